@@ -60,7 +60,7 @@ func main() {
 	log.Info().Msg("connected to nats")
 
 	// Ensure stream exists (optional, collector usually creates it, but good to ensure)
-	if err := natsClient.EnsureStream(ctx, "jobs", []string{"jobs.new"}); err != nil {
+	if err := natsClient.EnsureStream(ctx, "jobs", []string{"jobs.new", "jobs.updated"}); err != nil {
 		log.Fatal().Err(err).Msg("failed to ensure stream")
 	}
 
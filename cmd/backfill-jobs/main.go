@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/config"
+	"github.com/blockedby/positions-os/internal/database"
+	"github.com/blockedby/positions-os/internal/repository"
+)
+
+func main() {
+	rehash := flag.Bool("rehash", false, "recompute content_hash with the current normalization and regroup duplicates")
+	simhash := flag.Bool("simhash", false, "compute simhash of jobs stored without one and cluster them")
+	sourceURL := flag.Bool("source-url", false, "set source_url of telegram jobs stored without one to the post permalink")
+	flag.Parse()
+
+	if !*rehash && !*simhash && !*sourceURL {
+		fmt.Println("usage: backfill-jobs [-rehash] [-simhash] [-source-url]")
+		fmt.Println("run with the collector stopped, uses DATABASE_URL")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Printf("error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	jobs := repository.NewJobsRepository(db.Pool)
+	jobs.SetClustering(time.Duration(cfg.JobClusterDays)*24*time.Hour, cfg.JobClusterMaxDistance)
+
+	if *rehash {
+		n, err := jobs.RehashContent(ctx)
+		if err != nil {
+			fmt.Printf("error rehashing jobs: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("rehashed %d jobs, duplicates regrouped\n", n)
+	}
+
+	if *simhash {
+		n, err := jobs.BackfillSimHashes(ctx)
+		if err != nil {
+			fmt.Printf("error backfilling simhashes: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("fingerprinted and clustered %d jobs\n", n)
+	}
+
+	if *sourceURL {
+		n, err := jobs.BackfillSourceURLs(ctx)
+		if err != nil {
+			fmt.Printf("error backfilling source urls: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("linked %d jobs to their posts\n", n)
+	}
+}
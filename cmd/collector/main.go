@@ -11,6 +11,8 @@ import (
 	"github.com/blockedby/positions-os/internal/collector"
 	"github.com/blockedby/positions-os/internal/config"
 	"github.com/blockedby/positions-os/internal/database"
+	"github.com/blockedby/positions-os/internal/feed"
+	"github.com/blockedby/positions-os/internal/hh"
 	"github.com/blockedby/positions-os/internal/logger"
 	"github.com/blockedby/positions-os/internal/nats"
 	"github.com/blockedby/positions-os/internal/publisher"
@@ -70,8 +72,17 @@ func main() {
 	// 6. Initialize repositories
 	targetsRepo := repository.NewTargetsRepository(db.Pool)
 	jobsRepo := repository.NewJobsRepository(db.Pool)
+	jobsRepo.SetClustering(time.Duration(cfg.JobClusterDays)*24*time.Hour, cfg.JobClusterMaxDistance)
 	rangesRepo := repository.NewRangesRepository(db.Pool)
 	statsRepo := repository.NewStatsRepository(db.Pool)
+	runsRepo := repository.NewRunsRepository(db.Pool)
+
+	// runs left running by the previous process can be resumed from their checkpoints
+	if n, err := runsRepo.MarkInterrupted(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to mark interrupted scrape runs")
+	} else if n > 0 {
+		log.Info().Int("runs", n).Msg("scrape runs interrupted by the last shutdown")
+	}
 
 	// 7. Initialize telegram manager
 	if cfg.TGApiID == 0 || cfg.TGApiHash == "" {
@@ -97,16 +108,67 @@ func main() {
 		pub,
 		log,
 	)
+	svc.SetHHClient(hh.NewClient(cfg.HHAPIURL, cfg.HHUserAgent))
+	svc.SetFeedClient(feed.NewClient(cfg.FeedUserAgent))
+	svc.SetAttachmentStore(collector.NewAttachmentStore(cfg.AttachmentsDir, int64(cfg.AttachmentMaxMB)<<20, tgClient))
+	svc.SetMaxFailures(cfg.TargetMaxFailures)
+	svc.SetCheckpointStore(repository.NewCheckpointsRepository(db.Pool))
 	scrapeManager := collector.NewScrapeManager(svc)
+	scrapeManager.SetConcurrency(cfg.ScrapeConcurrency)
+	scrapeManager.SetRunRecorder(runsRepo)
 	collectorHandler := collector.NewHandler(scrapeManager, targetsRepo)
+	collectorHandler.SetRuns(runsRepo)
+
+	// scheduled scraping of active targets (per-target interval from metadata)
+	if cfg.SchedulerEnabled {
+		scheduler := collector.NewScheduler(
+			scrapeManager,
+			targetsRepo,
+			tgClient,
+			time.Duration(cfg.SchedulerTickSeconds)*time.Second,
+			log,
+		)
+		collectorHandler.SetScheduler(scheduler)
+		go scheduler.Run(ctx)
+	}
+
+	// live ingestion: new messages of active targets arrive as telegram updates
+	if cfg.LiveUpdatesEnabled {
+		live := collector.NewLiveIngester(
+			svc,
+			targetsRepo,
+			tgClient,
+			scrapeManager,
+			time.Minute,
+			time.Duration(cfg.LiveCatchUpMinutes)*time.Minute,
+			log,
+		)
+		tgManager.SetChannelMessageCallback(live.OnMessage)
+		tgManager.SetChannelDeleteCallback(live.OnDelete)
+		go live.Run(ctx)
+	}
+
+	// periodic verification: jobs whose source message was deleted are closed
+	if cfg.VerifyEnabled {
+		verifier := collector.NewVerifier(
+			jobsRepo,
+			targetsRepo,
+			tgClient,
+			time.Duration(cfg.VerifyIntervalHours)*time.Hour,
+			log,
+		)
+		go verifier.Run(ctx)
+	}
 
 	// 9. Initialize WebSocket Hub
 	hub := web.NewHub()
 	go hub.Run()
+	svc.SetHub(hub)
 
 	// 10. Initialize API Handlers
 	jobsAPIHandler := handlers.NewJobsHandler(jobsRepo, hub)
 	targetsAPIHandler := handlers.NewTargetsHandler(targetsRepo)
+	targetsAPIHandler.SetResolver(svc)
 	statsAPIHandler := handlers.NewStatsHandler(statsRepo)
 	authHandler := handlers.NewAuthHandler(tgClient, hub)
 
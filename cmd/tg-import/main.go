@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/collector"
+	"github.com/blockedby/positions-os/internal/config"
+	"github.com/blockedby/positions-os/internal/database"
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/nats"
+	"github.com/blockedby/positions-os/internal/publisher"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+func main() {
+	targetID := flag.String("target", "", "id of the target to import into")
+	channel := flag.String("channel", "", "@username of the channel, finds or creates its target")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Println("usage: tg-import [-target id | -channel @username] path/to/export")
+		fmt.Println("path is the Telegram Desktop json export directory or its result.json")
+		fmt.Println("without -target and -channel the target is found by the channel id of the export")
+		os.Exit(1)
+	}
+
+	export, err := telegram.ReadExport(flag.Arg(0))
+	if err != nil {
+		fmt.Printf("error reading export: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("read %q: %d messages\n", export.Name, len(export.Messages))
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(cfg.LogLevel, ""); err != nil {
+		fmt.Printf("error initializing logger: %v\n", err)
+		os.Exit(1)
+	}
+	log := logger.Get()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := database.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Printf("error connecting to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	// jobs.new events let the analyzer pick the imported jobs up
+	var pub collector.EventPublisher
+	nc, err := nats.New(ctx, cfg.NatsURL)
+	if err != nil {
+		fmt.Printf("warning: nats unavailable, jobs.new events are not published: %v\n", err)
+	} else {
+		defer nc.Close()
+		pub = publisher.NewNATSPublisher(nc.Conn)
+	}
+
+	targets := repository.NewTargetsRepository(db.Pool)
+	jobs := repository.NewJobsRepository(db.Pool)
+	jobs.SetClustering(time.Duration(cfg.JobClusterDays)*24*time.Hour, cfg.JobClusterMaxDistance)
+	ranges := repository.NewRangesRepository(db.Pool)
+
+	target, err := importTarget(ctx, targets, export, *targetID, *channel)
+	if err != nil {
+		fmt.Printf("error resolving target: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("importing into target %s (%s)\n", target.ID, target.URL)
+
+	// no telegram client: documents are read from the export
+	svc := collector.NewService(nil, targets, jobs, ranges, pub, log)
+	svc.SetAttachmentStore(collector.NewAttachmentStore(cfg.AttachmentsDir, int64(cfg.AttachmentMaxMB)<<20, nil))
+
+	result, err := svc.Import(ctx, target, export.Messages)
+	if err != nil {
+		fmt.Printf("error importing: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("imported %d jobs: %d already collected, %d edited, %d empty, %d filtered, %d errors\n",
+		result.NewJobs, result.SkippedOld, result.EditedJobs, result.SkippedEmpty, result.SkippedFiltered, result.Errors)
+}
+
+// importTarget returns the target of the export: the given one, the one of
+// the channel username, or the one with the channel id of the export.
+// a missing target is created; without a username it links to t.me/c/<id>
+// and stays inactive: it resolves only if the session joined the channel.
+func importTarget(ctx context.Context, targets *repository.TargetsRepository, export *telegram.Export, targetID, channel string) (*repository.ScrapingTarget, error) {
+	var (
+		target *repository.ScrapingTarget
+		err    error
+	)
+	switch {
+	case targetID != "":
+		id, perr := uuid.Parse(targetID)
+		if perr != nil {
+			return nil, fmt.Errorf("invalid target id: %w", perr)
+		}
+		if target, err = targets.GetByID(ctx, id); err == nil && target == nil {
+			err = fmt.Errorf("target not found: %s", id)
+		}
+	case channel != "":
+		target, err = targets.GetByURL(ctx, channel)
+	default:
+		target, err = targets.GetByChannelID(ctx, export.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		url := channel
+		if url == "" {
+			url = fmt.Sprintf("https://t.me/c/%d", export.ID)
+		}
+		target = &repository.ScrapingTarget{
+			Name:        export.Name,
+			Type:        export.TargetType(),
+			URL:         url,
+			TgChannelID: &export.ID,
+			IsActive:    channel != "",
+		}
+		if err := targets.Create(ctx, target); err != nil {
+			return nil, err
+		}
+		fmt.Printf("created target %q\n", target.Name)
+	}
+
+	// permalinks of private channels need the channel id
+	if target.TgChannelID == nil {
+		target.TgChannelID = &export.ID
+	}
+	return target, nil
+}
@@ -16,6 +16,7 @@ const statusToBadge: Record<JobStatus, BadgeStatus> = {
   TAILORED: 'analyzed',
   SENT: 'interested',
   RESPONDED: 'interested',
+  CLOSED: 'rejected',
 }
 
 export const RecentJobs = ({ limit = 5, className = '' }: RecentJobsProps) => {
@@ -16,6 +16,7 @@ const statusOptions = [
   { value: 'TAILORED', label: 'Tailored' },
   { value: 'SENT', label: 'Sent' },
   { value: 'RESPONDED', label: 'Responded' },
+  { value: 'CLOSED', label: 'Closed' },
 ]
 
 const sortOptions = [
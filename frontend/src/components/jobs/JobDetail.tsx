@@ -16,6 +16,7 @@ const statusToBadge: Record<JobStatus, BadgeStatus> = {
   TAILORED: 'analyzed',
   SENT: 'interested',
   RESPONDED: 'interested',
+  CLOSED: 'rejected',
 }
 
 const statusActions: { status: JobStatus; label: string; variant: 'success' | 'danger' | 'primary' }[] = [
@@ -15,6 +15,7 @@ const statusToBadge: Record<JobStatus, BadgeStatus> = {
   TAILORED: 'analyzed',
   SENT: 'interested',
   RESPONDED: 'interested',
+  CLOSED: 'rejected',
 }
 
 const formatSalary = (job: Job): string => {
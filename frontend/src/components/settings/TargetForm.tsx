@@ -15,6 +15,7 @@ const targetTypeOptions = [
   { value: 'TG_FORUM', label: 'Telegram Forum' },
   { value: 'HH_SEARCH', label: 'HeadHunter Search' },
   { value: 'LINKEDIN_SEARCH', label: 'LinkedIn Search' },
+  { value: 'FEED', label: 'RSS / Atom / JSON Feed' },
 ]
 
 export const TargetForm = ({ target, onCancel, onSuccess }: TargetFormProps) => {
@@ -40,14 +41,20 @@ export const TargetForm = ({ target, onCancel, onSuccess }: TargetFormProps) =>
   const validate = (): boolean => {
     const newErrors: Record<string, string> = {}
 
-    if (!name.trim()) {
+    // a new telegram target is named after its channel
+    if (!name.trim() && (isEditing || !type.startsWith('TG_'))) {
       newErrors.name = 'Name is required'
     }
 
     if (!url.trim()) {
       newErrors.url = 'URL is required'
-    } else if (type.startsWith('TG_') && !url.startsWith('@') && !url.includes('t.me')) {
-      newErrors.url = 'Telegram URL should start with @ or include t.me'
+    } else if (
+      type.startsWith('TG_') &&
+      !url.startsWith('@') &&
+      !url.includes('t.me') &&
+      !/^-?\d+$/.test(url.trim())
+    ) {
+      newErrors.url = 'Telegram URL should start with @, include t.me or be a channel id'
     }
 
     setErrors(newErrors)
@@ -108,12 +115,16 @@ export const TargetForm = ({ target, onCancel, onSuccess }: TargetFormProps) =>
 
         <Input
           label="URL"
-          placeholder="e.g., @golang_jobs or https://t.me/golang_jobs"
+          placeholder="e.g., @golang_jobs, https://t.me/golang_jobs or https://t.me/+invite"
           value={url}
           onChange={(e) => setUrl(e.target.value)}
           error={!!errors.url}
           errorMessage={errors.url}
-          helperText={type.startsWith('TG_') ? 'Use @channel_name or full t.me URL' : undefined}
+          helperText={
+            type.startsWith('TG_')
+              ? 'Use @channel_name, a t.me URL, an invite link or the id of a joined channel; channel, group or forum is detected'
+              : undefined
+          }
         />
 
         <div className="form-checkbox">
@@ -14,6 +14,7 @@ const typeToBadge: Record<string, BadgeStatus> = {
   TG_FORUM: 'analyzed',
   HH_SEARCH: 'interested',
   LINKEDIN_SEARCH: 'interested',
+  FEED: 'interested',
 }
 
 export const TargetList = ({ onScrape }: TargetListProps) => {
@@ -88,8 +89,18 @@ export const TargetList = ({ onScrape }: TargetListProps) => {
                   {!target.is_active && (
                     <Badge status="paused">Paused</Badge>
                   )}
+                  {target.consecutive_failures > 0 && (
+                    <Badge status="rejected">
+                      {target.disabled_at ? 'Disabled' : 'Failing'} ({target.consecutive_failures})
+                    </Badge>
+                  )}
                 </div>
                 <span className="target-url text-xs text-muted">{target.url}</span>
+                {target.consecutive_failures > 0 && target.last_error && (
+                  <span className="target-last-error text-xs text-muted">
+                    Last error: {target.last_error}
+                  </span>
+                )}
                 {target.last_scraped_at && (
                   <span className="target-last-scraped text-xs text-muted">
                     Last scraped: {new Date(target.last_scraped_at).toLocaleString()}
@@ -136,6 +147,7 @@ const formatType = (type: string): string => {
     TG_FORUM: 'Forum',
     HH_SEARCH: 'HH',
     LINKEDIN_SEARCH: 'LinkedIn',
+    FEED: 'Feed',
   }
   return map[type] || type
 }
@@ -136,8 +136,8 @@ export function useScrapeStatus(enabled = true) {
 
       case 'scrape.progress':
         setScrapeProgress({
-          processed: event.processed,
-          total: event.processed, // API doesn't return total
+          processed: event.fetched,
+          total: event.fetched, // API doesn't return total
           newJobs: event.new_jobs,
         })
         break
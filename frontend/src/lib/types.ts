@@ -10,6 +10,7 @@ export type JobStatus =
   | 'TAILORED'
   | 'SENT'
   | 'RESPONDED'
+  | 'CLOSED'
 
 export type TargetType =
   | 'TG_CHANNEL'
@@ -17,6 +18,7 @@ export type TargetType =
   | 'TG_FORUM'
   | 'HH_SEARCH'
   | 'LINKEDIN_SEARCH'
+  | 'FEED'
 
 export type Currency = 'RUB' | 'USD' | 'EUR' | null
 export type Language = 'RU' | 'EN'
@@ -55,6 +57,7 @@ export interface Job {
   created_at: string
   updated_at: string
   analyzed_at?: string | null
+  closed_at?: string | null
 }
 
 // ============================================================================
@@ -80,6 +83,14 @@ export interface Target {
   last_scraped_at?: string | null
   last_message_id?: number | null
   is_active: boolean
+  // health
+  consecutive_failures: number
+  last_error?: string | null
+  last_error_at?: string | null
+  last_success_at?: string | null
+  successful_runs: number
+  avg_new_jobs: number
+  disabled_at?: string | null
   created_at: string
   updated_at: string
 }
@@ -191,33 +202,57 @@ export interface BaseWSEvent {
 
 export interface ScrapeStartedEvent extends BaseWSEvent {
   type: 'scrape.started'
+  target_id: string
   target: string
-  limit: number
+  limit?: number
 }
 
 export interface ScrapeProgressEvent extends BaseWSEvent {
   type: 'scrape.progress'
+  target_id: string
   target: string
-  processed: number
+  batch: number
+  topic_id?: number
+  offset: number
+  fetched: number
+  new_jobs: number
+  skipped_old: number
+  skipped_empty: number
+  skipped_filtered: number
+  edited_jobs: number
+  errors: number
+}
+
+export interface ScrapeResult {
+  target_id: string
+  total_fetched: number
   new_jobs: number
+  skipped_old: number
+  skipped_empty: number
+  skipped_filtered: number
+  edited_jobs: number
+  errors: number
 }
 
 export interface ScrapeCompletedEvent extends BaseWSEvent {
   type: 'scrape.completed'
+  target_id: string
   target: string
-  total: number
-  new: number
+  result: ScrapeResult
 }
 
 export interface ScrapeFailedEvent extends BaseWSEvent {
   type: 'scrape.failed'
+  target_id: string
   target: string
   error: string
 }
 
 export interface ScrapeCancelledEvent extends BaseWSEvent {
   type: 'scrape.cancelled'
+  target_id: string
   target: string
+  result?: ScrapeResult
 }
 
 export interface JobNewEvent extends BaseWSEvent {
@@ -25,13 +25,17 @@ func NewConsumer(client *nats.Client, processor *Processor, log *zerolog.Logger)
 	}
 }
 
-// Start subscribes to jobs.new and starts processing
+// Start subscribes to jobs.new and jobs.updated and starts processing
 func (c *Consumer) Start(ctx context.Context) error {
 	// Subject: jobs.new
 	// Stream: jobs (created by collector)
 	// Durable Consumer Name: analyzer_processor
 	c.log.Info().Msg("starting analyzer consumer")
-	return c.client.Subscribe(ctx, "jobs", "analyzer_processor", "jobs.new", c.handleMessage)
+	if err := c.client.Subscribe(ctx, "jobs", "analyzer_processor", "jobs.new", c.handleMessage); err != nil {
+		return err
+	}
+	// edited jobs are analyzed again (same payload shape, job_id is all we need)
+	return c.client.Subscribe(ctx, "jobs", "analyzer_updates", "jobs.updated", c.handleMessage)
 }
 
 // handleMessage processes a single message
@@ -35,12 +35,22 @@ func (p *Processor) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 	if job == nil {
 		return fmt.Errorf("job not found: %s", jobID)
 	}
+	// duplicates share the analysis of their canonical job
+	if job.IsDuplicate() {
+		p.log.Info().
+			Str("job_id", jobID.String()).
+			Str("duplicate_of", job.DuplicateOf.String()).
+			Msg("job is a duplicate, skipping analysis")
+		return nil
+	}
 
 	// 2. Prepare prompt
-	userPrompt := p.prompts.BuildUserPrompt(job.RawContent)
+	// comments are analyzed together with the post they reply to
+	content := job.AnalysisContent()
+	userPrompt := p.prompts.BuildUserPrompt(content)
 
 	// 3. Call LLM
-	jsonStr, err := p.llm.ExtractJobData(ctx, job.RawContent, p.prompts.System, userPrompt)
+	jsonStr, err := p.llm.ExtractJobData(ctx, content, p.prompts.System, userPrompt)
 	if err != nil {
 		return fmt.Errorf("llm extraction: %w", err)
 	}
@@ -53,6 +63,18 @@ func (p *Processor) ProcessJob(ctx context.Context, jobID uuid.UUID) error {
 		return fmt.Errorf("invalid json received from llm: %w", err)
 	}
 
+	// fields pre-filled by the source (e.g. the hh.ru api) are more reliable
+	// than the extraction, they win on the first analysis
+	if job.AnalyzedAt == nil {
+		for k, v := range job.StructuredData {
+			data[k] = v
+		}
+	}
+	// contacts marked up in the post are exact, the extracted ones come after
+	if linked := job.LinkContacts(); len(linked) > 0 {
+		data["contacts"] = mergeContacts(linked, data["contacts"])
+	}
+
 	// 5. Update DB
 	if err := p.repo.UpdateStructuredData(ctx, jobID, data); err != nil {
 		return fmt.Errorf("update db: %w", err)
@@ -93,3 +115,21 @@ func cleanJSON(s string) string {
 	s = strings.TrimSuffix(s, "```")
 	return strings.TrimSpace(s)
 }
+
+// mergeContacts puts the link contacts before the extracted ones, without duplicates
+func mergeContacts(linked []string, extracted interface{}) []interface{} {
+	contacts := append([]string{}, linked...)
+	if list, ok := extracted.([]interface{}); ok {
+		for _, c := range list {
+			if s, ok := c.(string); ok {
+				contacts = append(contacts, s)
+			}
+		}
+	}
+
+	merged := make([]interface{}, 0, len(contacts))
+	for _, c := range repository.UniqueContacts(contacts) {
+		merged = append(merged, c)
+	}
+	return merged
+}
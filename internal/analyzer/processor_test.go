@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -159,6 +160,134 @@ func TestProcessor_ProcessJob(t *testing.T) {
 			t.Errorf("JSON cleanup failed. Got: %v", mockRepo.UpdatedData)
 		}
 	})
+
+	// Test Case 4: Duplicate job is not sent to the LLM
+	t.Run("DuplicateSkipped", func(t *testing.T) {
+		jobID := uuid.New()
+		canonicalID := uuid.New()
+
+		mockLLM := &MockLLMClient{
+			ExtractFunc: func(ctx context.Context, raw, sys, user string) (string, error) {
+				t.Error("LLM should not be called for a duplicate")
+				return "{}", nil
+			},
+		}
+
+		mockRepo := &MockJobsRepo{
+			Jobs: map[uuid.UUID]*repository.Job{
+				jobID: {ID: jobID, RawContent: "Test", DuplicateOf: &canonicalID},
+			},
+		}
+
+		proc := NewProcessor(mockLLM, mockRepo, prompts, &logger)
+		if err := proc.ProcessJob(context.Background(), jobID); err != nil {
+			t.Fatalf("ProcessJob() error: %v", err)
+		}
+		if mockRepo.UpdatedData != nil {
+			t.Errorf("duplicate should not be updated, got %v", mockRepo.UpdatedData)
+		}
+	})
+
+	// Test Case 5: Source data pre-filled by the collector wins over the LLM
+	t.Run("SourceDataMerged", func(t *testing.T) {
+		jobID := uuid.New()
+
+		mockLLM := &MockLLMClient{
+			ExtractFunc: func(ctx context.Context, raw, sys, user string) (string, error) {
+				return `{"title": "Go dev", "salary_min": 100, "language": "RU"}`, nil
+			},
+		}
+
+		mockRepo := &MockJobsRepo{
+			Jobs: map[uuid.UUID]*repository.Job{
+				jobID: {
+					ID:             jobID,
+					RawContent:     "Golang-разработчик",
+					StructuredData: map[string]interface{}{"title": "Golang-разработчик", "salary_min": float64(250000)},
+				},
+			},
+		}
+
+		proc := NewProcessor(mockLLM, mockRepo, prompts, &logger)
+		if err := proc.ProcessJob(context.Background(), jobID); err != nil {
+			t.Fatalf("ProcessJob() error: %v", err)
+		}
+
+		data := mockRepo.GetUpdatedData()
+		if data["title"] != "Golang-разработчик" || data["salary_min"] != float64(250000) {
+			t.Errorf("source fields should win, got %v", data)
+		}
+		if data["language"] != "RU" {
+			t.Errorf("llm fields should be kept, got %v", data)
+		}
+	})
+
+	// Test Case 6: Contacts from the post links come first in structured_data.contacts
+	t.Run("LinkContactsMerged", func(t *testing.T) {
+		jobID := uuid.New()
+
+		mockLLM := &MockLLMClient{
+			ExtractFunc: func(ctx context.Context, raw, sys, user string) (string, error) {
+				return `{"title": "Go dev", "contacts": ["@HR_Anna", "+7 900 000-00-00"]}`, nil
+			},
+		}
+
+		mockRepo := &MockJobsRepo{
+			Jobs: map[uuid.UUID]*repository.Job{
+				jobID: {
+					ID:         jobID,
+					RawContent: "Go dev, write @hr_anna or hr@example.com",
+					Links: []repository.Link{
+						{Type: repository.LinkMention, URL: "@hr_anna"},
+						{Type: repository.LinkEmail, URL: "hr@example.com"},
+						{Type: repository.LinkURL, URL: "https://example.com"},
+					},
+				},
+			},
+		}
+
+		proc := NewProcessor(mockLLM, mockRepo, prompts, &logger)
+		if err := proc.ProcessJob(context.Background(), jobID); err != nil {
+			t.Fatalf("ProcessJob() error: %v", err)
+		}
+
+		got := mockRepo.GetUpdatedData()["contacts"]
+		want := []interface{}{"@hr_anna", "hr@example.com", "+7 900 000-00-00"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("contacts = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("CommentWithParentPost", func(t *testing.T) {
+		jobID := uuid.New()
+
+		var prompt string
+		mockLLM := &MockLLMClient{
+			ExtractFunc: func(ctx context.Context, raw, sys, user string) (string, error) {
+				prompt = user
+				return `{"title": "Go dev"}`, nil
+			},
+		}
+
+		mockRepo := &MockJobsRepo{
+			Jobs: map[uuid.UUID]*repository.Job{
+				jobID: {
+					ID:         jobID,
+					RawContent: "We also need a QA, write @hr_anna",
+					Parent:     &repository.ParentPost{MessageID: 42, Text: "Acme is hiring a Go developer"},
+				},
+			},
+		}
+
+		proc := NewProcessor(mockLLM, mockRepo, prompts, &logger)
+		if err := proc.ProcessJob(context.Background(), jobID); err != nil {
+			t.Fatalf("ProcessJob() error: %v", err)
+		}
+
+		if !strings.Contains(prompt, "We also need a QA") || !strings.Contains(prompt, "Acme is hiring") {
+			t.Errorf("prompt should hold the comment and its post: %q", prompt)
+		}
+	})
 }
 
 func contains(s, substr string) bool {
@@ -498,10 +498,6 @@ func (s *Server) sendApplication(c fuego.ContextWithBody[ApplicationSendRequest]
 		return ApplicationSendResponse{}, fuego.BadRequestError{Detail: err.Error()}
 	}
 
-	if body.Recipient == "" {
-		return ApplicationSendResponse{}, fuego.BadRequestError{Detail: "Recipient is required"}
-	}
-
 	// Determine channel
 	channel := "TG_DM"
 	if app.DeliveryChannel != nil {
@@ -228,7 +228,7 @@ type ApplicationCreateRequest struct {
 // ApplicationSendRequest contains the request body for sending an application.
 type ApplicationSendRequest struct {
 	ID        uuid.UUID `path:"id" description:"Application ID"`
-	Recipient string    `json:"recipient" validate:"required" description:"Delivery recipient (username or email)"`
+	Recipient string    `json:"recipient" description:"Delivery recipient (username or email), chosen from the job contacts when empty"`
 }
 
 // ApplicationSendResponse contains the response after sending an application.
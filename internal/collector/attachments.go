@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blockedby/positions-os/internal/extract"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// ErrAttachmentTooLarge is returned for documents over the download limit
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// DocumentDownloader downloads document attachments of telegram messages
+type DocumentDownloader interface {
+	DownloadDocument(ctx context.Context, att *telegram.Attachment, w io.Writer) error
+}
+
+// AttachmentStore downloads text documents (PDF, DOCX, TXT) attached to
+// posts into a storage directory and extracts their text
+type AttachmentStore struct {
+	dir        string
+	maxSize    int64
+	downloader DocumentDownloader
+}
+
+// NewAttachmentStore creates a store under dir. documents larger than
+// maxSize bytes are not downloaded.
+func NewAttachmentStore(dir string, maxSize int64, downloader DocumentDownloader) *AttachmentStore {
+	return &AttachmentStore{dir: dir, maxSize: maxSize, downloader: downloader}
+}
+
+// SetAttachmentStore enables downloads of text documents attached to telegram posts
+func (s *Service) SetAttachmentStore(store *AttachmentStore) {
+	for _, typ := range telegramTargetTypes {
+		if tg, ok := s.sources[typ].(*telegramSource); ok {
+			tg.attachments = store
+		}
+	}
+}
+
+// Fetch downloads the document of a post to <dir>/<target id>/<external id>-<file name>
+// and sets its Path and Text. a document with a Path (from a Telegram Desktop
+// export) is copied from that file instead. photos and documents text can not
+// be extracted from are left as they are.
+func (s *AttachmentStore) Fetch(ctx context.Context, targetID uuid.UUID, externalID string, att *repository.Attachment) error {
+	data, err := s.load(ctx, att)
+	if err != nil || data == nil {
+		return err
+	}
+
+	dir := filepath.Join(s.dir, targetID.String())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create attachment directory: %w", err)
+	}
+	path := filepath.Join(dir, externalID+"-"+attachmentFileName(att))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("save attachment: %w", err)
+	}
+	att.Path = path
+
+	return extractText(att, data)
+}
+
+// Read downloads the document of a post and sets its Text without saving
+// the file, for dry runs. Path is left as it is.
+func (s *AttachmentStore) Read(ctx context.Context, att *repository.Attachment) error {
+	data, err := s.load(ctx, att)
+	if err != nil || data == nil {
+		return err
+	}
+	return extractText(att, data)
+}
+
+// load returns the content of a document text can be extracted from,
+// nil for photos and other documents
+func (s *AttachmentStore) load(ctx context.Context, att *repository.Attachment) ([]byte, error) {
+	if att.Type != telegram.AttachmentDocument || !extract.Supported(att.FileName, att.MimeType) {
+		return nil, nil
+	}
+	if s.maxSize > 0 && att.Size > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrAttachmentTooLarge, att.Size)
+	}
+
+	if att.Path != "" {
+		data, err := os.ReadFile(att.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read exported attachment: %w", err)
+		}
+		return data, nil
+	}
+	if s.downloader == nil {
+		return nil, errors.New("attachment downloads are not configured")
+	}
+	var data bytes.Buffer
+	if err := s.downloader.DownloadDocument(ctx, telegramAttachment(att), &data); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+// extractText sets the text of a loaded document
+func extractText(att *repository.Attachment, data []byte) error {
+	text, err := extract.Text(att.FileName, att.MimeType, data)
+	if err != nil {
+		return fmt.Errorf("extract attachment text: %w", err)
+	}
+	att.Text = text
+	return nil
+}
+
+// attachmentFileName is the file name of a document, safe for the storage directory
+func attachmentFileName(att *repository.Attachment) string {
+	name := filepath.Base(strings.ReplaceAll(att.FileName, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		name = fmt.Sprintf("%d.%s", att.FileID, extract.Format(att.FileName, att.MimeType))
+	}
+	return name
+}
+
+// attachmentContent is the raw content of a post with an attachment:
+// the caption followed by the text extracted from the document
+func attachmentContent(caption string, att *repository.Attachment) string {
+	if att == nil || att.Text == "" {
+		return caption
+	}
+	if caption == "" {
+		return att.Text
+	}
+	return caption + "\n\n" + att.Text
+}
+
+// messageAttachment converts the attachment of a telegram message
+func messageAttachment(att *telegram.Attachment) *repository.Attachment {
+	if att == nil {
+		return nil
+	}
+	return &repository.Attachment{
+		Type:          att.Type,
+		FileName:      att.FileName,
+		MimeType:      att.MimeType,
+		Size:          att.Size,
+		FileID:        att.ID,
+		AccessHash:    att.AccessHash,
+		FileReference: att.FileReference,
+		DCID:          att.DCID,
+		Path:          att.Path,
+	}
+}
+
+// telegramAttachment is the telegram file location of a stored attachment
+func telegramAttachment(att *repository.Attachment) *telegram.Attachment {
+	return &telegram.Attachment{
+		Type:          att.Type,
+		FileName:      att.FileName,
+		MimeType:      att.MimeType,
+		Size:          att.Size,
+		ID:            att.FileID,
+		AccessHash:    att.AccessHash,
+		FileReference: att.FileReference,
+		DCID:          att.DCID,
+	}
+}
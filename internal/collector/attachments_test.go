@@ -0,0 +1,195 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// mockDownloader serves a fixed document and records the requested file ids
+type mockDownloader struct {
+	data       string
+	err        error
+	downloaded []int64
+}
+
+func (m *mockDownloader) DownloadDocument(ctx context.Context, att *telegram.Attachment, w io.Writer) error {
+	m.downloaded = append(m.downloaded, att.ID)
+	if m.err != nil {
+		return m.err
+	}
+	_, err := io.WriteString(w, m.data)
+	return err
+}
+
+func TestAttachmentStore_Fetch(t *testing.T) {
+	targetID := uuid.New()
+
+	t.Run("text document is saved and its text extracted", func(t *testing.T) {
+		dir := t.TempDir()
+		downloader := &mockDownloader{data: "Go developer\r\n\r\nRemote, 300k\n"}
+		store := NewAttachmentStore(dir, 1<<20, downloader)
+		att := &repository.Attachment{Type: telegram.AttachmentDocument, FileName: "../vacancy.txt", MimeType: "text/plain", Size: 30, FileID: 7}
+
+		if err := store.Fetch(context.Background(), targetID, "42", att); err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if att.Text != "Go developer\n\nRemote, 300k" {
+			t.Errorf("unexpected text %q", att.Text)
+		}
+		want := filepath.Join(dir, targetID.String(), "42-vacancy.txt")
+		if att.Path != want {
+			t.Errorf("path = %q, want %q", att.Path, want)
+		}
+		if data, err := os.ReadFile(want); err != nil || string(data) != downloader.data {
+			t.Errorf("stored file %q, %v", data, err)
+		}
+	})
+
+	t.Run("photos and other documents are not downloaded", func(t *testing.T) {
+		downloader := &mockDownloader{data: "x"}
+		store := NewAttachmentStore(t.TempDir(), 1<<20, downloader)
+		for _, att := range []*repository.Attachment{
+			{Type: telegram.AttachmentPhoto, MimeType: "image/jpeg"},
+			{Type: telegram.AttachmentDocument, FileName: "logo.png", MimeType: "image/png"},
+		} {
+			if err := store.Fetch(context.Background(), targetID, "1", att); err != nil {
+				t.Errorf("Fetch() error: %v", err)
+			}
+			if att.Path != "" || att.Text != "" {
+				t.Errorf("attachment should be left as is: %+v", att)
+			}
+		}
+		if len(downloader.downloaded) != 0 {
+			t.Errorf("unexpected downloads %v", downloader.downloaded)
+		}
+	})
+
+	t.Run("exported document is copied from its file", func(t *testing.T) {
+		export := filepath.Join(t.TempDir(), "vacancy.txt")
+		if err := os.WriteFile(export, []byte("Rust developer"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		store := NewAttachmentStore(dir, 1<<20, nil)
+		att := &repository.Attachment{Type: telegram.AttachmentDocument, FileName: "vacancy.txt", Size: 14, Path: export}
+
+		if err := store.Fetch(context.Background(), targetID, "5", att); err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if att.Text != "Rust developer" || att.Path != filepath.Join(dir, targetID.String(), "5-vacancy.txt") {
+			t.Errorf("unexpected attachment %+v", att)
+		}
+	})
+
+	t.Run("documents over the limit are skipped", func(t *testing.T) {
+		downloader := &mockDownloader{data: "x"}
+		store := NewAttachmentStore(t.TempDir(), 1024, downloader)
+		att := &repository.Attachment{Type: telegram.AttachmentDocument, FileName: "big.pdf", Size: 2048}
+
+		if err := store.Fetch(context.Background(), targetID, "1", att); !errors.Is(err, ErrAttachmentTooLarge) {
+			t.Errorf("expected ErrAttachmentTooLarge, got %v", err)
+		}
+		if len(downloader.downloaded) != 0 {
+			t.Error("large document should not be downloaded")
+		}
+	})
+}
+
+func TestAttachmentContent(t *testing.T) {
+	doc := &repository.Attachment{Text: "Go developer, 300k"}
+
+	tests := []struct {
+		name    string
+		caption string
+		att     *repository.Attachment
+		want    string
+	}{
+		{"no attachment", "caption", nil, "caption"},
+		{"attachment without text", "caption", &repository.Attachment{}, "caption"},
+		{"document only", "", doc, "Go developer, 300k"},
+		{"caption and document", "Vacancy below", doc, "Vacancy below\n\nGo developer, 300k"},
+	}
+	for _, tt := range tests {
+		if got := attachmentContent(tt.caption, tt.att); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// test that a post with a document becomes a job with the document text
+func TestTelegramSource_JobWithAttachment(t *testing.T) {
+	target := &repository.ScrapingTarget{ID: uuid.New()}
+	msg := telegram.Message{
+		ID:   10,
+		Date: time.Now(),
+		Attachment: &telegram.Attachment{
+			Type:     telegram.AttachmentDocument,
+			FileName: "vacancy.txt",
+			MimeType: "text/plain",
+			Size:     12,
+			ID:       100,
+		},
+	}
+	item := messageItem(&msg)
+
+	t.Run("document text becomes the raw content", func(t *testing.T) {
+		downloader := &mockDownloader{data: "Go developer"}
+		src := &telegramSource{attachments: NewAttachmentStore(t.TempDir(), 1<<20, downloader), log: logger.Get()}
+
+		job, err := src.Job(context.Background(), target, &item, ScrapeOptions{})
+		if err != nil {
+			t.Fatalf("Job() error: %v", err)
+		}
+		if job.RawContent != "Go developer" {
+			t.Errorf("unexpected raw content %q", job.RawContent)
+		}
+		if job.Attachment == nil || job.Attachment.FileID != 100 || job.Attachment.Path == "" {
+			t.Errorf("unexpected attachment %+v", job.Attachment)
+		}
+		if item.Attachment.Text != "" {
+			t.Error("the item attachment should not be modified")
+		}
+	})
+
+	t.Run("dry run reads the document without saving it", func(t *testing.T) {
+		dir := t.TempDir()
+		downloader := &mockDownloader{data: "Go developer"}
+		src := &telegramSource{attachments: NewAttachmentStore(dir, 1<<20, downloader), log: logger.Get()}
+
+		job, err := src.Job(context.Background(), target, &item, ScrapeOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("Job() error: %v", err)
+		}
+		if job.RawContent != "Go developer" || job.Attachment == nil || job.Attachment.Path != "" {
+			t.Errorf("unexpected job %q %+v", job.RawContent, job.Attachment)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("dry run wrote to the attachments directory: %v", entries)
+		}
+	})
+
+	t.Run("failed download keeps the caption", func(t *testing.T) {
+		downloader := &mockDownloader{err: errors.New("FILE_REFERENCE_EXPIRED")}
+		src := &telegramSource{attachments: NewAttachmentStore(t.TempDir(), 1<<20, downloader), log: logger.Get()}
+		captioned := item
+		captioned.Text = "Vacancy in the file"
+
+		job, err := src.Job(context.Background(), target, &captioned, ScrapeOptions{})
+		if err != nil {
+			t.Fatalf("Job() error: %v", err)
+		}
+		if job.RawContent != "Vacancy in the file" || job.Attachment == nil || job.Attachment.FileName != "vacancy.txt" {
+			t.Errorf("unexpected job %q %+v", job.RawContent, job.Attachment)
+		}
+	})
+}
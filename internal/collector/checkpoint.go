@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrNotResumable is returned when resuming a run that is still running or
+// whose streams all reached their end
+var ErrNotResumable = errors.New("scrape run is not resumable")
+
+// CheckpointStore keeps the per-stream progress of scrape runs
+// (repository.CheckpointsRepository)
+type CheckpointStore interface {
+	Save(ctx context.Context, cp *repository.ScrapeCheckpoint) error
+	Transfer(ctx context.Context, fromRun, toRun uuid.UUID) ([]repository.ScrapeCheckpoint, error)
+}
+
+// SetCheckpointStore enables checkpoints: the progress of every stream of a
+// recorded run (opts.RunID) is saved after each batch, and opts.ResumeFrom
+// continues the streams of an interrupted run where it stopped
+func (s *Service) SetCheckpointStore(store CheckpointStore) {
+	s.checkpoints = store
+}
+
+// ResumeOptions returns the options of a run that continues an interrupted
+// one: its options, bound to its resolved target, resuming from its
+// checkpoints
+func ResumeOptions(run *repository.ScrapeRun) (ScrapeOptions, error) {
+	if !run.Resumable {
+		return ScrapeOptions{}, ErrNotResumable
+	}
+	var opts ScrapeOptions
+	if err := json.Unmarshal(run.Options, &opts); err != nil {
+		return ScrapeOptions{}, fmt.Errorf("parse run options: %w", err)
+	}
+	if run.TargetID != nil {
+		opts.TargetID = *run.TargetID
+	}
+	opts.ResumeFrom = run.ID
+	return opts, nil
+}
+
+// resumePoints takes over the checkpoints of the run opts.ResumeFrom,
+// by stream key. nil when the run starts from scratch.
+func (s *Service) resumePoints(ctx context.Context, opts ScrapeOptions) (map[int64]*repository.ScrapeCheckpoint, error) {
+	if opts.ResumeFrom == uuid.Nil || opts.DryRun {
+		return nil, nil
+	}
+	if s.checkpoints == nil || opts.RunID == uuid.Nil {
+		return nil, fmt.Errorf("%w: checkpoints are not enabled", ErrNotResumable)
+	}
+
+	checkpoints, err := s.checkpoints.Transfer(ctx, opts.ResumeFrom, opts.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoints: %w", err)
+	}
+	points := make(map[int64]*repository.ScrapeCheckpoint, len(checkpoints))
+	for i := range checkpoints {
+		points[checkpoints[i].TopicID] = &checkpoints[i]
+	}
+	s.log.Info().
+		Str("resume_from", opts.ResumeFrom.String()).
+		Int("checkpoints", len(points)).
+		Msg("scrape: resuming interrupted run")
+	return points, nil
+}
+
+// saveCheckpoint stores the progress of a stream. recorded runs only,
+// dry runs leave nothing to resume.
+func (s *Service) saveCheckpoint(opts ScrapeOptions, cp *repository.ScrapeCheckpoint) {
+	if s.checkpoints == nil || opts.RunID == uuid.Nil || opts.DryRun {
+		return
+	}
+
+	// the scrape context may be cancelled, that is when the checkpoint matters
+	ctx, cancel := context.WithTimeout(context.Background(), runHistoryTimeout)
+	defer cancel()
+
+	if err := s.checkpoints.Save(ctx, cp); err != nil {
+		s.log.Warn().Err(err).Int64("topic_id", cp.TopicID).Msg("scrape: failed to save checkpoint")
+	}
+}
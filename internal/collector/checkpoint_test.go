@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// mockCheckpointStore keeps checkpoints in memory by run
+type mockCheckpointStore struct {
+	saved map[uuid.UUID][]repository.ScrapeCheckpoint
+}
+
+func (m *mockCheckpointStore) Save(ctx context.Context, cp *repository.ScrapeCheckpoint) error {
+	if m.saved == nil {
+		m.saved = make(map[uuid.UUID][]repository.ScrapeCheckpoint)
+	}
+	m.saved[cp.RunID] = append(m.saved[cp.RunID], *cp)
+	return nil
+}
+
+func (m *mockCheckpointStore) Transfer(ctx context.Context, fromRun, toRun uuid.UUID) ([]repository.ScrapeCheckpoint, error) {
+	moved := m.saved[fromRun]
+	delete(m.saved, fromRun)
+	for i := range moved {
+		moved[i].RunID = toRun
+	}
+	if m.saved == nil {
+		m.saved = make(map[uuid.UUID][]repository.ScrapeCheckpoint)
+	}
+	m.saved[toRun] = moved
+	return moved, nil
+}
+
+// test the options of a run resuming an interrupted one
+func TestResumeOptions(t *testing.T) {
+	targetID := uuid.New()
+	run := &repository.ScrapeRun{
+		ID:        uuid.New(),
+		TargetID:  &targetID,
+		Options:   json.RawMessage(`{"channel":"@golang_jobs","limit":500,"backfill":true}`),
+		Status:    "interrupted",
+		Resumable: true,
+	}
+
+	opts, err := ResumeOptions(run)
+	if err != nil {
+		t.Fatalf("ResumeOptions() error: %v", err)
+	}
+	if opts.TargetID != targetID || opts.Channel != "@golang_jobs" || opts.Limit != 500 || !opts.Backfill {
+		t.Errorf("options of the run not kept: %+v", opts)
+	}
+	if opts.ResumeFrom != run.ID {
+		t.Errorf("ResumeFrom = %v, want %v", opts.ResumeFrom, run.ID)
+	}
+
+	run.Resumable = false
+	if _, err := ResumeOptions(run); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("ResumeOptions() error = %v, want ErrNotResumable", err)
+	}
+}
+
+// test taking over the checkpoints of an interrupted run
+func TestService_ResumePoints(t *testing.T) {
+	interrupted, resumed := uuid.New(), uuid.New()
+	store := &mockCheckpointStore{}
+	_ = store.Save(context.Background(), &repository.ScrapeCheckpoint{RunID: interrupted, TopicID: 0, Cursor: 4200, MinMsgID: 4200, MaxMsgID: 9000})
+	_ = store.Save(context.Background(), &repository.ScrapeCheckpoint{RunID: interrupted, TopicID: -77, Done: true})
+
+	svc := newTestService(&MockTelegramClient{})
+	if _, err := svc.resumePoints(context.Background(), ScrapeOptions{ResumeFrom: interrupted, RunID: resumed}); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("resume without checkpoints enabled: error = %v, want ErrNotResumable", err)
+	}
+
+	svc.SetCheckpointStore(store)
+	points, err := svc.resumePoints(context.Background(), ScrapeOptions{})
+	if err != nil || points != nil {
+		t.Errorf("a run from scratch has no resume points, got %v, %v", points, err)
+	}
+
+	points, err = svc.resumePoints(context.Background(), ScrapeOptions{ResumeFrom: interrupted, RunID: resumed})
+	if err != nil {
+		t.Fatalf("resumePoints() error: %v", err)
+	}
+	if len(points) != 2 || points[0].Cursor != 4200 || points[0].RunID != resumed || !points[-77].Done {
+		t.Errorf("unexpected resume points: %+v", points)
+	}
+	if len(store.saved[interrupted]) != 0 {
+		t.Error("the interrupted run should give its checkpoints up")
+	}
+}
+
+// test that only recorded, stored runs are checkpointed
+func TestService_SaveCheckpoint(t *testing.T) {
+	store := &mockCheckpointStore{}
+	svc := newTestService(&MockTelegramClient{})
+	svc.SetCheckpointStore(store)
+	runID := uuid.New()
+
+	svc.saveCheckpoint(ScrapeOptions{}, &repository.ScrapeCheckpoint{Cursor: 1})
+	svc.saveCheckpoint(ScrapeOptions{RunID: runID, DryRun: true}, &repository.ScrapeCheckpoint{RunID: runID, Cursor: 2})
+	if len(store.saved) != 0 {
+		t.Fatalf("unrecorded and dry runs should not be checkpointed: %+v", store.saved)
+	}
+
+	svc.saveCheckpoint(ScrapeOptions{RunID: runID}, &repository.ScrapeCheckpoint{RunID: runID, Cursor: 3})
+	if len(store.saved[runID]) != 1 || store.saved[runID][0].Cursor != 3 {
+		t.Errorf("checkpoint not saved: %+v", store.saved)
+	}
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/feed"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// FeedClient fetches the rss, atom and json feeds of FEED targets
+type FeedClient interface {
+	Fetch(ctx context.Context, url string, cache feed.Validators) (*feed.Response, error)
+}
+
+// feedCache stores the http validators of the last fetched feed of a target
+type feedCache interface {
+	UpdateHTTPCache(ctx context.Context, id uuid.UUID, etag, lastModified string) error
+}
+
+// maxExternalIDLen is the size of jobs.external_id, longer guids are hashed
+const maxExternalIDLen = 255
+
+// SetFeedClient enables scraping of FEED targets
+func (s *Service) SetFeedClient(client FeedClient) {
+	s.RegisterSource("FEED", &feedSource{client: client, cache: s.targets})
+}
+
+// feedSource fetches the feed of FEED targets.
+// fetches are conditional on the validators of the last complete scrape,
+// items are deduplicated by guid.
+type feedSource struct {
+	client FeedClient
+	cache  feedCache
+}
+
+// Resolve fetches the feed and returns its items as a single stream,
+// no stream if the feed did not change. http errors fail the scrape.
+// backfill and dry runs fetch the feed unconditionally.
+func (f *feedSource) Resolve(ctx context.Context, target *repository.ScrapingTarget, opts ScrapeOptions) ([]Stream, error) {
+	var cache feed.Validators
+	if !opts.Backfill && !opts.DryRun {
+		if target.HTTPETag != nil {
+			cache.ETag = *target.HTTPETag
+		}
+		if target.HTTPLastModified != nil {
+			cache.LastModified = *target.HTTPLastModified
+		}
+	}
+
+	resp, err := f.client.Fetch(ctx, target.URL, cache)
+	if errors.Is(err, feed.ErrNotModified) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, targetFailure(fmt.Errorf("fetch feed: %w", err))
+	}
+
+	return []Stream{&feedStream{
+		items:    feedItems(resp.Feed, time.Now()),
+		cache:    f.cache,
+		targetID: target.ID,
+		valid:    resp.Validators,
+	}}, nil
+}
+
+// Job maps a feed item to a job
+func (f *feedSource) Job(ctx context.Context, target *repository.ScrapingTarget, item *Item, opts ScrapeOptions) (*repository.Job, error) {
+	sourceDate := item.Date
+	job := &repository.Job{
+		TargetID:   target.ID,
+		ExternalID: item.ExternalID,
+		RawContent: item.Text,
+		SourceDate: &sourceDate,
+		Status:     "RAW",
+	}
+	if item.URL != "" {
+		sourceURL := item.URL
+		job.SourceURL = &sourceURL
+	}
+	return job, nil
+}
+
+// feedItems converts the items of a fetched feed.
+// undated items get the fetch time so until and ordering still apply.
+func feedItems(f *feed.Feed, fetchedAt time.Time) []Item {
+	items := make([]Item, 0, len(f.Items))
+	for i := range f.Items {
+		it := &f.Items[i]
+		date := it.Published
+		if date.IsZero() {
+			date = fetchedAt
+		}
+		items = append(items, Item{
+			ExternalID: feedExternalID(it.GUID),
+			Date:       date,
+			Text:       it.Text(),
+			URL:        it.Link,
+		})
+	}
+	return items
+}
+
+// feedExternalID returns the guid, hashed if it does not fit jobs.external_id
+func feedExternalID(guid string) string {
+	if len(guid) <= maxExternalIDLen {
+		return guid
+	}
+	sum := sha256.Sum256([]byte(guid))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// feedStream pages through the items of one fetched feed.
+// the cursor is the item offset.
+type feedStream struct {
+	items    []Item
+	cache    feedCache
+	targetID uuid.UUID
+	valid    feed.Validators
+	done     bool // the last item was fetched
+}
+
+// Key returns 0, a feed is one stream
+func (s *feedStream) Key() int64 {
+	return 0
+}
+
+// Fetch returns up to limit items from the cursor offset
+func (s *feedStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	start := min(int(cursor), len(s.items))
+	end := min(start+limit, len(s.items))
+	s.done = end >= len(s.items)
+	return &Batch{
+		Items: s.items[start:end],
+		Next:  int64(end),
+		Done:  s.done,
+	}, nil
+}
+
+// Commit saves the validators of the feed, the next scrape is a conditional GET.
+// a feed cut short by the scrape limit is fetched in full next time.
+func (s *feedStream) Commit(ctx context.Context) error {
+	if !s.done {
+		return nil
+	}
+	return s.cache.UpdateHTTPCache(ctx, s.targetID, s.valid.ETag, s.valid.LastModified)
+}
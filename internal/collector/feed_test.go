@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/feed"
+	"github.com/blockedby/positions-os/internal/feed/feedtest"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// mockFeedCache records the saved http validators
+type mockFeedCache struct {
+	etag, lastModified string
+	saves              int
+}
+
+func (m *mockFeedCache) UpdateHTTPCache(ctx context.Context, id uuid.UUID, etag, lastModified string) error {
+	m.etag, m.lastModified = etag, lastModified
+	m.saves++
+	return nil
+}
+
+func newTestFeedSource(t *testing.T) (*feedSource, *mockFeedCache, *feedtest.Server) {
+	t.Helper()
+	srv := feedtest.NewServer("../feed/testdata")
+	t.Cleanup(srv.Close)
+
+	cache := &mockFeedCache{}
+	return &feedSource{client: feed.NewClient("positions-os-test"), cache: cache}, cache, srv
+}
+
+func TestFeedSource_Resolve(t *testing.T) {
+	src, cache, srv := newTestFeedSource(t)
+	target := &repository.ScrapingTarget{ID: uuid.New(), Type: "FEED", URL: srv.URL + "/rss.xml"}
+
+	streams, err := src.Resolve(context.Background(), target, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if len(streams) != 1 || streams[0].Key() != 0 {
+		t.Fatalf("streams = %+v, want a single stream", streams)
+	}
+	stream := streams[0]
+
+	first, err := stream.Fetch(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[0].ExternalID != "acme-1042" || first.Next != 2 || first.Done {
+		t.Fatalf("first batch = %+v, want the 2 newest items", first)
+	}
+	item := first.Items[0]
+	if item.Seq != 0 || item.URL != "https://acme.example/careers/senior-go" || !strings.HasPrefix(item.Text, "Senior Go Developer") {
+		t.Errorf("feed item = %+v, want guid, link and text without seq", item)
+	}
+
+	// a partly walked feed keeps no validators
+	if err := stream.(committer).Commit(context.Background()); err != nil || cache.saves != 0 {
+		t.Errorf("Commit() before the end = %v, %d saves, want none", err, cache.saves)
+	}
+
+	last, err := stream.Fetch(context.Background(), first.Next, 2)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(last.Items) != 1 || !last.Done {
+		t.Errorf("last batch = %d items, Done = %v, want 1 item and done", len(last.Items), last.Done)
+	}
+	if err := stream.(committer).Commit(context.Background()); err != nil {
+		t.Fatalf("Commit() error: %v", err)
+	}
+	if cache.etag == "" || cache.lastModified != feedtest.LastModified.Format(http.TimeFormat) {
+		t.Errorf("saved validators = %q, %q", cache.etag, cache.lastModified)
+	}
+
+	// unchanged feed: conditional GET, nothing to walk
+	target.HTTPETag = &cache.etag
+	target.HTTPLastModified = &cache.lastModified
+	streams, err = src.Resolve(context.Background(), target, ScrapeOptions{})
+	if err != nil || len(streams) != 0 {
+		t.Errorf("Resolve() unchanged = %d streams, %v, want none", len(streams), err)
+	}
+
+	// backfill ignores the validators
+	streams, err = src.Resolve(context.Background(), target, ScrapeOptions{Backfill: true})
+	if err != nil || len(streams) != 1 {
+		t.Errorf("Resolve() backfill = %d streams, %v, want the feed", len(streams), err)
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 3 || requests[1].Status != http.StatusNotModified || requests[2].Status != http.StatusOK {
+		t.Errorf("requests = %+v, want ok, not modified, ok", requests)
+	}
+}
+
+func TestFeedSource_Resolve_HTTPError(t *testing.T) {
+	src, _, srv := newTestFeedSource(t)
+	target := &repository.ScrapingTarget{ID: uuid.New(), Type: "FEED", URL: srv.URL + "/status/503"}
+
+	_, err := src.Resolve(context.Background(), target, ScrapeOptions{})
+	var statusErr *feed.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Resolve() error = %v, want the 503 as a target error", err)
+	}
+	if !isTargetFailure(err) {
+		t.Errorf("Resolve() error = %v should count against the target", err)
+	}
+}
+
+func TestFeedSource_Job(t *testing.T) {
+	src := &feedSource{}
+	target := &repository.ScrapingTarget{ID: uuid.New(), Type: "FEED"}
+	date := time.Date(2025, 10, 14, 9, 30, 0, 0, time.UTC)
+
+	job, err := src.Job(context.Background(), target, &Item{
+		ExternalID: "acme-1042",
+		Date:       date,
+		Text:       "Senior Go Developer\n\nRemote.",
+		URL:        "https://acme.example/careers/senior-go",
+	}, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Job() error: %v", err)
+	}
+	if job.ExternalID != "acme-1042" || job.TargetID != target.ID || job.Status != "RAW" || job.RawContent == "" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://acme.example/careers/senior-go" {
+		t.Errorf("SourceURL = %v, want the item link", job.SourceURL)
+	}
+	if job.SourceDate == nil || !job.SourceDate.Equal(date) || job.TgMessageID != nil {
+		t.Errorf("SourceDate = %v, TgMessageID = %v", job.SourceDate, job.TgMessageID)
+	}
+
+	// no link
+	job, err = src.Job(context.Background(), target, &Item{ExternalID: "acme-1001", Date: date, Text: "Backend Intern"}, ScrapeOptions{})
+	if err != nil || job.SourceURL != nil {
+		t.Errorf("Job() without link = %+v, %v, want no source url", job, err)
+	}
+}
+
+func TestFeedItems(t *testing.T) {
+	fetchedAt := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	longGUID := "https://example.com/jobs?" + strings.Repeat("q", 300)
+
+	items := feedItems(&feed.Feed{Items: []feed.Item{
+		{GUID: "job-1", Title: "Go Developer", Published: fetchedAt.Add(-time.Hour)},
+		{GUID: longGUID, Title: "Undated"},
+	}}, fetchedAt)
+
+	if len(items) != 2 || items[0].ExternalID != "job-1" || items[0].Text != "Go Developer" {
+		t.Fatalf("items = %+v", items)
+	}
+	if !items[1].Date.Equal(fetchedAt) {
+		t.Errorf("undated item date = %v, want the fetch time", items[1].Date)
+	}
+	if id := items[1].ExternalID; len(id) > maxExternalIDLen || !strings.HasPrefix(id, "sha256:") {
+		t.Errorf("long guid external id = %q, want a hash", id)
+	}
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blockedby/positions-os/internal/models"
+)
+
+// hashtagPattern matches telegram hashtags, e.g. #golang or #вакансия
+var hashtagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+)`)
+
+// MessageFilter is the per-target prefilter applied before a message becomes a job.
+// exclude rules win; if any include rule is set, at least one of them must match.
+// keywords and hashtags are matched case-insensitively.
+type MessageFilter struct {
+	include     []string
+	exclude     []string
+	includeRe   []*regexp.Regexp
+	excludeRe   []*regexp.Regexp
+	includeTags map[string]bool
+	excludeTags map[string]bool
+}
+
+// NewMessageFilter compiles the filter rules from target metadata
+func NewMessageFilter(meta models.TargetMetadata) (*MessageFilter, error) {
+	f := &MessageFilter{
+		include:     lowerAll(meta.Keywords),
+		exclude:     lowerAll(meta.ExcludeKeywords),
+		includeTags: hashtagSet(meta.IncludeHashtags),
+		excludeTags: hashtagSet(meta.ExcludeHashtags),
+	}
+
+	var err error
+	if f.includeRe, err = compileAll(meta.IncludeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRe, err = compileAll(meta.ExcludeRegex); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Skip returns the reason a message is filtered out, empty if it is kept
+func (f *MessageFilter) Skip(text string) string {
+	if f == nil {
+		return ""
+	}
+
+	lower := strings.ToLower(text)
+	tags := hashtags(lower)
+
+	if containsAny(lower, f.exclude) {
+		return "exclude_keyword"
+	}
+	if matchesAny(text, f.excludeRe) {
+		return "exclude_regex"
+	}
+	if hasAnyTag(tags, f.excludeTags) {
+		return "exclude_hashtag"
+	}
+
+	if len(f.include) == 0 && len(f.includeRe) == 0 && len(f.includeTags) == 0 {
+		return ""
+	}
+	if containsAny(lower, f.include) || matchesAny(text, f.includeRe) || hasAnyTag(tags, f.includeTags) {
+		return ""
+	}
+	return "no_include_match"
+}
+
+// hashtags returns the hashtags of a lowercased text
+func hashtags(lower string) []string {
+	matches := hashtagPattern.FindAllStringSubmatch(lower, -1)
+	tags := make([]string, 0, len(matches))
+	for _, m := range matches {
+		tags = append(tags, m[1])
+	}
+	return tags
+}
+
+func containsAny(lower string, keywords []string) bool {
+	for _, kw := range keywords {
+		if kw != "" && strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(text string, res []*regexp.Regexp) bool {
+	for _, re := range res {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyTag(tags []string, set map[string]bool) bool {
+	for _, tag := range tags {
+		if set[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func hashtagSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range lowerAll(values) {
+		set[strings.TrimPrefix(v, "#")] = true
+	}
+	return set
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/models"
+)
+
+// test per-target prefilter rules
+func TestMessageFilter_Skip(t *testing.T) {
+	tests := []struct {
+		name string
+		meta models.TargetMetadata
+		text string
+		want string
+	}{
+		{"no rules keeps everything", models.TargetMetadata{}, "anything", ""},
+		{"include keyword case-insensitive", models.TargetMetadata{Keywords: []string{"golang"}}, "Senior Golang developer", ""},
+		{"include keyword any of", models.TargetMetadata{Keywords: []string{"golang", "remote"}}, "Remote only", ""},
+		{"include keyword no match", models.TargetMetadata{Keywords: []string{"golang"}}, "Java developer", "no_include_match"},
+		{"exclude keyword", models.TargetMetadata{ExcludeKeywords: []string{"реклама"}}, "Реклама: лучший курс", "exclude_keyword"},
+		{"exclude wins over include", models.TargetMetadata{Keywords: []string{"go"}, ExcludeKeywords: []string{"digest"}}, "Go digest of the week", "exclude_keyword"},
+		{"include regex", models.TargetMetadata{IncludeRegex: []string{`\bgo(lang)?\b`}}, "Backend on Go, remote", ""},
+		{"include regex no match", models.TargetMetadata{IncludeRegex: []string{`\bgo(lang)?\b`}}, "Google ads", "no_include_match"},
+		{"exclude regex", models.TargetMetadata{ExcludeRegex: []string{`^#?ad\b`}}, "AD: buy now", "exclude_regex"},
+		{"include hashtag", models.TargetMetadata{IncludeHashtags: []string{"#vacancy"}}, "Go dev #Vacancy #remote", ""},
+		{"include hashtag without hash", models.TargetMetadata{IncludeHashtags: []string{"вакансия"}}, "Go dev #вакансия", ""},
+		{"hashtag is not a keyword", models.TargetMetadata{IncludeHashtags: []string{"vacancy"}}, "no vacancy tag here", "no_include_match"},
+		{"exclude hashtag", models.TargetMetadata{ExcludeHashtags: []string{"digest"}}, "Weekly #digest", "exclude_hashtag"},
+		{"any include rule type matches", models.TargetMetadata{Keywords: []string{"rust"}, IncludeHashtags: []string{"go"}}, "Backend #go", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewMessageFilter(tt.meta)
+			if err != nil {
+				t.Fatalf("NewMessageFilter() error: %v", err)
+			}
+			if got := f.Skip(tt.text); got != tt.want {
+				t.Errorf("Skip(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMessageFilter_InvalidRegex(t *testing.T) {
+	_, err := NewMessageFilter(models.TargetMetadata{ExcludeRegex: []string{"("}})
+	if err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
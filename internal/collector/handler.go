@@ -1,17 +1,31 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
+// RunReader reads the scrape run history
+type RunReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*repository.ScrapeRun, error)
+	List(ctx context.Context, filter repository.RunFilter) ([]*repository.ScrapeRun, int, error)
+}
+
 // Handler handles HTTP requests for collector service
 type Handler struct {
 	manager     *ScrapeManager
 	targetsRepo *repository.TargetsRepository
+	scheduler   *Scheduler
+	runs        RunReader
 }
 
 // NewHandler creates a new handler with the given manager
@@ -22,6 +36,16 @@ func NewHandler(manager *ScrapeManager, targetsRepo *repository.TargetsRepositor
 	}
 }
 
+// SetScheduler enables the schedule endpoint
+func (h *Handler) SetScheduler(scheduler *Scheduler) {
+	h.scheduler = scheduler
+}
+
+// SetRuns enables the run history endpoints
+func (h *Handler) SetRuns(runs RunReader) {
+	h.runs = runs
+}
+
 // Health handles GET /health
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{
@@ -31,6 +55,7 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 }
 
 // StartScrape handles POST /api/v1/scrape/telegram
+// a dry_run request is not queued, the preview is the response
 func (h *Handler) StartScrape(w http.ResponseWriter, r *http.Request) {
 	var req ScrapeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -50,15 +75,27 @@ func (h *Handler) StartScrape(w http.ResponseWriter, r *http.Request) {
 		Limit:    req.Limit,
 		Until:    req.UntilTime(),
 		TopicIDs: req.TopicIDs,
+		Backfill: req.Backfill,
 	}
 	if req.TargetID != nil {
 		opts.TargetID = *req.TargetID
 	}
 
-	// start scraping
+	// a preview is run right away and answered with its result
+	if req.DryRun {
+		result, err := h.manager.Preview(r.Context(), opts)
+		if err != nil {
+			respondError(w, previewStatus(err), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, result)
+		return
+	}
+
+	// enqueue scraping
 	job, err := h.manager.Start(r.Context(), opts)
 	if err != nil {
-		if err == ErrAlreadyRunning {
+		if err == ErrAlreadyQueued {
 			respondError(w, http.StatusConflict, err.Error())
 			return
 		}
@@ -68,7 +105,8 @@ func (h *Handler) StartScrape(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, ScrapeResponse{
 		ScrapeID:  job.ID,
-		Status:    "running",
+		Status:    string(job.Status),
+		QueuedAt:  job.QueuedAt,
 		StartedAt: job.StartedAt,
 		Target: TargetInfo{
 			ID:      job.TargetID,
@@ -77,84 +115,270 @@ func (h *Handler) StartScrape(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// previewStatus is the status of a failed preview: 400 for topics the
+// channel does not have and channels that are unknown or not readable,
+// 503 without a telegram session, like target creation
+func previewStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrTopicsForForum), errors.Is(err, ErrTopicNotFound),
+		errors.Is(err, telegram.ErrChannelNotFound), errors.Is(err, telegram.ErrChannelPrivate):
+		return http.StatusBadRequest
+	case errors.Is(err, telegram.ErrNotReady):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // StopScrape handles DELETE /api/v1/scrape/current
+// cancels the running jobs only, queued ones (scheduler, live catch-up) keep
+// their place. use CancelJob for a single job.
 func (h *Handler) StopScrape(w http.ResponseWriter, r *http.Request) {
-	h.manager.Stop()
-	respondJSON(w, http.StatusOK, map[string]string{
-		"message": "scrape job stopped",
+	cancelled := h.manager.CancelRunning()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":   "running scrape jobs stopped",
+		"cancelled": cancelled,
 	})
 }
 
 // Status handles GET /api/v1/scrape/status
 func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
-	current := h.manager.Current()
+	running := h.manager.Running()
 	tgStatus := h.manager.GetTelegramStatus()
 
-	if current == nil {
-		respondJSON(w, http.StatusOK, map[string]interface{}{
-			"status":          "idle",
-			"telegram_status": string(tgStatus),
-		})
+	resp := map[string]interface{}{
+		"status":          "idle",
+		"running":         len(running),
+		"queued":          h.manager.Queued(),
+		"telegram_status": string(tgStatus),
+	}
+
+	if len(running) > 0 {
+		// oldest running job, kept for clients expecting a single job
+		current := running[0]
+		resp["status"] = "running"
+		resp["scrape_id"] = current.ID.String()
+		resp["started_at"] = current.StartedAt.Format(time.RFC3339)
+		resp["channel"] = current.Options.Channel
+		if current.Progress != nil {
+			resp["progress"] = current.Progress
+		}
+		resp["jobs"] = running
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// ListJobs handles GET /api/v1/scrape/jobs
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.manager.List())
+}
+
+// GetJob handles GET /api/v1/scrape/jobs/{id}
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid job id")
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"status":          "running",
-		"scrape_id":       current.ID.String(),
-		"started_at":      current.StartedAt.Format(time.RFC3339),
-		"channel":         current.Options.Channel,
-		"telegram_status": string(tgStatus),
-	})
+	job, err := h.manager.Get(id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, job)
 }
 
-// ListTargets handles GET /api/v1/targets
-func (h *Handler) ListTargets(w http.ResponseWriter, r *http.Request) {
-	targets, err := h.targetsRepo.GetActive(r.Context())
+// CancelJob handles DELETE /api/v1/scrape/jobs/{id}
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, http.StatusBadRequest, "invalid job id")
 		return
 	}
-	respondJSON(w, http.StatusOK, targets)
+
+	if err := h.manager.Cancel(id); err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "scrape job cancelled",
+	})
 }
 
-// CreateTargetRequest represents request body for creating a target
-type CreateTargetRequest struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	URL  string `json:"url"`
+// MoveJobRequest represents request body for reordering a queued job
+type MoveJobRequest struct {
+	Position int `json:"position"`
 }
 
-// CreateTarget handles POST /api/v1/targets
-func (h *Handler) CreateTarget(w http.ResponseWriter, r *http.Request) {
-	var req CreateTargetRequest
+// MoveJob handles POST /api/v1/scrape/jobs/{id}/move
+func (h *Handler) MoveJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	var req MoveJobRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid json")
 		return
 	}
 
-	if req.Name == "" || req.URL == "" {
-		respondError(w, http.StatusBadRequest, "name and url are required")
+	if err := h.manager.Move(id, req.Position); err != nil {
+		switch err {
+		case ErrJobNotQueued:
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, h.manager.List())
+}
+
+// Schedule handles GET /api/v1/scrape/schedule
+func (h *Handler) Schedule(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		respondJSON(w, http.StatusOK, ScheduleStatus{Targets: []ScheduleEntry{}})
+		return
+	}
+	respondJSON(w, http.StatusOK, h.scheduler.Status())
+}
+
+// ListRuns handles GET /api/v1/scrape/runs
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	if h.runs == nil {
+		respondError(w, http.StatusServiceUnavailable, "run history is not enabled")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+
+	filter := repository.RunFilter{
+		Status: r.URL.Query().Get("status"),
+		Page:   page,
+		Limit:  limit,
+	}
+	if tid := r.URL.Query().Get("target_id"); tid != "" {
+		id, err := uuid.Parse(tid)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid target_id")
+			return
+		}
+		filter.TargetID = &id
+	}
+
+	runs, total, err := h.runs.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":  runs,
+		"total": total,
+		"page":  filter.Page,
+		"limit": filter.Limit,
+	})
+}
+
+// GetRun handles GET /api/v1/scrape/runs/{id}
+func (h *Handler) GetRun(w http.ResponseWriter, r *http.Request) {
+	if h.runs == nil {
+		respondError(w, http.StatusServiceUnavailable, "run history is not enabled")
 		return
 	}
 
-	if req.Type == "" {
-		req.Type = "TG_CHANNEL"
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid run id")
+		return
 	}
 
-	target := &repository.ScrapingTarget{
-		Name:     req.Name,
-		Type:     req.Type,
-		URL:      req.URL,
-		IsActive: true,
-		Metadata: map[string]interface{}{},
+	run, err := h.runs.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if run == nil {
+		respondError(w, http.StatusNotFound, "scrape run not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, run)
+}
+
+// ResumeRun handles POST /api/v1/scrape/runs/{id}/resume
+// queues a run continuing an interrupted one from its checkpoints
+func (h *Handler) ResumeRun(w http.ResponseWriter, r *http.Request) {
+	if h.runs == nil {
+		respondError(w, http.StatusServiceUnavailable, "run history is not enabled")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid run id")
+		return
 	}
 
-	if err := h.targetsRepo.Create(r.Context(), target); err != nil {
+	run, err := h.runs.GetByID(r.Context(), id)
+	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if run == nil {
+		respondError(w, http.StatusNotFound, "scrape run not found")
+		return
+	}
 
-	respondJSON(w, http.StatusCreated, target)
+	opts, err := ResumeOptions(run)
+	if err != nil {
+		if errors.Is(err, ErrNotResumable) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	job, err := h.manager.Start(r.Context(), opts)
+	if err != nil {
+		if err == ErrAlreadyQueued {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ScrapeResponse{
+		ScrapeID:  job.ID,
+		Status:    string(job.Status),
+		QueuedAt:  job.QueuedAt,
+		StartedAt: job.StartedAt,
+		Target: TargetInfo{
+			ID:      job.TargetID,
+			Channel: job.Options.Channel,
+		},
+	})
+}
+
+// ListTargets handles GET /api/v1/targets
+func (h *Handler) ListTargets(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.targetsRepo.GetActive(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, targets)
 }
 
 // ListForumTopics handles GET /api/v1/tools/telegram/topics
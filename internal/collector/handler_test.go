@@ -2,13 +2,19 @@ package collector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
 )
 
 // test health endpoint
@@ -100,7 +106,7 @@ func TestHandler_StartScrape(t *testing.T) {
 		}
 	})
 
-	t.Run("returns 409 when already running", func(t *testing.T) {
+	t.Run("returns 409 when target already queued", func(t *testing.T) {
 		manager := NewScrapeManager(&MockScraper{Delay: 100 * time.Millisecond})
 		handler := NewHandler(manager, nil)
 		router := NewRouter(handler)
@@ -116,8 +122,8 @@ func TestHandler_StartScrape(t *testing.T) {
 			t.Fatalf("first request failed: %d", rec.Code)
 		}
 
-		// try second job
-		body = `{"channel": "@second"}`
+		// try same target again
+		body = `{"channel": "@first"}`
 		req = httptest.NewRequest(http.MethodPost, "/api/v1/scrape/telegram", bytes.NewBufferString(body))
 		req.Header.Set("Content-Type", "application/json")
 		rec = httptest.NewRecorder()
@@ -127,6 +133,66 @@ func TestHandler_StartScrape(t *testing.T) {
 			t.Errorf("second request status = %d, want %d", rec.Code, http.StatusConflict)
 		}
 	})
+
+	t.Run("returns the preview of a dry run", func(t *testing.T) {
+		scraper := &MockScraper{Result: &ScrapeResult{
+			NewJobs: 1,
+			Preview: []PreviewItem{
+				{ExternalID: "2", Content: "Go developer"},
+				{ExternalID: "1", Skip: SkipAlreadyCollected},
+			},
+		}}
+		manager := NewScrapeManager(scraper)
+		router := NewRouter(NewHandler(manager, nil))
+
+		body := `{"channel": "@test_channel", "dry_run": true}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/scrape/telegram", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("StartScrape() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp ScrapeResult
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.NewJobs != 1 || len(resp.Preview) != 2 || resp.Preview[1].Skip != SkipAlreadyCollected {
+			t.Errorf("unexpected preview: %+v", resp)
+		}
+		if !scraper.Opts.DryRun || scraper.Opts.Limit != DefaultPreviewLimit {
+			t.Errorf("scraper options = %+v, want a dry run with the preview limit", scraper.Opts)
+		}
+		if len(manager.List()) != 0 {
+			t.Errorf("dry run was queued: %+v", manager.List())
+		}
+	})
+
+	t.Run("dry run errors", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			want int
+		}{
+			{fmt.Errorf("get target: %w: no_such", telegram.ErrChannelNotFound), http.StatusBadRequest},
+			{fmt.Errorf("get target: %w: closed", telegram.ErrChannelPrivate), http.StatusBadRequest},
+			{ErrTopicNotFound, http.StatusBadRequest},
+			{fmt.Errorf("get target: %w", telegram.ErrNotReady), http.StatusServiceUnavailable},
+			{errors.New("connection refused"), http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			router := NewRouter(NewHandler(NewScrapeManager(&MockScraper{Err: tt.err}), nil))
+			body := `{"channel": "@test_channel", "dry_run": true}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/scrape/telegram", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+			}
+		}
+	})
 }
 
 // test stop scrape endpoint
@@ -167,10 +233,310 @@ func TestHandler_StopScrape(t *testing.T) {
 		}
 
 		// verify it stopped
-		if manager.Current() != nil {
+		time.Sleep(10 * time.Millisecond)
+		if len(manager.Running()) != 0 {
 			t.Error("job should be stopped")
 		}
 	})
+
+	t.Run("keeps queued jobs", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		router := NewRouter(NewHandler(manager, nil))
+
+		_, _ = manager.Start(context.Background(), ScrapeOptions{Channel: "running"})
+		queued, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "queued"})
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/scrape/current", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp struct {
+			Cancelled int `json:"cancelled"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Cancelled != 1 {
+			t.Errorf("StopScrape() cancelled = %d (%v), want 1", resp.Cancelled, err)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		if job, err := manager.Get(queued.ID); err != nil || job.Status == ScrapeCancelled {
+			t.Errorf("queued job = %+v, %v, should not be cancelled", job, err)
+		}
+	})
+}
+
+// test scrape jobs endpoints
+func TestHandler_Jobs(t *testing.T) {
+	startJob := func(t *testing.T, router http.Handler, channel string) ScrapeResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/scrape/telegram", bytes.NewBufferString(`{"channel": "`+channel+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("start %s failed: %d", channel, rec.Code)
+		}
+		var resp ScrapeResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("lists running and queued jobs", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		router := NewRouter(NewHandler(manager, nil))
+
+		startJob(t, router, "@first")
+		second := startJob(t, router, "@second")
+		if second.Status != "queued" {
+			t.Errorf("second job status = %s, want queued", second.Status)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/jobs", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var jobs []map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&jobs); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(jobs) != 2 {
+			t.Fatalf("expected 2 jobs, got %d", len(jobs))
+		}
+		if jobs[0]["status"] != "running" || jobs[1]["status"] != "queued" {
+			t.Errorf("unexpected statuses: %v, %v", jobs[0]["status"], jobs[1]["status"])
+		}
+	})
+
+	t.Run("cancels job by id", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		router := NewRouter(NewHandler(manager, nil))
+
+		startJob(t, router, "@first")
+		second := startJob(t, router, "@second")
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/scrape/jobs/"+second.ScrapeID.String(), nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("CancelJob() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if manager.Queued() != 0 {
+			t.Error("queued job should be cancelled")
+		}
+		if len(manager.Running()) != 1 {
+			t.Error("running job should not be affected")
+		}
+	})
+
+	t.Run("returns 404 for unknown job", func(t *testing.T) {
+		router := NewRouter(NewHandler(NewScrapeManager(&MockScraper{}), nil))
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/scrape/jobs/"+uuid.New().String(), nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("CancelJob() status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("moves queued job", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		router := NewRouter(NewHandler(manager, nil))
+
+		running := startJob(t, router, "@first")
+		startJob(t, router, "@second")
+		third := startJob(t, router, "@third")
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/scrape/jobs/"+third.ScrapeID.String()+"/move", bytes.NewBufferString(`{"position": 0}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("MoveJob() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		jobs := manager.List()
+		if jobs[1].ID != third.ScrapeID {
+			t.Error("moved job should be first in queue")
+		}
+
+		// running jobs can't be moved
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/scrape/jobs/"+running.ScrapeID.String()+"/move", bytes.NewBufferString(`{"position": 0}`))
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("MoveJob(running) status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+}
+
+// mockRunReader serves runs from memory
+type mockRunReader struct {
+	runs []*repository.ScrapeRun
+}
+
+func (m *mockRunReader) GetByID(ctx context.Context, id uuid.UUID) (*repository.ScrapeRun, error) {
+	for _, run := range m.runs {
+		if run.ID == id {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRunReader) List(ctx context.Context, filter repository.RunFilter) ([]*repository.ScrapeRun, int, error) {
+	var runs []*repository.ScrapeRun
+	for _, run := range m.runs {
+		if filter.Status != "" && run.Status != filter.Status {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, len(runs), nil
+}
+
+// test run history endpoints
+func TestHandler_Runs(t *testing.T) {
+	errText := "resolve channel: not found"
+	runs := &mockRunReader{runs: []*repository.ScrapeRun{
+		{ID: uuid.New(), Channel: "@ok", Status: "completed", TotalFetched: 10, NewJobs: 2, StartedAt: time.Now()},
+		{ID: uuid.New(), Channel: "@missing", Status: "failed", Error: &errText, StartedAt: time.Now()},
+	}}
+
+	newRouter := func() http.Handler {
+		handler := NewHandler(NewScrapeManager(&MockScraper{}), nil)
+		handler.SetRuns(runs)
+		return NewRouter(handler)
+	}
+
+	t.Run("lists runs filtered by status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/runs?status=failed", nil)
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("ListRuns() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+
+		var resp struct {
+			Runs  []map[string]interface{} `json:"runs"`
+			Total int                      `json:"total"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Total != 1 || len(resp.Runs) != 1 {
+			t.Fatalf("expected 1 run, got %d", len(resp.Runs))
+		}
+		if resp.Runs[0]["error"] != errText {
+			t.Errorf("error = %v, want %q", resp.Runs[0]["error"], errText)
+		}
+	})
+
+	t.Run("returns run by id with counters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/runs/"+runs.runs[0].ID.String(), nil)
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GetRun() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+
+		var resp map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for _, key := range []string{"total_fetched", "new_jobs", "skipped_old", "skipped_empty", "errors", "started_at"} {
+			if _, ok := resp[key]; !ok {
+				t.Errorf("missing key %q in run", key)
+			}
+		}
+	})
+
+	t.Run("returns 404 for unknown run", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/runs/"+uuid.New().String(), nil)
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GetRun() status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("returns 503 without run history", func(t *testing.T) {
+		router := NewRouter(NewHandler(NewScrapeManager(&MockScraper{}), nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/runs", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("ListRuns() status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// test resuming an interrupted run
+func TestHandler_ResumeRun(t *testing.T) {
+	targetID := uuid.New()
+	interrupted := &repository.ScrapeRun{
+		ID: uuid.New(), TargetID: &targetID, Channel: "@golang_jobs", Status: "interrupted", Resumable: true,
+		Options: json.RawMessage(`{"channel":"@golang_jobs","limit":500}`),
+	}
+	completed := &repository.ScrapeRun{ID: uuid.New(), Channel: "@ok", Status: "completed", Options: json.RawMessage(`{}`)}
+	runs := &mockRunReader{runs: []*repository.ScrapeRun{interrupted, completed}}
+
+	resume := func(scraper *MockScraper, id uuid.UUID) *httptest.ResponseRecorder {
+		handler := NewHandler(NewScrapeManager(scraper), nil)
+		handler.SetRuns(runs)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/scrape/runs/"+id.String()+"/resume", nil)
+		rec := httptest.NewRecorder()
+		NewRouter(handler).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("queues a run from the checkpoints", func(t *testing.T) {
+		scraper := &MockScraper{}
+		rec := resume(scraper, interrupted.ID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("ResumeRun() status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+		}
+
+		var resp ScrapeResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Target.ID != targetID || resp.ScrapeID == interrupted.ID {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		scraper.mu.Lock()
+		opts := scraper.Opts
+		scraper.mu.Unlock()
+		if opts.ResumeFrom != interrupted.ID || opts.TargetID != targetID || opts.Limit != 500 {
+			t.Errorf("unexpected scrape options: %+v", opts)
+		}
+	})
+
+	t.Run("returns 409 for a run that is not resumable", func(t *testing.T) {
+		if rec := resume(&MockScraper{}, completed.ID); rec.Code != http.StatusConflict {
+			t.Errorf("ResumeRun() status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("returns 404 for unknown run", func(t *testing.T) {
+		if rec := resume(&MockScraper{}, uuid.New()); rec.Code != http.StatusNotFound {
+			t.Errorf("ResumeRun() status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
 }
 
 // test status endpoint
@@ -221,6 +587,41 @@ func TestHandler_Status(t *testing.T) {
 			t.Errorf("telegram_status = %v, want READY", telegramStatus)
 		}
 	})
+
+	t.Run("returns live counters of the running job", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{
+			Delay:    time.Second,
+			Progress: &ScrapeProgress{Batch: 2, Offset: 500, Fetched: 200, NewJobs: 12, SkippedOld: 150},
+		})
+		defer manager.Stop()
+		router := NewRouter(NewHandler(manager, nil))
+
+		if _, err := manager.Start(context.Background(), ScrapeOptions{Channel: "@test"}); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+
+		var progress map[string]interface{}
+		deadline := time.Now().Add(time.Second)
+		for progress == nil && time.Now().Before(deadline) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/status", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			var resp map[string]interface{}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			progress, _ = resp["progress"].(map[string]interface{})
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if progress == nil {
+			t.Fatal("status has no progress of the running job")
+		}
+		if progress["batch"] != 2.0 || progress["offset"] != 500.0 || progress["fetched"] != 200.0 || progress["new_jobs"] != 12.0 {
+			t.Errorf("progress = %v", progress)
+		}
+	})
 }
 
 func keys(m map[string]interface{}) []string {
@@ -269,3 +670,66 @@ func TestHandler_ListForumTopics(t *testing.T) {
 		}
 	})
 }
+
+// test schedule endpoint
+func TestHandler_Schedule(t *testing.T) {
+	t.Run("returns disabled without scheduler", func(t *testing.T) {
+		handler := NewHandler(NewScrapeManager(&MockScraper{}), nil)
+		router := NewRouter(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/schedule", nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Schedule() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+
+		var resp ScheduleStatus
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Enabled {
+			t.Error("expected enabled=false")
+		}
+	})
+
+	t.Run("returns scheduled targets", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		lister := &mockTargetLister{targets: []repository.ScrapingTarget{scheduledTestTarget("2h", nil)}}
+		scheduler := NewScheduler(manager, lister, &mockFloodWaiter{}, time.Minute, logger.Get())
+		scheduler.tick(context.Background(), time.Now())
+
+		handler := NewHandler(manager, nil)
+		handler.SetScheduler(scheduler)
+		router := NewRouter(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/scrape/schedule", nil)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		var resp map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp["enabled"] != true {
+			t.Error("expected enabled=true")
+		}
+		targets, _ := resp["targets"].([]interface{})
+		if len(targets) != 1 {
+			t.Fatalf("expected 1 target, got %d", len(targets))
+		}
+		entry := targets[0].(map[string]interface{})
+		for _, key := range []string{"target_id", "interval", "next_run_at", "last_run_at"} {
+			if _, ok := entry[key]; !ok {
+				t.Errorf("missing key %q in schedule entry", key)
+			}
+		}
+		if entry["interval"] != "2h0m0s" {
+			t.Errorf("interval = %v, want 2h0m0s", entry["interval"])
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxFailures is the number of consecutive failed runs after which
+// a target is deactivated
+const DefaultMaxFailures = 5
+
+// SetMaxFailures sets after how many consecutive failed runs a target is
+// deactivated, 0 keeps failing targets active
+func (s *Service) SetMaxFailures(n int) {
+	if n < 0 {
+		n = 0
+	}
+	s.maxFailures = n
+}
+
+// targetError is a failure of the target itself: it could not be resolved
+// or fetched (channel gone or private, http status). only these count
+// against the health of a target.
+type targetError struct {
+	err error
+}
+
+func (e *targetError) Error() string { return e.err.Error() }
+func (e *targetError) Unwrap() error { return e.err }
+
+// targetFailure marks an error of resolving or fetching a target
+func targetFailure(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &targetError{err: err}
+}
+
+// isTargetFailure reports whether an error is the target's failure, and
+// not one of the request (unknown topics), the configuration (invalid
+// metadata, source not configured) or the storage
+func isTargetFailure(err error) bool {
+	var te *targetError
+	return errors.As(err, &te)
+}
+
+// recordHealth stores the outcome of a scrape run on its target: a success
+// resets the failures and adds to the new jobs average, a failure is counted
+// and deactivates the target after maxFailures in a row. a run that fetched
+// nothing because every fetch failed is a failure too.
+// dry runs, cancelled runs, unsaved targets and failures that are not the
+// target's fault (see isTargetFailure; telegram not connected, flood wait)
+// are left out.
+func (s *Service) recordHealth(ctx context.Context, opts ScrapeOptions, target *repository.ScrapingTarget, result *ScrapeResult, err error) {
+	if s.targets == nil || target == nil || target.ID == uuid.Nil || opts.DryRun || ctx.Err() != nil {
+		return
+	}
+	if err == nil && result != nil && result.TotalFetched == 0 && result.fetchErr != nil {
+		err = targetFailure(result.fetchErr)
+	}
+
+	if err == nil {
+		newJobs := 0
+		if result != nil {
+			newJobs = result.NewJobs
+		}
+		if err := s.targets.RecordSuccess(ctx, target.ID, newJobs); err != nil {
+			s.log.Warn().Err(err).Msg("scrape: failed to record target health")
+		}
+		return
+	}
+
+	if !isTargetFailure(err) {
+		s.log.Debug().Err(err).Str("target_id", target.ID.String()).Msg("scrape: not a failure of the target, not counted")
+		return
+	}
+	if s.unavailable(target, err) {
+		s.log.Debug().Err(err).Str("target_id", target.ID.String()).Msg("scrape: telegram unavailable, failure not counted")
+		return
+	}
+	disabled, rerr := s.targets.RecordFailure(ctx, target.ID, err.Error(), s.maxFailures)
+	if rerr != nil {
+		s.log.Warn().Err(rerr).Msg("scrape: failed to record target health")
+		return
+	}
+	if disabled {
+		s.log.Warn().
+			Str("target_id", target.ID.String()).
+			Str("target", target.URL).
+			Int("failures", s.maxFailures).
+			Err(err).
+			Msg("scrape: target deactivated after consecutive failures")
+	}
+}
+
+// unavailable reports whether a telegram target failed because of the
+// telegram session rather than the target: not connected or flood wait
+func (s *Service) unavailable(target *repository.ScrapingTarget, err error) bool {
+	if !target.IsTelegram() {
+		return false
+	}
+	if s.tgClient == nil || s.tgClient.GetStatus() != telegram.StatusReady {
+		return true
+	}
+	return strings.Contains(err.Error(), "FLOOD_WAIT")
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// statusClient is a telegram client with a given connection status
+type statusClient struct {
+	MockTelegramClient
+	status telegram.Status
+}
+
+func (c *statusClient) GetStatus() telegram.Status {
+	return c.status
+}
+
+// test which failures are not counted against a target
+func TestService_Unavailable(t *testing.T) {
+	channel := &repository.ScrapingTarget{Type: "TG_CHANNEL"}
+	feed := &repository.ScrapingTarget{Type: "FEED"}
+	resolveErr := errors.New("resolve channel: USERNAME_NOT_OCCUPIED")
+	floodErr := errors.New("resolve channel: rpc error code 420: FLOOD_WAIT_30")
+
+	ready := newTestService(&MockTelegramClient{})
+	if ready.unavailable(channel, resolveErr) {
+		t.Error("a channel that can not be resolved is the target's failure")
+	}
+	if !ready.unavailable(channel, floodErr) {
+		t.Error("a flood wait should not count against the target")
+	}
+	if ready.unavailable(feed, floodErr) {
+		t.Error("feed failures always count")
+	}
+
+	offline := newTestService(&statusClient{status: telegram.StatusUnauthorized})
+	if !offline.unavailable(channel, resolveErr) {
+		t.Error("failures without a telegram session should not count against the target")
+	}
+}
+
+// test that runs without a stored target leave no health record
+func TestService_RecordHealthSkipped(t *testing.T) {
+	svc := newTestService(&MockTelegramClient{})
+	// no targets repository: would panic if anything was recorded
+	svc.recordHealth(context.Background(), ScrapeOptions{}, &repository.ScrapingTarget{}, &ScrapeResult{}, errors.New("boom"))
+
+	svc.targets = &repository.TargetsRepository{}
+	svc.recordHealth(context.Background(), ScrapeOptions{DryRun: true}, &repository.ScrapingTarget{ID: uuid.New(), Type: "FEED"}, nil, errors.New("boom"))
+	svc.recordHealth(context.Background(), ScrapeOptions{}, nil, nil, errors.New("resolve target"))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.recordHealth(cancelled, ScrapeOptions{}, &repository.ScrapingTarget{ID: uuid.New(), Type: "FEED"}, nil, context.Canceled)
+}
+
+// test which errors count against a target: failures to resolve or fetch
+// it, not the ones of the request, the configuration or the storage
+func TestIsTargetFailure(t *testing.T) {
+	svc := newTestService(&MockTelegramClient{})
+	channel := &repository.ScrapingTarget{ID: uuid.New(), Type: "TG_CHANNEL", URL: "@gone_channel"}
+
+	_, resolveErr := svc.sources["TG_CHANNEL"].Resolve(context.Background(), channel, ScrapeOptions{})
+	plain := newTestService(&MockTelegramClient{Channel: &telegram.Channel{ID: 1}})
+	_, topicsErr := plain.sources["TG_CHANNEL"].Resolve(context.Background(), channel, ScrapeOptions{TopicIDs: []int{15}})
+	forum := newTestService(&MockTelegramClient{Channel: &telegram.Channel{ID: 1, IsForum: true}})
+	_, unknownTopicErr := forum.sources["TG_FORUM"].Resolve(context.Background(), channel, ScrapeOptions{TopicIDs: []int{99}, DryRun: true})
+	_, filterErr := NewMessageFilter(models.TargetMetadata{ExcludeRegex: []string{"("}})
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"channel not resolved", resolveErr, true},
+		{"failed fetch", targetFailure(errors.New("get history: CHANNEL_PRIVATE")), true},
+		{"topics of a channel", topicsErr, false},
+		{"unknown topic", unknownTopicErr, false},
+		{"source not configured", fmt.Errorf("%w: HH_SEARCH", ErrUnsupportedTarget), false},
+		{"invalid filter", fmt.Errorf("target filter: %w", filterErr), false},
+		{"storage", errors.New("create filter: failed to connect to `host=db`"), false},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		if got := isTargetFailure(tt.err); got != tt.want {
+			t.Errorf("%s: isTargetFailure(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+
+	// not counted: nothing is recorded, the zero repository would panic
+	svc.targets = &repository.TargetsRepository{}
+	for _, tt := range tests {
+		if !tt.want && tt.err != nil {
+			svc.recordHealth(context.Background(), ScrapeOptions{}, channel, nil, tt.err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/blockedby/positions-os/internal/hh"
+	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
+)
+
+// HHClient is the hh.ru api used by HH_SEARCH targets
+type HHClient interface {
+	Search(ctx context.Context, params url.Values, page, perPage int) (*hh.SearchPage, error)
+	GetVacancy(ctx context.Context, id string) (*hh.Vacancy, error)
+}
+
+// SetHHClient enables scraping of HH_SEARCH targets
+func (s *Service) SetHHClient(client HHClient) {
+	s.RegisterSource("HH_SEARCH", &hhSource{client: client})
+}
+
+// hhSource runs the saved hh.ru search of HH_SEARCH targets.
+// vacancies have no sequence ids, they are deduplicated by vacancy id.
+type hhSource struct {
+	client HHClient
+}
+
+// Resolve returns the search of the target as a single stream
+func (h *hhSource) Resolve(ctx context.Context, target *repository.ScrapingTarget, opts ScrapeOptions) ([]Stream, error) {
+	params, err := hhSearchParams(target)
+	if err != nil {
+		return nil, err
+	}
+	return []Stream{&searchStream{client: h.client, params: params}}, nil
+}
+
+// Job fetches the full vacancy (search items only carry a snippet) and maps it
+// to a job with the api fields pre-filled as structured data.
+// a vacancy removed since the search is skipped.
+func (h *hhSource) Job(ctx context.Context, target *repository.ScrapingTarget, item *Item, opts ScrapeOptions) (*repository.Job, error) {
+	v, err := h.client.GetVacancy(ctx, item.ExternalID)
+	if errors.Is(err, hh.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vacancyJob(target, v), nil
+}
+
+// hhSearchParams returns the api search of a target:
+// metadata.search if set, the target url otherwise
+func hhSearchParams(target *repository.ScrapingTarget) (url.Values, error) {
+	meta, err := models.ParseTargetMetadata(target.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("target metadata: %w", err)
+	}
+	search := meta.Search
+	if search == "" {
+		search = target.URL
+	}
+	params, err := hh.ParseSearchURL(search)
+	if err != nil {
+		return nil, fmt.Errorf("hh search: %w", err)
+	}
+	return params, nil
+}
+
+// searchStream pages through a search, newest vacancies first.
+// the cursor is the page number; the api only pages through the first hh.MaxDepth vacancies.
+type searchStream struct {
+	client HHClient
+	params url.Values
+}
+
+// Key returns 0, a search is one stream
+func (s *searchStream) Key() int64 {
+	return 0
+}
+
+// Fetch returns the search page cursor
+func (s *searchStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	page := int(cursor)
+	res, err := s.client.Search(ctx, s.params, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &Batch{
+		Items: make([]Item, 0, len(res.Items)),
+		Next:  cursor + 1,
+		Done:  page+1 >= res.Pages || (page+1)*res.PerPage >= hh.MaxDepth,
+	}
+	for i := range res.Items {
+		v := &res.Items[i]
+		batch.Items = append(batch.Items, Item{
+			ExternalID: v.ID,
+			Date:       v.PublishedAt.Time,
+			Text:       v.Text(),
+		})
+	}
+	return batch, nil
+}
+
+// vacancyJob builds a job from a full vacancy
+func vacancyJob(target *repository.ScrapingTarget, v *hh.Vacancy) *repository.Job {
+	job := &repository.Job{
+		TargetID:       target.ID,
+		ExternalID:     v.ID,
+		RawContent:     v.Text(),
+		Status:         "RAW",
+		StructuredData: v.StructuredData(),
+	}
+	if v.AlternateURL != "" {
+		sourceURL := v.AlternateURL
+		job.SourceURL = &sourceURL
+	}
+	if !v.PublishedAt.IsZero() {
+		sourceDate := v.PublishedAt.Time
+		job.SourceDate = &sourceDate
+	}
+	return job
+}
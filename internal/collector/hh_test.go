@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/hh"
+	"github.com/blockedby/positions-os/internal/hh/hhtest"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+func newTestHHClient(t *testing.T) (*hh.Client, *hhtest.Server) {
+	t.Helper()
+	srv := hhtest.NewServer("../hh/testdata")
+	t.Cleanup(srv.Close)
+
+	client := hh.NewClient(srv.URL, "positions-os-test")
+	client.SetRequestInterval(0)
+	return client, srv
+}
+
+func TestSearchStream_Fetch(t *testing.T) {
+	client, srv := newTestHHClient(t)
+	stream := &searchStream{client: client, params: url.Values{"text": {"golang"}}}
+
+	first, err := stream.Fetch(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[0].ExternalID != "93353083" || first.Items[1].ExternalID != "93350001" {
+		t.Fatalf("first page = %+v, want the 2 newest vacancies", first.Items)
+	}
+	item := first.Items[0]
+	if item.Seq != 0 || item.Date.IsZero() || item.Text == "" {
+		t.Errorf("vacancy item = %+v, want no seq, publication date and text", item)
+	}
+	if first.Next != 1 || first.Done {
+		t.Errorf("Next = %d, Done = %v, want next page 1", first.Next, first.Done)
+	}
+
+	last, err := stream.Fetch(context.Background(), first.Next, 2)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(last.Items) != 1 || !last.Done {
+		t.Errorf("last page = %d items, Done = %v, want 1 item and done", len(last.Items), last.Done)
+	}
+
+	if requests := srv.Requests(); len(requests) != 2 {
+		t.Errorf("requests = %v, want one per page", requests)
+	}
+}
+
+func TestHHSource_Job(t *testing.T) {
+	client, _ := newTestHHClient(t)
+	src := &hhSource{client: client}
+	target := &repository.ScrapingTarget{ID: uuid.New(), Type: "HH_SEARCH"}
+
+	job, err := src.Job(context.Background(), target, &Item{ExternalID: "93353083"}, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Job() error: %v", err)
+	}
+	if job.ExternalID != "93353083" || job.TargetID != target.ID || job.Status != "RAW" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://hh.ru/vacancy/93353083" {
+		t.Errorf("SourceURL = %v, want the vacancy page", job.SourceURL)
+	}
+	if job.SourceDate == nil || job.TgMessageID != nil {
+		t.Errorf("SourceDate = %v, TgMessageID = %v", job.SourceDate, job.TgMessageID)
+	}
+	if job.StructuredData["company"] != "Яндекс" || job.StructuredData["salary_min"] != 250000 {
+		t.Errorf("StructuredData = %v, want api fields", job.StructuredData)
+	}
+
+	// removed since the search
+	job, err = src.Job(context.Background(), target, &Item{ExternalID: "1"}, ScrapeOptions{})
+	if err != nil || job != nil {
+		t.Errorf("Job() = %v, %v, want a skipped item", job, err)
+	}
+}
+
+func TestHHSearchParams(t *testing.T) {
+	target := &repository.ScrapingTarget{
+		ID:   uuid.New(),
+		Type: "HH_SEARCH",
+		URL:  "https://hh.ru/search/vacancy?text=golang&area=1",
+	}
+
+	params, err := hhSearchParams(target)
+	if err != nil {
+		t.Fatalf("hhSearchParams() error: %v", err)
+	}
+	if params.Get("text") != "golang" || params.Get("area") != "1" {
+		t.Errorf("params from url = %v", params)
+	}
+
+	target.Metadata = map[string]interface{}{"search": "text=rust&schedule=remote"}
+	params, err = hhSearchParams(target)
+	if err != nil {
+		t.Fatalf("hhSearchParams() error: %v", err)
+	}
+	if params.Get("text") != "rust" || params.Get("schedule") != "remote" {
+		t.Errorf("metadata search should win, got %v", params)
+	}
+}
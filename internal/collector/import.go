@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+)
+
+// ErrForumImport is returned when importing into a forum target:
+// exported messages do not carry their topic
+var ErrForumImport = errors.New("exports can not be imported into forum targets")
+
+// Import creates jobs from the messages of a Telegram Desktop export
+// (telegram.ReadExport) of a telegram target. it takes the same path as a
+// scrape with backfill: parsed ranges, empty and prefilter checks, edits of
+// already collected messages, job creation and the jobs.new publish.
+// exported documents are read from the export, no telegram session is used.
+// last_scraped_at is left alone, the next scrape picks up newer messages.
+func (s *Service) Import(ctx context.Context, target *repository.ScrapingTarget, messages []telegram.Message) (*ScrapeResult, error) {
+	if target.IsForum() {
+		return nil, ErrForumImport
+	}
+	src, ok := s.sources[target.Type]
+	if !ok || !target.IsTelegram() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedTarget, target.Type)
+	}
+	filter, err := s.targetFilter(target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info().
+		Str("target_id", target.ID.String()).
+		Int("messages", len(messages)).
+		Msg("import: starting")
+
+	result := &ScrapeResult{TargetID: target.ID}
+	stream := newExportStream(messages)
+	// a scrape walks a bounded number of batches, long exports take several;
+	// each walk jumps over the ranges parsed by the previous ones
+	for ctx.Err() == nil && !stream.done {
+		oldest := stream.oldest
+		if _, err := s.scrapeStream(ctx, target, src, stream, ScrapeOptions{Backfill: true}, filter, result, nil); err != nil {
+			return nil, err
+		}
+		if stream.oldest == oldest {
+			break
+		}
+	}
+
+	s.logCompleted(result)
+	return result, ctx.Err()
+}
+
+// exportStream serves exported messages newest first, like the history
+// of a channel
+type exportStream struct {
+	messages []telegram.Message // newest first
+	oldest   int64              // oldest message id served
+	done     bool               // the oldest message was served
+}
+
+func newExportStream(messages []telegram.Message) *exportStream {
+	sorted := make([]telegram.Message, len(messages))
+	copy(sorted, messages)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID > sorted[j].ID })
+	return &exportStream{messages: sorted}
+}
+
+// Key returns 0, the export is the history of the whole channel
+func (e *exportStream) Key() int64 {
+	return 0
+}
+
+// Fetch returns messages older than cursor (0 = newest)
+func (e *exportStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	start := 0
+	if cursor > 0 {
+		start = sort.Search(len(e.messages), func(i int) bool { return int64(e.messages[i].ID) < cursor })
+	}
+	end := min(start+limit, len(e.messages))
+
+	batch := messageBatch(e.messages[start:end])
+	if end > start {
+		if id := int64(e.messages[end-1].ID); e.oldest == 0 || id < e.oldest {
+			e.oldest = id
+		}
+	}
+	if end == len(e.messages) {
+		batch.Done = true
+		e.done = true
+	}
+	return batch, nil
+}
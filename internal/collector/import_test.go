@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+)
+
+// test that exported messages are served newest first in batches
+func TestExportStream_Fetch(t *testing.T) {
+	date := time.Date(2023, 1, 10, 10, 0, 0, 0, time.UTC)
+	stream := newExportStream([]telegram.Message{
+		{ID: 2, Text: "a", Date: date},
+		{ID: 3, Text: "b", Date: date},
+		{ID: 7, Text: "c", Date: date},
+		{ID: 9, Text: "d", Date: date},
+		{ID: 10, Text: "e", Date: date},
+	})
+
+	batch, err := stream.Fetch(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	if len(batch.Items) != 2 || batch.Items[0].Seq != 10 || batch.Items[1].Seq != 9 || batch.Done {
+		t.Errorf("first batch = %+v", batch)
+	}
+
+	// the cursor may point into a gap of deleted messages
+	batch, _ = stream.Fetch(context.Background(), 8, 2)
+	if len(batch.Items) != 2 || batch.Items[0].Seq != 7 || batch.Items[1].Seq != 3 || batch.Done {
+		t.Errorf("batch below 8 = %+v", batch)
+	}
+	if stream.oldest != 3 || stream.done {
+		t.Errorf("oldest = %d, done = %v", stream.oldest, stream.done)
+	}
+
+	batch, _ = stream.Fetch(context.Background(), 3, 2)
+	if len(batch.Items) != 1 || batch.Items[0].Seq != 2 || !batch.Done || !stream.done {
+		t.Errorf("last batch = %+v, done = %v", batch, stream.done)
+	}
+
+	// going back to the newest messages keeps the oldest served id
+	stream.Fetch(context.Background(), 0, 2)
+	if stream.oldest != 2 {
+		t.Errorf("oldest = %d, want 2", stream.oldest)
+	}
+}
+
+// test that exports are only imported into channel and group targets
+func TestService_ImportUnsupportedTarget(t *testing.T) {
+	svc := newTestService(&MockTelegramClient{})
+	messages := []telegram.Message{{ID: 1, Text: "Go developer"}}
+
+	if _, err := svc.Import(context.Background(), &repository.ScrapingTarget{Type: "TG_FORUM"}, messages); !errors.Is(err, ErrForumImport) {
+		t.Errorf("forum target: expected ErrForumImport, got %v", err)
+	}
+	if _, err := svc.Import(context.Background(), &repository.ScrapingTarget{Type: "FEED"}, messages); !errors.Is(err, ErrUnsupportedTarget) {
+		t.Errorf("feed target: expected ErrUnsupportedTarget, got %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// LiveClient is the telegram side of live ingestion
+type LiveClient interface {
+	ChannelResolver
+	JoinChannel(ctx context.Context, channel *telegram.Channel) error
+	GetStatus() telegram.Status
+}
+
+// MessageIngester creates a job from a single message of a target
+// and closes the jobs of deleted messages
+type MessageIngester interface {
+	Ingest(ctx context.Context, target *repository.ScrapingTarget, msg telegram.Message) (bool, error)
+	CloseDeleted(ctx context.Context, target *repository.ScrapingTarget, msgIDs []int) (int, error)
+}
+
+// LiveIngester creates jobs from new channel messages as they arrive.
+// active telegram targets are subscribed (joined) on every refresh.
+// updates missed while disconnected are covered by a catch-up scrape of every
+// subscribed target, queued after (re)connects and every catch-up interval.
+type LiveIngester struct {
+	ingester MessageIngester
+	targets  TargetLister
+	tg       LiveClient
+	manager  *ScrapeManager
+	every    time.Duration
+	catchUp  time.Duration
+	log      *logger.Logger
+
+	mu       sync.Mutex
+	channels map[int64]repository.ScrapingTarget // subscribed channel id -> target
+	byTarget map[uuid.UUID]int64                 // target id -> subscribed channel id
+	retryAt  map[uuid.UUID]time.Time             // failed subscriptions are retried after catch-up interval
+
+	wasReady    bool
+	lastCatchUp time.Time
+}
+
+// NewLiveIngester creates a live ingester that refreshes subscriptions every tick
+func NewLiveIngester(ingester MessageIngester, targets TargetLister, tg LiveClient, manager *ScrapeManager, every, catchUp time.Duration, log *logger.Logger) *LiveIngester {
+	if every <= 0 {
+		every = time.Minute
+	}
+	if catchUp <= 0 {
+		catchUp = 15 * time.Minute
+	}
+	return &LiveIngester{
+		ingester: ingester,
+		targets:  targets,
+		tg:       tg,
+		manager:  manager,
+		every:    every,
+		catchUp:  catchUp,
+		log:      log,
+		channels: make(map[int64]repository.ScrapingTarget),
+		byTarget: make(map[uuid.UUID]int64),
+		retryAt:  make(map[uuid.UUID]time.Time),
+	}
+}
+
+// OnMessage handles a new channel message.
+// it is registered as the telegram channel message callback.
+func (l *LiveIngester) OnMessage(ctx context.Context, msg telegram.Message) error {
+	l.mu.Lock()
+	target, ok := l.channels[msg.ChannelID]
+	l.mu.Unlock()
+	if !ok {
+		return nil // not a subscribed target
+	}
+
+	created, err := l.ingester.Ingest(ctx, &target, msg)
+	if err != nil {
+		return fmt.Errorf("ingest message %d: %w", msg.ID, err)
+	}
+	if created {
+		l.log.Info().
+			Str("target_id", target.ID.String()).
+			Int("msg_id", msg.ID).
+			Msg("live: job created from update")
+	}
+	return nil
+}
+
+// OnDelete closes the jobs of deleted channel messages.
+// it is registered as the telegram channel delete callback.
+func (l *LiveIngester) OnDelete(ctx context.Context, channelID int64, msgIDs []int) error {
+	l.mu.Lock()
+	target, ok := l.channels[channelID]
+	l.mu.Unlock()
+	if !ok {
+		return nil // not a subscribed target
+	}
+
+	if _, err := l.ingester.CloseDeleted(ctx, &target, msgIDs); err != nil {
+		return fmt.Errorf("close deleted messages: %w", err)
+	}
+	return nil
+}
+
+// Run refreshes subscriptions and queues catch-up scrapes until ctx is cancelled
+func (l *LiveIngester) Run(ctx context.Context) {
+	l.log.Info().Dur("catch_up", l.catchUp).Msg("live: started")
+
+	ticker := time.NewTicker(l.every)
+	defer ticker.Stop()
+
+	l.tick(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			l.log.Info().Msg("live: stopped")
+			return
+		case now := <-ticker.C:
+			l.tick(ctx, now)
+		}
+	}
+}
+
+// Subscribed returns the number of subscribed targets
+func (l *LiveIngester) Subscribed() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.channels)
+}
+
+// tick refreshes subscriptions and queues catch-up scrapes when due
+func (l *LiveIngester) tick(ctx context.Context, now time.Time) {
+	if l.tg.GetStatus() != telegram.StatusReady {
+		l.wasReady = false
+		return
+	}
+	reconnected := !l.wasReady
+	l.wasReady = true
+
+	l.refresh(ctx, now)
+
+	if reconnected || now.Sub(l.lastCatchUp) >= l.catchUp {
+		l.catchUpAll(ctx)
+		l.lastCatchUp = now
+	}
+}
+
+// refresh subscribes new active telegram targets and drops inactive ones
+func (l *LiveIngester) refresh(ctx context.Context, now time.Time) {
+	targets, err := l.targets.GetActive(ctx)
+	if err != nil {
+		l.log.Error().Err(err).Msg("live: failed to list active targets")
+		return
+	}
+
+	l.mu.Lock()
+	byTarget := make(map[uuid.UUID]int64, len(l.byTarget))
+	for id, channelID := range l.byTarget {
+		byTarget[id] = channelID
+	}
+	l.mu.Unlock()
+
+	channels := make(map[int64]repository.ScrapingTarget, len(targets))
+	subscribed := make(map[uuid.UUID]int64, len(targets))
+	for _, t := range targets {
+		if !t.IsTelegram() {
+			continue
+		}
+
+		channelID, ok := byTarget[t.ID]
+		if !ok {
+			if now.Before(l.retryAt[t.ID]) {
+				continue
+			}
+			channelID, err = l.subscribe(ctx, t)
+			if err != nil {
+				l.retryAt[t.ID] = now.Add(l.catchUp)
+				l.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("live: failed to subscribe to target")
+				continue
+			}
+			delete(l.retryAt, t.ID)
+		}
+
+		channels[channelID] = t
+		subscribed[t.ID] = channelID
+	}
+
+	l.mu.Lock()
+	l.channels = channels
+	l.byTarget = subscribed
+	l.mu.Unlock()
+}
+
+// subscribe resolves and joins the channel of a target, returns its channel id
+func (l *LiveIngester) subscribe(ctx context.Context, t repository.ScrapingTarget) (int64, error) {
+	channel, err := resolveChannel(ctx, l.tg, &t, false)
+	if err != nil {
+		return 0, fmt.Errorf("resolve channel: %w", err)
+	}
+	if err := l.tg.JoinChannel(ctx, channel); err != nil {
+		return 0, err
+	}
+
+	l.log.Info().
+		Str("target_id", t.ID.String()).
+		Int64("channel_id", channel.ID).
+		Msg("live: subscribed to target")
+	return channel.ID, nil
+}
+
+// catchUpAll queues a scrape of every subscribed target.
+// scrapes stop at already parsed messages, so only missed messages are fetched.
+func (l *LiveIngester) catchUpAll(ctx context.Context) {
+	l.mu.Lock()
+	targets := make([]repository.ScrapingTarget, 0, len(l.channels))
+	for _, t := range l.channels {
+		targets = append(targets, t)
+	}
+	l.mu.Unlock()
+
+	for _, t := range targets {
+		meta, err := models.ParseTargetMetadata(t.Metadata)
+		if err != nil {
+			l.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("live: invalid target metadata")
+			continue
+		}
+		opts, err := targetOptions(t, meta)
+		if err != nil {
+			l.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("live: invalid until date in metadata")
+		}
+
+		if _, err := l.manager.Start(ctx, opts); err != nil && err != ErrAlreadyQueued {
+			l.log.Error().Err(err).Str("target_id", t.ID.String()).Msg("live: failed to queue catch-up scrape")
+		}
+	}
+
+	if len(targets) > 0 {
+		l.log.Info().Int("targets", len(targets)).Msg("live: catch-up scrapes queued")
+	}
+}
@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// mockLiveClient resolves channels from a map of username -> channel id
+type mockLiveClient struct {
+	channels map[string]int64
+	status   telegram.Status
+	joined   []int64
+	resolves int
+}
+
+func (m *mockLiveClient) ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error) {
+	m.resolves++
+	id, ok := m.channels[username]
+	if !ok {
+		return nil, errors.New("channel not found")
+	}
+	return &telegram.Channel{ID: id, Username: username}, nil
+}
+
+func (m *mockLiveClient) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error) {
+	m.resolves++
+	return &telegram.Channel{ID: id}, nil
+}
+
+func (m *mockLiveClient) JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return m.ResolveChannel(ctx, "+"+hash)
+}
+
+func (m *mockLiveClient) CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return m.ResolveChannel(ctx, "+"+hash)
+}
+
+func (m *mockLiveClient) JoinChannel(ctx context.Context, channel *telegram.Channel) error {
+	m.joined = append(m.joined, channel.ID)
+	return nil
+}
+
+func (m *mockLiveClient) GetStatus() telegram.Status {
+	return m.status
+}
+
+// mockIngester records ingested messages and deleted message ids
+type mockIngester struct {
+	messages []telegram.Message
+	targets  []uuid.UUID
+	deleted  []int
+}
+
+func (m *mockIngester) Ingest(ctx context.Context, target *repository.ScrapingTarget, msg telegram.Message) (bool, error) {
+	m.messages = append(m.messages, msg)
+	m.targets = append(m.targets, target.ID)
+	return true, nil
+}
+
+func (m *mockIngester) CloseDeleted(ctx context.Context, target *repository.ScrapingTarget, msgIDs []int) (int, error) {
+	m.deleted = append(m.deleted, msgIDs...)
+	m.targets = append(m.targets, target.ID)
+	return len(msgIDs), nil
+}
+
+func liveTestTarget(url, targetType string) repository.ScrapingTarget {
+	return repository.ScrapingTarget{ID: uuid.New(), Name: url, Type: targetType, URL: url, IsActive: true}
+}
+
+func TestLiveIngester(t *testing.T) {
+	channel := liveTestTarget("@golang_jobs", "TG_CHANNEL")
+	forum := liveTestTarget("@go_forum", "TG_FORUM")
+	hh := liveTestTarget("https://hh.ru/search", "HH_SEARCH")
+	targets := &mockTargetLister{targets: []repository.ScrapingTarget{channel, forum, hh}}
+
+	newLive := func(tg *mockLiveClient, ingester *mockIngester) (*LiveIngester, *ScrapeManager) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		manager.SetConcurrency(2)
+		return NewLiveIngester(ingester, targets, tg, manager, time.Minute, 15*time.Minute, logger.Get()), manager
+	}
+
+	t.Run("subscribes telegram targets and queues catch-up", func(t *testing.T) {
+		tg := &mockLiveClient{status: telegram.StatusReady, channels: map[string]int64{"@golang_jobs": 100, "@go_forum": 200}}
+		live, manager := newLive(tg, &mockIngester{})
+		defer manager.Stop()
+
+		live.tick(context.Background(), time.Now())
+
+		if live.Subscribed() != 2 {
+			t.Errorf("Subscribed() = %d, want 2", live.Subscribed())
+		}
+		if len(tg.joined) != 2 {
+			t.Errorf("joined %d channels, want 2", len(tg.joined))
+		}
+		if len(manager.Running()) != 2 {
+			t.Errorf("expected catch-up scrapes for 2 targets, got %d", len(manager.Running()))
+		}
+	})
+
+	t.Run("routes messages of subscribed channels", func(t *testing.T) {
+		tg := &mockLiveClient{status: telegram.StatusReady, channels: map[string]int64{"@golang_jobs": 100, "@go_forum": 200}}
+		ingester := &mockIngester{}
+		live, manager := newLive(tg, ingester)
+		defer manager.Stop()
+		live.tick(context.Background(), time.Now())
+
+		if err := live.OnMessage(context.Background(), telegram.Message{ID: 1, ChannelID: 100, Text: "vacancy"}); err != nil {
+			t.Fatalf("OnMessage() error: %v", err)
+		}
+		if err := live.OnMessage(context.Background(), telegram.Message{ID: 2, ChannelID: 999, Text: "other"}); err != nil {
+			t.Fatalf("OnMessage() error: %v", err)
+		}
+
+		if len(ingester.messages) != 1 || ingester.targets[0] != channel.ID {
+			t.Errorf("expected only the subscribed channel message, got %+v", ingester.messages)
+		}
+	})
+
+	t.Run("closes jobs of deleted messages of subscribed channels", func(t *testing.T) {
+		tg := &mockLiveClient{status: telegram.StatusReady, channels: map[string]int64{"@golang_jobs": 100, "@go_forum": 200}}
+		ingester := &mockIngester{}
+		live, manager := newLive(tg, ingester)
+		defer manager.Stop()
+		live.tick(context.Background(), time.Now())
+
+		if err := live.OnDelete(context.Background(), 200, []int{7, 8}); err != nil {
+			t.Fatalf("OnDelete() error: %v", err)
+		}
+		if err := live.OnDelete(context.Background(), 999, []int{9}); err != nil {
+			t.Fatalf("OnDelete() error: %v", err)
+		}
+
+		if len(ingester.deleted) != 2 || ingester.targets[0] != forum.ID {
+			t.Errorf("expected only the subscribed forum deletions, got %v", ingester.deleted)
+		}
+	})
+
+	t.Run("waits for telegram and catches up after reconnect", func(t *testing.T) {
+		tg := &mockLiveClient{status: telegram.StatusUnauthorized, channels: map[string]int64{"@golang_jobs": 100, "@go_forum": 200}}
+		live, manager := newLive(tg, &mockIngester{})
+		defer manager.Stop()
+		now := time.Now()
+
+		live.tick(context.Background(), now)
+		if live.Subscribed() != 0 || tg.resolves != 0 {
+			t.Fatal("expected nothing to happen while telegram is not ready")
+		}
+
+		tg.status = telegram.StatusReady
+		live.tick(context.Background(), now)
+		for _, job := range manager.Running() {
+			_ = manager.Cancel(job.ID)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		// steady state: no catch-up before the interval
+		live.tick(context.Background(), now.Add(time.Minute))
+		if len(manager.Running()) != 0 {
+			t.Errorf("unexpected catch-up before interval, got %d jobs", len(manager.Running()))
+		}
+
+		// reconnect queues a catch-up right away
+		tg.status = telegram.StatusError
+		live.tick(context.Background(), now.Add(2*time.Minute))
+		tg.status = telegram.StatusReady
+		live.tick(context.Background(), now.Add(3*time.Minute))
+		if len(manager.Running()) != 2 {
+			t.Errorf("expected catch-up after reconnect, got %d jobs", len(manager.Running()))
+		}
+	})
+
+	t.Run("retries failed subscription after catch-up interval", func(t *testing.T) {
+		tg := &mockLiveClient{status: telegram.StatusReady, channels: map[string]int64{"@golang_jobs": 100}}
+		live, manager := newLive(tg, &mockIngester{})
+		defer manager.Stop()
+		now := time.Now()
+
+		live.tick(context.Background(), now)
+		live.tick(context.Background(), now.Add(time.Minute))
+		if tg.resolves != 2 {
+			t.Errorf("resolves = %d, want 2 (forum resolved once, channel subscribed)", tg.resolves)
+		}
+
+		tg.channels["@go_forum"] = 200
+		live.tick(context.Background(), now.Add(16*time.Minute))
+		if live.Subscribed() != 2 {
+			t.Errorf("Subscribed() = %d, want 2 after retry", live.Subscribed())
+		}
+	})
+}
@@ -2,34 +2,104 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/blockedby/positions-os/internal/telegram"
 	"github.com/google/uuid"
 )
 
 // errors
 var (
-	ErrAlreadyRunning = errors.New("a scrape job is already running")
+	ErrAlreadyQueued = errors.New("a scrape job for this target is already queued or running")
+	ErrJobNotFound   = errors.New("scrape job not found")
+	ErrJobNotQueued  = errors.New("scrape job is not queued")
 )
 
+// maxFinishedJobs is the number of finished jobs kept for listing
+const maxFinishedJobs = 50
+
+// runHistoryTimeout bounds writes to the run history
+const runHistoryTimeout = 10 * time.Second
+
 // ScrapeOptions holds options for a scrape job
 type ScrapeOptions struct {
-	TargetID uuid.UUID
-	Channel  string
-	Limit    int
-	Until    *time.Time
-	TopicIDs []int
+	TargetID uuid.UUID  `json:"target_id,omitempty"`
+	Channel  string     `json:"channel,omitempty"`
+	Limit    int        `json:"limit,omitempty"`
+	Until    *time.Time `json:"until,omitempty"`
+	TopicIDs []int      `json:"topic_ids,omitempty"`
+	Backfill bool       `json:"backfill,omitempty"` // walk history below the oldest parsed message, down to Until
+	DryRun   bool       `json:"dry_run,omitempty"`  // preview only: no jobs, ranges or target updates are written
+
+	// ResumeFrom continues the streams of an interrupted run from its checkpoints
+	ResumeFrom uuid.UUID `json:"resume_from,omitempty"`
+	// RunID is the run history id of the scrape, checkpoints are saved under it
+	RunID uuid.UUID `json:"-"`
+
+	// OnProgress receives the live counters after every batch
+	OnProgress func(ScrapeProgress) `json:"-"`
 }
 
-// ScrapeJob represents an active scrape job
+// ScrapeJobStatus is the lifecycle state of a scrape job
+type ScrapeJobStatus string
+
+const (
+	ScrapeQueued    ScrapeJobStatus = "queued"
+	ScrapeRunning   ScrapeJobStatus = "running"
+	ScrapeCompleted ScrapeJobStatus = "completed"
+	ScrapeFailed    ScrapeJobStatus = "failed"
+	ScrapeCancelled ScrapeJobStatus = "cancelled"
+)
+
+// ScrapeJob represents a queued, running or finished scrape job
 type ScrapeJob struct {
-	ID        uuid.UUID
-	TargetID  uuid.UUID
-	StartedAt time.Time
-	Options   ScrapeOptions
+	ID         uuid.UUID       `json:"id"`
+	TargetID   uuid.UUID       `json:"target_id"`
+	Status     ScrapeJobStatus `json:"status"`
+	QueuedAt   time.Time       `json:"queued_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Options    ScrapeOptions   `json:"options"`
+	Progress   *ScrapeProgress `json:"progress,omitempty"` // live counters while running
+	Result     *ScrapeResult   `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+
+	cancelFn context.CancelFunc
+}
+
+// snapshot returns a copy safe to hand out of the manager lock
+func (j *ScrapeJob) snapshot() *ScrapeJob {
+	cp := *j
+	cp.cancelFn = nil
+	return &cp
+}
+
+// sameTarget reports whether two jobs scrape the same target,
+// matched by target id or by channel username
+func (j *ScrapeJob) sameTarget(opts ScrapeOptions) bool {
+	if j.Options.TargetID != uuid.Nil && j.Options.TargetID == opts.TargetID {
+		return true
+	}
+	a, b := normalizeChannel(j.Options.Channel), normalizeChannel(opts.Channel)
+	return a != "" && a == b
+}
+
+// normalizeChannel lowercases a channel username and strips the @ prefix
+func normalizeChannel(channel string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(channel), "@"))
+}
+
+// RunRecorder persists the history of scrape runs
+type RunRecorder interface {
+	Create(ctx context.Context, run *repository.ScrapeRun) error
+	Finish(ctx context.Context, run *repository.ScrapeRun) error
 }
 
 // Scraper defines the interface for scraping logic
@@ -39,93 +109,396 @@ type Scraper interface {
 	GetTelegramStatus() telegram.Status
 }
 
-// ScrapeManager manages active scrape jobs
-// ensures only one job runs at a time
+// ScrapeManager queues scrape jobs and runs them with limited concurrency.
+// all jobs share the scraper (and so the telegram rate limiter).
+// a target can only be queued or running once at a time.
 // thread-safe
 type ScrapeManager struct {
-	mu       sync.Mutex
-	current  *ScrapeJob
-	cancelFn context.CancelFunc
-	scraper  Scraper
+	mu          sync.Mutex
+	scraper     Scraper
+	concurrency int
+	queue       []*ScrapeJob
+	running     map[uuid.UUID]*ScrapeJob
+	finished    []*ScrapeJob
+	runs        RunRecorder
 }
 
-// NewScrapeManager creates a new scrape manager
+// NewScrapeManager creates a new scrape manager running one job at a time
 func NewScrapeManager(scraper Scraper) *ScrapeManager {
 	return &ScrapeManager{
-		scraper: scraper,
+		scraper:     scraper,
+		concurrency: 1,
+		running:     make(map[uuid.UUID]*ScrapeJob),
 	}
 }
 
-// Start starts a new scrape job
-// returns ErrAlreadyRunning if a job is already running
+// SetConcurrency sets how many jobs may run at the same time
+func (m *ScrapeManager) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.concurrency = n
+	m.dispatch()
+}
+
+// SetRunRecorder enables persistent run history
+func (m *ScrapeManager) SetRunRecorder(runs RunRecorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = runs
+}
+
+// Start enqueues a new scrape job and starts it if a slot is free
+// returns ErrAlreadyQueued if the target is already queued or running
 func (m *ScrapeManager) Start(ctx context.Context, opts ScrapeOptions) (*ScrapeJob, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.current != nil {
-		return nil, ErrAlreadyRunning
+	for _, active := range m.active() {
+		if active.sameTarget(opts) {
+			return nil, ErrAlreadyQueued
+		}
 	}
 
-	// IMPORTANT: Use background context, NOT the HTTP request context!
-	// The HTTP request context gets canceled when the handler returns,
-	// which would immediately cancel our scrape job.
-	// We create a new cancellable context from Background() so the job
-	// continues running after the HTTP response is sent.
-	scrapeCtx, cancel := context.WithCancel(context.Background())
-	m.cancelFn = cancel
-
 	job := &ScrapeJob{
-		ID:        uuid.New(),
-		TargetID:  opts.TargetID,
-		StartedAt: time.Now(),
-		Options:   opts,
+		ID:       uuid.New(),
+		TargetID: opts.TargetID,
+		Status:   ScrapeQueued,
+		QueuedAt: time.Now(),
+		Options:  opts,
 	}
-	m.current = job
 
-	// run the actual scraping in a goroutine
-	go m.run(scrapeCtx, job)
+	m.queue = append(m.queue, job)
+	m.dispatch()
 
-	return job, nil
+	return job.snapshot(), nil
+}
+
+// Preview runs a dry-run scrape right away, outside the queue.
+// it writes nothing, so it does not wait for jobs of the same target.
+func (m *ScrapeManager) Preview(ctx context.Context, opts ScrapeOptions) (*ScrapeResult, error) {
+	if m.scraper == nil {
+		return nil, errors.New("scraper is not configured")
+	}
+	opts.DryRun = true
+	return m.scraper.Scrape(ctx, opts)
 }
 
-// Stop stops the current scrape job
+// Cancel cancels a queued or running job
+// returns ErrJobNotFound if the job is not queued or running
+func (m *ScrapeManager) Cancel(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.running[id]; ok {
+		// run() records the final status once the scraper returns
+		job.cancelFn()
+		return nil
+	}
+
+	for i, job := range m.queue {
+		if job.ID == id {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			now := time.Now()
+			job.Status = ScrapeCancelled
+			job.FinishedAt = &now
+			m.addFinished(job)
+			return nil
+		}
+	}
+
+	return ErrJobNotFound
+}
+
+// Move moves a queued job to the given position in the queue (0 = next)
+// returns ErrJobNotQueued for running jobs and ErrJobNotFound for unknown ones
+func (m *ScrapeManager) Move(id uuid.UUID, position int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.running[id]; ok {
+		return ErrJobNotQueued
+	}
+
+	idx := -1
+	for i, job := range m.queue {
+		if job.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return ErrJobNotFound
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position > len(m.queue)-1 {
+		position = len(m.queue) - 1
+	}
+
+	job := m.queue[idx]
+	m.queue = append(m.queue[:idx], m.queue[idx+1:]...)
+	m.queue = append(m.queue[:position], append([]*ScrapeJob{job}, m.queue[position:]...)...)
+
+	return nil
+}
+
+// Stop cancels all queued and running jobs
 // safe to call when no job is running
 func (m *ScrapeManager) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.cancelFn != nil {
-		m.cancelFn()
-		m.cancelFn = nil
+	now := time.Now()
+	for _, job := range m.queue {
+		job.Status = ScrapeCancelled
+		job.FinishedAt = &now
+		m.addFinished(job)
+	}
+	m.queue = nil
+
+	for _, job := range m.running {
+		job.cancelFn()
 	}
-	m.current = nil
 }
 
-// Current returns the currently running job
-// returns nil if no job is running
-func (m *ScrapeManager) Current() *ScrapeJob {
+// CancelRunning cancels the running jobs and returns how many there were.
+// queued jobs stay in the queue and take the freed slots.
+func (m *ScrapeManager) CancelRunning() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.current
+
+	for _, job := range m.running {
+		// run() records the final status once the scraper returns
+		job.cancelFn()
+	}
+	return len(m.running)
+}
+
+// Get returns a job by id, including recently finished ones
+func (m *ScrapeManager) Get(id uuid.UUID) (*ScrapeJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, job := range m.all() {
+		if job.ID == id {
+			return job.snapshot(), nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// List returns running jobs, then queued jobs in order, then recently finished ones
+func (m *ScrapeManager) List() []*ScrapeJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	all := m.all()
+	jobs := make([]*ScrapeJob, 0, len(all))
+	for _, job := range all {
+		jobs = append(jobs, job.snapshot())
+	}
+	return jobs
+}
+
+// Running returns currently running jobs ordered by start time
+func (m *ScrapeManager) Running() []*ScrapeJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*ScrapeJob, 0, len(m.running))
+	for _, job := range m.runningSorted() {
+		jobs = append(jobs, job.snapshot())
+	}
+	return jobs
+}
+
+// Queued returns the number of jobs waiting for a slot
+func (m *ScrapeManager) Queued() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queue)
+}
+
+// dispatch starts queued jobs while there are free slots.
+// must be called with mu held.
+func (m *ScrapeManager) dispatch() {
+	for len(m.running) < m.concurrency && len(m.queue) > 0 {
+		job := m.queue[0]
+		m.queue = m.queue[1:]
+
+		// IMPORTANT: Use background context, NOT the HTTP request context!
+		// The HTTP request context gets canceled when the handler returns,
+		// which would immediately cancel our scrape job.
+		// We create a new cancellable context from Background() so the job
+		// continues running after the HTTP response is sent.
+		scrapeCtx, cancel := context.WithCancel(context.Background())
+		now := time.Now()
+		job.cancelFn = cancel
+		job.Status = ScrapeRunning
+		job.StartedAt = &now
+		job.Options.OnProgress = m.progressFunc(job)
+		m.running[job.ID] = job
+
+		// run the actual scraping in a goroutine
+		go m.run(scrapeCtx, job, m.runs)
+	}
+}
+
+// progressFunc returns the progress callback of a job, keeping its live counters
+func (m *ScrapeManager) progressFunc(job *ScrapeJob) func(ScrapeProgress) {
+	return func(p ScrapeProgress) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		job.Progress = &p
+	}
 }
 
 // run executes the scrape job
 // this is called in a goroutine
-func (m *ScrapeManager) run(ctx context.Context, job *ScrapeJob) {
-	defer func() {
-		m.mu.Lock()
-		if m.current != nil && m.current.ID == job.ID {
-			m.current = nil
-			m.cancelFn = nil
-		}
-		m.mu.Unlock()
-	}()
+func (m *ScrapeManager) run(ctx context.Context, job *ScrapeJob, runs RunRecorder) {
+	var (
+		result *ScrapeResult
+		err    error
+	)
+
+	run := startRun(runs, job)
 
 	// execute scraping
 	if m.scraper != nil {
-		_, _ = m.scraper.Scrape(ctx, job.Options)
+		opts := job.Options
+		if run != nil {
+			opts.RunID = run.ID
+		}
+		result, err = m.scraper.Scrape(ctx, opts)
 		// errors are logged inside Scrape method usually
 	}
+
+	status := ScrapeCompleted
+	var errText string
+	switch {
+	case ctx.Err() != nil:
+		status = ScrapeCancelled
+	case err != nil:
+		status = ScrapeFailed
+		errText = err.Error()
+	}
+
+	finishRun(runs, run, status, result, errText)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Result = result
+	job.Status = status
+	job.Error = errText
+	job.cancelFn()
+
+	delete(m.running, job.ID)
+	m.addFinished(job)
+	m.dispatch()
+}
+
+// startRun records the start of a job in the run history.
+// returns nil if history is disabled or the insert failed.
+func startRun(runs RunRecorder, job *ScrapeJob) *repository.ScrapeRun {
+	if runs == nil {
+		return nil
+	}
+
+	opts, _ := json.Marshal(job.Options)
+	run := &repository.ScrapeRun{
+		ID:      job.ID,
+		Channel: job.Options.Channel,
+		Options: opts,
+	}
+	if job.TargetID != uuid.Nil {
+		tid := job.TargetID
+		run.TargetID = &tid
+	}
+
+	// the job context may be cancelled at any time, history is written regardless
+	ctx, cancel := context.WithTimeout(context.Background(), runHistoryTimeout)
+	defer cancel()
+
+	if err := runs.Create(ctx, run); err != nil {
+		logger.Get().Warn().Err(err).Str("job_id", job.ID.String()).Msg("scrape: failed to record run start")
+		return nil
+	}
+	return run
+}
+
+// finishRun records the outcome and statistics of a job in the run history
+func finishRun(runs RunRecorder, run *repository.ScrapeRun, status ScrapeJobStatus, result *ScrapeResult, errText string) {
+	if runs == nil || run == nil {
+		return
+	}
+
+	run.Status = string(status)
+	if errText != "" {
+		run.Error = &errText
+	}
+	if result != nil {
+		run.TotalFetched = result.TotalFetched
+		run.NewJobs = result.NewJobs
+		run.SkippedOld = result.SkippedOld
+		run.SkippedEmpty = result.SkippedEmpty
+		run.SkippedFiltered = result.SkippedFiltered
+		run.EditedJobs = result.EditedJobs
+		run.Errors = result.Errors
+		if result.TargetID != uuid.Nil {
+			tid := result.TargetID
+			run.TargetID = &tid
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runHistoryTimeout)
+	defer cancel()
+
+	if err := runs.Finish(ctx, run); err != nil {
+		logger.Get().Warn().Err(err).Str("job_id", run.ID.String()).Msg("scrape: failed to record run result")
+	}
+}
+
+// addFinished records a finished job, keeping the most recent ones.
+// must be called with mu held.
+func (m *ScrapeManager) addFinished(job *ScrapeJob) {
+	m.finished = append([]*ScrapeJob{job}, m.finished...)
+	if len(m.finished) > maxFinishedJobs {
+		m.finished = m.finished[:maxFinishedJobs]
+	}
+}
+
+// active returns running and queued jobs.
+// must be called with mu held.
+func (m *ScrapeManager) active() []*ScrapeJob {
+	return append(m.runningSorted(), m.queue...)
+}
+
+// all returns active and finished jobs.
+// must be called with mu held.
+func (m *ScrapeManager) all() []*ScrapeJob {
+	return append(m.active(), m.finished...)
+}
+
+// runningSorted returns running jobs ordered by start time.
+// must be called with mu held.
+func (m *ScrapeManager) runningSorted() []*ScrapeJob {
+	jobs := make([]*ScrapeJob, 0, len(m.running))
+	for _, job := range m.running {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.Before(*jobs[j].StartedAt)
+	})
+	return jobs
 }
 
 // ListTopics delegates to scraper
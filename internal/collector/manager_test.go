@@ -2,10 +2,12 @@ package collector
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/blockedby/positions-os/internal/telegram"
 	"github.com/google/uuid"
 )
@@ -16,20 +18,64 @@ type MockScraper struct {
 	Opts           ScrapeOptions
 	Delay          time.Duration
 	TopicsToReturn []telegram.Topic
+	Result         *ScrapeResult   // returned by Scrape, empty result if nil
+	Err            error           // returned by Scrape
+	Progress       *ScrapeProgress // reported to opts.OnProgress before the delay
+
+	mu sync.Mutex // jobs may run concurrently
 }
 
 func (m *MockScraper) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult, error) {
+	m.mu.Lock()
 	m.Called = true
 	m.Opts = opts
+	m.mu.Unlock()
+	if m.Progress != nil && opts.OnProgress != nil {
+		opts.OnProgress(*m.Progress)
+	}
 	if m.Delay > 0 {
 		select {
 		case <-time.After(m.Delay):
 		case <-ctx.Done():
 		}
 	}
+	if m.Err != nil {
+		return m.Result, m.Err
+	}
+	if m.Result != nil {
+		return m.Result, nil
+	}
 	return &ScrapeResult{}, nil
 }
 
+// mockRunRecorder keeps recorded runs in memory
+type mockRunRecorder struct {
+	mu       sync.Mutex
+	created  []repository.ScrapeRun
+	finished []repository.ScrapeRun
+}
+
+func (m *mockRunRecorder) Create(ctx context.Context, run *repository.ScrapeRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.StartedAt = time.Now()
+	m.created = append(m.created, *run)
+	return nil
+}
+
+func (m *mockRunRecorder) Finish(ctx context.Context, run *repository.ScrapeRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finished = append(m.finished, *run)
+	return nil
+}
+
+func (m *mockRunRecorder) finishedRuns() []repository.ScrapeRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]repository.ScrapeRun(nil), m.finished...)
+}
+
 func (m *MockScraper) ListTopics(ctx context.Context, channelURL string) ([]telegram.Topic, error) {
 	return m.TopicsToReturn, nil
 }
@@ -76,34 +122,275 @@ func TestScrapeManager_Start(t *testing.T) {
 		manager.Stop()
 	})
 
-	t.Run("returns error when already running", func(t *testing.T) {
-		manager := NewScrapeManager(&MockScraper{})
+	t.Run("queues job for another target", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
 
 		// start first job
-		_, err := manager.Start(context.Background(), ScrapeOptions{
+		first, err := manager.Start(context.Background(), ScrapeOptions{
 			Channel: "first",
 		})
 		if err != nil {
 			t.Fatalf("first Start() unexpected error: %v", err)
 		}
+		if first.Status != ScrapeRunning {
+			t.Errorf("first job status = %s, want running", first.Status)
+		}
 
-		// try to start second
-		_, err = manager.Start(context.Background(), ScrapeOptions{
+		// second target waits for a free slot
+		second, err := manager.Start(context.Background(), ScrapeOptions{
 			Channel: "second",
 		})
-		if err != ErrAlreadyRunning {
-			t.Errorf("second Start() error = %v, want ErrAlreadyRunning", err)
+		if err != nil {
+			t.Fatalf("second Start() unexpected error: %v", err)
+		}
+		if second.Status != ScrapeQueued {
+			t.Errorf("second job status = %s, want queued", second.Status)
 		}
+		if manager.Queued() != 1 {
+			t.Errorf("Queued() = %d, want 1", manager.Queued())
+		}
+	})
 
-		// cleanup
-		manager.Stop()
+	t.Run("returns error when target already queued", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+
+		_, err := manager.Start(context.Background(), ScrapeOptions{
+			Channel: "@first",
+		})
+		if err != nil {
+			t.Fatalf("first Start() unexpected error: %v", err)
+		}
+
+		_, err = manager.Start(context.Background(), ScrapeOptions{
+			Channel: "First",
+		})
+		if err != ErrAlreadyQueued {
+			t.Errorf("second Start() error = %v, want ErrAlreadyQueued", err)
+		}
+	})
+}
+
+// test queue concurrency
+func TestScrapeManager_Concurrency(t *testing.T) {
+	t.Run("runs up to concurrency jobs", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		manager.SetConcurrency(2)
+		defer manager.Stop()
+
+		for _, ch := range []string{"a", "b", "c"} {
+			if _, err := manager.Start(context.Background(), ScrapeOptions{Channel: ch}); err != nil {
+				t.Fatalf("Start(%s) error: %v", ch, err)
+			}
+		}
+
+		if n := len(manager.Running()); n != 2 {
+			t.Errorf("Running() = %d jobs, want 2", n)
+		}
+		if manager.Queued() != 1 {
+			t.Errorf("Queued() = %d, want 1", manager.Queued())
+		}
+	})
+
+	t.Run("starts next queued job when one finishes", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: 20 * time.Millisecond})
+		defer manager.Stop()
+
+		first, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "a"})
+		second, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "b"})
+
+		time.Sleep(30 * time.Millisecond)
+
+		running := manager.Running()
+		if len(running) != 1 || running[0].ID != second.ID {
+			t.Fatalf("expected second job to be running, got %+v", running)
+		}
+
+		job, err := manager.Get(first.ID)
+		if err != nil {
+			t.Fatalf("Get() error: %v", err)
+		}
+		if job.Status != ScrapeCompleted {
+			t.Errorf("first job status = %s, want completed", job.Status)
+		}
+		if job.FinishedAt == nil {
+			t.Error("first job should have finished_at")
+		}
+	})
+}
+
+// test per-job cancellation
+func TestScrapeManager_Cancel(t *testing.T) {
+	t.Run("cancels queued job", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+
+		_, _ = manager.Start(context.Background(), ScrapeOptions{Channel: "a"})
+		queued, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "b"})
+
+		if err := manager.Cancel(queued.ID); err != nil {
+			t.Fatalf("Cancel() error: %v", err)
+		}
+		if manager.Queued() != 0 {
+			t.Errorf("Queued() = %d, want 0", manager.Queued())
+		}
+
+		job, _ := manager.Get(queued.ID)
+		if job == nil || job.Status != ScrapeCancelled {
+			t.Errorf("cancelled job = %+v, want status cancelled", job)
+		}
+	})
+
+	t.Run("cancels running job and starts next", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+
+		running, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "a"})
+		next, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "b"})
+
+		if err := manager.Cancel(running.ID); err != nil {
+			t.Fatalf("Cancel() error: %v", err)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		job, _ := manager.Get(running.ID)
+		if job == nil || job.Status != ScrapeCancelled {
+			t.Errorf("cancelled job = %+v, want status cancelled", job)
+		}
+		current := manager.Running()
+		if len(current) != 1 || current[0].ID != next.ID {
+			t.Errorf("expected next job to be running, got %+v", current)
+		}
+	})
+
+	t.Run("returns ErrJobNotFound for unknown job", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{})
+
+		if err := manager.Cancel(uuid.New()); err != ErrJobNotFound {
+			t.Errorf("Cancel() error = %v, want ErrJobNotFound", err)
+		}
+	})
+}
+
+// test queue reordering
+func TestScrapeManager_Move(t *testing.T) {
+	manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+	defer manager.Stop()
+
+	running, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "a"})
+	b, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "b"})
+	c, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "c"})
+
+	if err := manager.Move(c.ID, 0); err != nil {
+		t.Fatalf("Move() error: %v", err)
+	}
+
+	var queued []uuid.UUID
+	for _, job := range manager.List() {
+		if job.Status == ScrapeQueued {
+			queued = append(queued, job.ID)
+		}
+	}
+	if len(queued) != 2 || queued[0] != c.ID || queued[1] != b.ID {
+		t.Errorf("queue order = %v, want [%s %s]", queued, c.ID, b.ID)
+	}
+
+	if err := manager.Move(running.ID, 0); err != ErrJobNotQueued {
+		t.Errorf("Move(running) error = %v, want ErrJobNotQueued", err)
+	}
+	if err := manager.Move(uuid.New(), 0); err != ErrJobNotFound {
+		t.Errorf("Move(unknown) error = %v, want ErrJobNotFound", err)
+	}
+}
+
+// test run history
+func TestScrapeManager_RunHistory(t *testing.T) {
+	t.Run("records completed run with statistics", func(t *testing.T) {
+		targetID := uuid.New()
+		scraper := &MockScraper{Result: &ScrapeResult{
+			TargetID:     targetID,
+			TotalFetched: 20,
+			NewJobs:      3,
+			SkippedOld:   15,
+			SkippedEmpty: 2,
+		}}
+		runs := &mockRunRecorder{}
+		manager := NewScrapeManager(scraper)
+		manager.SetRunRecorder(runs)
+
+		job, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "@test", Limit: 20})
+		time.Sleep(20 * time.Millisecond)
+
+		finished := runs.finishedRuns()
+		if len(runs.created) != 1 || len(finished) != 1 {
+			t.Fatalf("expected 1 created and 1 finished run, got %d/%d", len(runs.created), len(finished))
+		}
+		run := finished[0]
+		if run.ID != job.ID {
+			t.Errorf("run id = %v, want job id %v", run.ID, job.ID)
+		}
+		if run.Status != "completed" {
+			t.Errorf("run status = %s, want completed", run.Status)
+		}
+		if run.TotalFetched != 20 || run.NewJobs != 3 || run.SkippedOld != 15 || run.SkippedEmpty != 2 {
+			t.Errorf("unexpected counters: %+v", run)
+		}
+		if run.TargetID == nil || *run.TargetID != targetID {
+			t.Errorf("run target = %v, want %v", run.TargetID, targetID)
+		}
+		if run.Channel != "@test" || len(run.Options) == 0 {
+			t.Errorf("run should keep channel and options, got %q %s", run.Channel, run.Options)
+		}
+		scraper.mu.Lock()
+		runID := scraper.Opts.RunID
+		scraper.mu.Unlock()
+		if runID != job.ID {
+			t.Errorf("scrape RunID = %v, want run id %v for checkpoints", runID, job.ID)
+		}
+	})
+
+	t.Run("records failed run with error text", func(t *testing.T) {
+		runs := &mockRunRecorder{}
+		manager := NewScrapeManager(&MockScraper{Err: errors.New("resolve channel: not found")})
+		manager.SetRunRecorder(runs)
+
+		_, _ = manager.Start(context.Background(), ScrapeOptions{Channel: "@missing"})
+		time.Sleep(20 * time.Millisecond)
+
+		finished := runs.finishedRuns()
+		if len(finished) != 1 {
+			t.Fatalf("expected 1 finished run, got %d", len(finished))
+		}
+		if finished[0].Status != "failed" {
+			t.Errorf("run status = %s, want failed", finished[0].Status)
+		}
+		if finished[0].Error == nil || *finished[0].Error != "resolve channel: not found" {
+			t.Errorf("run error = %v, want error text", finished[0].Error)
+		}
+	})
+
+	t.Run("records cancelled run", func(t *testing.T) {
+		runs := &mockRunRecorder{}
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		manager.SetRunRecorder(runs)
+
+		job, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "@test"})
+		_ = manager.Cancel(job.ID)
+		time.Sleep(20 * time.Millisecond)
+
+		finished := runs.finishedRuns()
+		if len(finished) != 1 || finished[0].Status != "cancelled" {
+			t.Errorf("expected cancelled run, got %+v", finished)
+		}
 	})
 }
 
 // test manager stop
 func TestScrapeManager_Stop(t *testing.T) {
-	t.Run("stops running job", func(t *testing.T) {
-		manager := NewScrapeManager(&MockScraper{})
+	t.Run("stops running and queued jobs", func(t *testing.T) {
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
 
 		_, err := manager.Start(context.Background(), ScrapeOptions{
 			Channel: "test",
@@ -111,10 +398,13 @@ func TestScrapeManager_Stop(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Start() error: %v", err)
 		}
+		_, _ = manager.Start(context.Background(), ScrapeOptions{
+			Channel: "queued",
+		})
 
 		// verify job is running
-		if manager.Current() == nil {
-			t.Fatal("Current() should return job before stop")
+		if len(manager.Running()) == 0 {
+			t.Fatal("Running() should return job before stop")
 		}
 
 		// stop
@@ -123,9 +413,12 @@ func TestScrapeManager_Stop(t *testing.T) {
 		// give a bit of time for cleanup
 		time.Sleep(10 * time.Millisecond)
 
-		// verify job is stopped
-		if manager.Current() != nil {
-			t.Error("Current() should return nil after stop")
+		// verify jobs are stopped
+		if len(manager.Running()) != 0 {
+			t.Error("Running() should be empty after stop")
+		}
+		if manager.Queued() != 0 {
+			t.Error("Queued() should be 0 after stop")
 		}
 	})
 
@@ -139,29 +432,56 @@ func TestScrapeManager_Stop(t *testing.T) {
 	})
 }
 
-// test manager current
-func TestScrapeManager_Current(t *testing.T) {
-	t.Run("returns nil when not running", func(t *testing.T) {
+// test cancelling running jobs only
+func TestScrapeManager_CancelRunning(t *testing.T) {
+	manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+	defer manager.Stop()
+
+	first, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "first"})
+	second, _ := manager.Start(context.Background(), ScrapeOptions{Channel: "second"})
+
+	if n := manager.CancelRunning(); n != 1 {
+		t.Errorf("CancelRunning() = %d, want 1", n)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if job, _ := manager.Get(first.ID); job.Status != ScrapeCancelled {
+		t.Errorf("running job status = %s, want cancelled", job.Status)
+	}
+	if job, _ := manager.Get(second.ID); job.Status != ScrapeRunning {
+		t.Errorf("queued job status = %s, want it started next", job.Status)
+	}
+
+	// nothing running is a no-op
+	idle := NewScrapeManager(&MockScraper{})
+	if n := idle.CancelRunning(); n != 0 {
+		t.Errorf("idle CancelRunning() = %d, want 0", n)
+	}
+}
+
+// test manager running
+func TestScrapeManager_Running(t *testing.T) {
+	t.Run("returns empty when not running", func(t *testing.T) {
 		manager := NewScrapeManager(&MockScraper{})
 
-		if manager.Current() != nil {
-			t.Error("Current() should return nil when not running")
+		if len(manager.Running()) != 0 {
+			t.Error("Running() should be empty when not running")
 		}
 	})
 
 	t.Run("returns job when running", func(t *testing.T) {
-		manager := NewScrapeManager(&MockScraper{})
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
 
 		job, _ := manager.Start(context.Background(), ScrapeOptions{
 			Channel: "test",
 		})
 
-		current := manager.Current()
-		if current == nil {
-			t.Fatal("Current() should return job when running")
+		running := manager.Running()
+		if len(running) != 1 {
+			t.Fatal("Running() should return job when running")
 		}
-		if current.ID != job.ID {
-			t.Error("Current() should return the same job")
+		if running[0].ID != job.ID {
+			t.Error("Running() should return the same job")
 		}
 
 		manager.Stop()
@@ -178,7 +498,7 @@ func TestScrapeManager_ConcurrentAccess(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			manager.Start(context.Background(), ScrapeOptions{})
-			manager.Current()
+			manager.List()
 			manager.Stop()
 		}()
 	}
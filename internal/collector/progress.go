@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/web"
+	"github.com/google/uuid"
+)
+
+// Broadcaster sends events to the websocket clients (web.Hub)
+type Broadcaster interface {
+	Broadcast(message interface{})
+}
+
+// ScrapeProgress holds the live counters of a running scrape,
+// updated after every batch
+type ScrapeProgress struct {
+	Batch           int   `json:"batch"`              // batch number within the stream
+	TopicID         int64 `json:"topic_id,omitempty"` // stream being walked (forum topic)
+	Offset          int64 `json:"offset"`             // cursor of the last batch (0 = newest)
+	Fetched         int   `json:"fetched"`
+	NewJobs         int   `json:"new_jobs"`
+	SkippedOld      int   `json:"skipped_old"`
+	SkippedEmpty    int   `json:"skipped_empty"`
+	SkippedFiltered int   `json:"skipped_filtered"`
+	EditedJobs      int   `json:"edited_jobs"`
+	Errors          int   `json:"errors"`
+}
+
+// ScrapeEvent is a scrape lifecycle event sent over the web hub:
+// scrape.started, scrape.progress, then scrape.completed, scrape.failed or
+// scrape.cancelled with the result
+type ScrapeEvent struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	TargetID  uuid.UUID `json:"target_id"`
+	Target    string    `json:"target"`          // channel or url of the target
+	Limit     int       `json:"limit,omitempty"` // scrape.started
+
+	*ScrapeProgress               // scrape.progress
+	Result          *ScrapeResult `json:"result,omitempty"`
+	Error           string        `json:"error,omitempty"` // scrape.failed
+}
+
+// SetHub enables scrape events over the websocket hub
+func (s *Service) SetHub(hub Broadcaster) {
+	s.hub = hub
+}
+
+// notifyStart broadcasts scrape.started for a resolved target
+func (s *Service) notifyStart(opts ScrapeOptions, target *repository.ScrapingTarget) {
+	event := newScrapeEvent(web.EventScrapeStart, opts, target)
+	event.Limit = opts.Limit
+	s.broadcast(opts, event)
+}
+
+// reportProgress passes the counters after a batch to opts.OnProgress and
+// broadcasts scrape.progress
+func (s *Service) reportProgress(opts ScrapeOptions, target *repository.ScrapingTarget, progress ScrapeProgress) {
+	if opts.OnProgress != nil {
+		opts.OnProgress(progress)
+	}
+	event := newScrapeEvent(web.EventScrapeProgress, opts, target)
+	event.ScrapeProgress = &progress
+	s.broadcast(opts, event)
+}
+
+// notifyEnd broadcasts the outcome of a scrape. target is nil when the
+// scrape failed before its target was resolved.
+func (s *Service) notifyEnd(ctx context.Context, opts ScrapeOptions, target *repository.ScrapingTarget, result *ScrapeResult, err error) {
+	var event ScrapeEvent
+	switch {
+	case err != nil:
+		event = newScrapeEvent(web.EventScrapeFailed, opts, target)
+		event.Error = err.Error()
+	case ctx.Err() != nil:
+		event = newScrapeEvent(web.EventScrapeCancelled, opts, target)
+	default:
+		event = newScrapeEvent(web.EventScrapeEnd, opts, target)
+	}
+	event.Result = result
+	s.broadcast(opts, event)
+}
+
+// broadcast sends an event to the hub. dry runs are answered directly
+// and not broadcast.
+func (s *Service) broadcast(opts ScrapeOptions, event ScrapeEvent) {
+	if s.hub == nil || opts.DryRun {
+		return
+	}
+	s.hub.Broadcast(event)
+}
+
+// newScrapeEvent creates an event of the target, or of the requested
+// channel when the target is not resolved
+func newScrapeEvent(typ string, opts ScrapeOptions, target *repository.ScrapingTarget) ScrapeEvent {
+	event := ScrapeEvent{
+		Type:      typ,
+		Timestamp: time.Now(),
+		TargetID:  opts.TargetID,
+		Target:    opts.Channel,
+	}
+	if target != nil {
+		event.TargetID = target.ID
+		event.Target = target.URL
+	}
+	return event
+}
+
+// progress returns the counters of a scrape after a batch
+func (r *ScrapeResult) progress(batch int, topicID, offset int64) ScrapeProgress {
+	return ScrapeProgress{
+		Batch:           batch,
+		TopicID:         topicID,
+		Offset:          offset,
+		Fetched:         r.TotalFetched,
+		NewJobs:         r.NewJobs,
+		SkippedOld:      r.SkippedOld,
+		SkippedEmpty:    r.SkippedEmpty,
+		SkippedFiltered: r.SkippedFiltered,
+		EditedJobs:      r.EditedJobs,
+		Errors:          r.Errors,
+	}
+}
@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/web"
+	"github.com/google/uuid"
+)
+
+// recordingHub keeps broadcast messages as json objects
+type recordingHub struct {
+	events []map[string]interface{}
+}
+
+func (h *recordingHub) Broadcast(message interface{}) {
+	b, _ := json.Marshal(message)
+	var event map[string]interface{}
+	_ = json.Unmarshal(b, &event)
+	h.events = append(h.events, event)
+}
+
+// test the scrape events sent to the hub
+func TestService_ScrapeEvents(t *testing.T) {
+	hub := &recordingHub{}
+	svc := newTestService(&MockTelegramClient{})
+	svc.SetHub(hub)
+
+	target := &repository.ScrapingTarget{ID: uuid.New(), URL: "golang_jobs"}
+	opts := ScrapeOptions{Channel: "golang_jobs", Limit: 100}
+
+	var reported []ScrapeProgress
+	opts.OnProgress = func(p ScrapeProgress) { reported = append(reported, p) }
+
+	result := &ScrapeResult{TargetID: target.ID, TotalFetched: 100, NewJobs: 7, SkippedOld: 90, SkippedFiltered: 3}
+	svc.notifyStart(opts, target)
+	svc.reportProgress(opts, target, result.progress(1, 0, 0))
+	svc.notifyEnd(context.Background(), opts, target, result, nil)
+
+	if len(hub.events) != 3 {
+		t.Fatalf("got %d events, want 3", len(hub.events))
+	}
+	if hub.events[0]["type"] != web.EventScrapeStart || hub.events[0]["target"] != "golang_jobs" || hub.events[0]["limit"] != 100.0 {
+		t.Errorf("start event = %v", hub.events[0])
+	}
+
+	// progress counters are flat in the event
+	progress := hub.events[1]
+	if progress["type"] != web.EventScrapeProgress || progress["batch"] != 1.0 || progress["fetched"] != 100.0 ||
+		progress["new_jobs"] != 7.0 || progress["skipped_old"] != 90.0 || progress["skipped_filtered"] != 3.0 {
+		t.Errorf("progress event = %v", progress)
+	}
+	if _, ok := progress["offset"]; !ok {
+		t.Errorf("progress event has no offset: %v", progress)
+	}
+	if len(reported) != 1 || reported[0].NewJobs != 7 {
+		t.Errorf("OnProgress got %+v", reported)
+	}
+
+	end := hub.events[2]
+	res, _ := end["result"].(map[string]interface{})
+	if end["type"] != web.EventScrapeEnd || res == nil || res["new_jobs"] != 7.0 || end["target_id"] != target.ID.String() {
+		t.Errorf("end event = %v", end)
+	}
+	if _, ok := end["batch"]; ok {
+		t.Errorf("end event has progress counters: %v", end)
+	}
+
+	t.Run("failure before the target is resolved", func(t *testing.T) {
+		hub.events = nil
+		svc.notifyEnd(context.Background(), opts, nil, nil, errors.New("get target: boom"))
+
+		if len(hub.events) != 1 || hub.events[0]["type"] != web.EventScrapeFailed ||
+			hub.events[0]["error"] != "get target: boom" || hub.events[0]["target"] != "golang_jobs" {
+			t.Errorf("events = %v", hub.events)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		hub.events = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		svc.notifyEnd(ctx, opts, target, result, nil)
+
+		if len(hub.events) != 1 || hub.events[0]["type"] != web.EventScrapeCancelled {
+			t.Errorf("events = %v", hub.events)
+		}
+	})
+
+	t.Run("dry runs are not broadcast", func(t *testing.T) {
+		hub.events = nil
+		dry := ScrapeOptions{Channel: "golang_jobs", DryRun: true}
+		svc.notifyStart(dry, target)
+		svc.notifyEnd(context.Background(), dry, target, result, nil)
+
+		if len(hub.events) != 0 {
+			t.Errorf("events = %v", hub.events)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+)
+
+// ChannelResolver resolves telegram channels by username, by id and access
+// hash, or by invite link, joined or only checked (telegram.Client)
+type ChannelResolver interface {
+	ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error)
+	ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error)
+	JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error)
+	CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error)
+}
+
+// ResolveTarget checks a telegram target against telegram before it is
+// stored: its url must name a channel or group the session can read
+// (telegram.ErrChannelNotFound, telegram.ErrChannelPrivate otherwise).
+// the url is a username, an invite link (joined if the session is not a
+// member yet) or the id of a channel the session joined.
+// the type is detected (channel, group or forum), the url normalized to
+// @username (t.me/c/<id> for private channels), the channel id and access
+// hash stored and an empty name set to the channel title.
+// telegram.ErrNotReady without a telegram session.
+func (s *Service) ResolveTarget(ctx context.Context, target *repository.ScrapingTarget) error {
+	return s.resolveTarget(ctx, target, false)
+}
+
+// resolveTarget resolves a target like ResolveTarget; a dry run only checks
+// an invite link instead of joining it
+func (s *Service) resolveTarget(ctx context.Context, target *repository.ScrapingTarget, dryRun bool) error {
+	if target.Username() == "" && target.InviteHash() == "" && target.URLChannelID() == 0 {
+		return fmt.Errorf("%w: %q is not a username, invite link or channel id", telegram.ErrChannelNotFound, target.URL)
+	}
+	if s.tgClient == nil || s.tgClient.GetStatus() != telegram.StatusReady {
+		return telegram.ErrNotReady
+	}
+
+	channel, err := resolveChannel(ctx, s.tgClient, target, dryRun)
+	if err != nil {
+		return err
+	}
+	applyChannel(target, channel)
+
+	s.log.Info().
+		Str("channel", target.URL).
+		Str("type", target.Type).
+		Int64("channel_id", channel.ID).
+		Msg("target resolved")
+	return nil
+}
+
+// resolveChannel resolves the channel of a telegram target: by the channel
+// id and access hash stored when it was first resolved, otherwise by the
+// invite link, channel id or username of its url. a stored channel the
+// session can not read (another account) falls back to the url.
+// a dry run does not join invite links, it only previews the chats the
+// session is a member of or may peek into.
+func resolveChannel(ctx context.Context, tg ChannelResolver, target *repository.ScrapingTarget, dryRun bool) (*telegram.Channel, error) {
+	if target.TgChannelID != nil && *target.TgChannelID != 0 {
+		var accessHash int64
+		if target.TgAccessHash != nil {
+			accessHash = *target.TgAccessHash
+		}
+		channel, err := tg.ResolveChannelByID(ctx, *target.TgChannelID, accessHash)
+		if err == nil || !errors.Is(err, telegram.ErrChannelPrivate) && !errors.Is(err, telegram.ErrChannelNotFound) {
+			return channel, err
+		}
+	}
+
+	if hash := target.InviteHash(); hash != "" {
+		if dryRun {
+			return tg.CheckInvite(ctx, hash)
+		}
+		return tg.JoinInvite(ctx, hash)
+	}
+	if id := target.URLChannelID(); id != 0 {
+		return tg.ResolveChannelByID(ctx, id, 0)
+	}
+	return tg.ResolveChannel(ctx, target.URL)
+}
+
+// applyChannel fills a target in from its resolved channel
+func applyChannel(target *repository.ScrapingTarget, channel *telegram.Channel) {
+	target.Type = channel.TargetType()
+	if channel.Username != "" {
+		target.URL = "@" + channel.Username
+	} else {
+		target.URL = fmt.Sprintf("https://t.me/c/%d", channel.ID)
+	}
+	target.TgChannelID, target.TgAccessHash = &channel.ID, &channel.AccessHash
+	if target.Name == "" {
+		target.Name = channel.Title
+	}
+}
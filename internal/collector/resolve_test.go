@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+)
+
+// test detecting the type and normalizing the url of a new target
+func TestService_ResolveTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		channel  telegram.Channel
+		wantType string
+		wantURL  string
+	}{
+		{
+			name:     "channel by t.me link",
+			url:      "https://t.me/Golang_Jobs",
+			channel:  telegram.Channel{ID: 1, AccessHash: 11, Username: "golang_jobs", Title: "Go Jobs", IsBroadcast: true},
+			wantType: "TG_CHANNEL",
+			wantURL:  "@golang_jobs",
+		},
+		{
+			name:     "group by username",
+			url:      "go_chat",
+			channel:  telegram.Channel{ID: 2, AccessHash: 22, Username: "go_chat", Title: "Go Chat", IsMegagroup: true},
+			wantType: "TG_GROUP",
+			wantURL:  "@go_chat",
+		},
+		{
+			name:     "forum",
+			url:      "@it_jobs_forum",
+			channel:  telegram.Channel{ID: 3, AccessHash: 33, Username: "it_jobs_forum", Title: "IT Jobs", IsMegagroup: true, IsForum: true},
+			wantType: "TG_FORUM",
+			wantURL:  "@it_jobs_forum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := tt.channel
+			svc := newTestService(&MockTelegramClient{Channel: &channel})
+			target := &repository.ScrapingTarget{Type: "TG_CHANNEL", URL: tt.url}
+
+			if err := svc.ResolveTarget(context.Background(), target); err != nil {
+				t.Fatalf("ResolveTarget() error: %v", err)
+			}
+			if target.Type != tt.wantType || target.URL != tt.wantURL || target.Name != channel.Title {
+				t.Errorf("target = %s %s %q, want %s %s %q", target.Type, target.URL, target.Name, tt.wantType, tt.wantURL, channel.Title)
+			}
+			if target.TgChannelID == nil || *target.TgChannelID != channel.ID || target.TgAccessHash == nil || *target.TgAccessHash != channel.AccessHash {
+				t.Errorf("channel id and access hash not stored: %v %v", target.TgChannelID, target.TgAccessHash)
+			}
+		})
+	}
+
+	t.Run("private group by invite link", func(t *testing.T) {
+		svc := newTestService(&MockTelegramClient{Channel: &telegram.Channel{ID: 1234567890, AccessHash: 44, Title: "Go Insiders", IsMegagroup: true}})
+		target := &repository.ScrapingTarget{URL: "https://t.me/+AbCdEf123"}
+		if err := svc.ResolveTarget(context.Background(), target); err != nil {
+			t.Fatalf("ResolveTarget() error: %v", err)
+		}
+		if target.Type != "TG_GROUP" || target.URL != "https://t.me/c/1234567890" || target.Name != "Go Insiders" {
+			t.Errorf("target = %s %s %q", target.Type, target.URL, target.Name)
+		}
+		if target.TgAccessHash == nil || *target.TgAccessHash != 44 {
+			t.Errorf("access hash not stored: %v", target.TgAccessHash)
+		}
+	})
+
+	t.Run("keeps a given name", func(t *testing.T) {
+		svc := newTestService(&MockTelegramClient{Channel: &telegram.Channel{ID: 1, Username: "golang_jobs", Title: "Go Jobs"}})
+		target := &repository.ScrapingTarget{Name: "Golang vacancies", URL: "@golang_jobs"}
+		if err := svc.ResolveTarget(context.Background(), target); err != nil || target.Name != "Golang vacancies" {
+			t.Errorf("name = %q, error %v", target.Name, err)
+		}
+	})
+
+	t.Run("rejects unknown usernames", func(t *testing.T) {
+		svc := newTestService(&MockTelegramClient{})
+		err := svc.ResolveTarget(context.Background(), &repository.ScrapingTarget{URL: "@no_such_channel"})
+		if !errors.Is(err, telegram.ErrChannelNotFound) {
+			t.Errorf("ResolveTarget() error = %v, want ErrChannelNotFound", err)
+		}
+	})
+
+	t.Run("rejects urls that name no channel", func(t *testing.T) {
+		svc := newTestService(&MockTelegramClient{Channel: &telegram.Channel{ID: 1}})
+		err := svc.ResolveTarget(context.Background(), &repository.ScrapingTarget{URL: "https://example.com/jobs"})
+		if !errors.Is(err, telegram.ErrChannelNotFound) {
+			t.Errorf("ResolveTarget() error = %v, want ErrChannelNotFound", err)
+		}
+	})
+
+	t.Run("needs a telegram session", func(t *testing.T) {
+		svc := newTestService(&statusClient{status: telegram.StatusUnauthorized})
+		err := svc.ResolveTarget(context.Background(), &repository.ScrapingTarget{URL: "@golang_jobs"})
+		if !errors.Is(err, telegram.ErrNotReady) {
+			t.Errorf("ResolveTarget() error = %v, want ErrNotReady", err)
+		}
+	})
+}
+
+// refClient records how channels are resolved
+type refClient struct {
+	MockTelegramClient
+	private bool // stored ids are not readable
+	calls   []string
+}
+
+func (c *refClient) ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error) {
+	c.calls = append(c.calls, "username "+username)
+	return &telegram.Channel{ID: 1, Username: username}, nil
+}
+
+func (c *refClient) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error) {
+	c.calls = append(c.calls, fmt.Sprintf("id %d/%d", id, accessHash))
+	if c.private {
+		return nil, telegram.ErrChannelPrivate
+	}
+	return &telegram.Channel{ID: id, AccessHash: accessHash}, nil
+}
+
+func (c *refClient) JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	c.calls = append(c.calls, "invite "+hash)
+	return &telegram.Channel{ID: 2}, nil
+}
+
+func (c *refClient) CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	c.calls = append(c.calls, "check invite "+hash)
+	return &telegram.Channel{ID: 2}, nil
+}
+
+// test that stored channels are resolved by id and access hash
+func TestResolveChannel(t *testing.T) {
+	channelID, accessHash := int64(1234567890), int64(44)
+
+	tests := []struct {
+		name    string
+		target  repository.ScrapingTarget
+		private bool
+		want    []string
+	}{
+		{"username", repository.ScrapingTarget{URL: "@golang_jobs"}, false, []string{"username @golang_jobs"}},
+		{"invite link", repository.ScrapingTarget{URL: "https://t.me/+AbCdEf123"}, false, []string{"invite AbCdEf123"}},
+		{"channel id", repository.ScrapingTarget{URL: "-1001234567890"}, false, []string{"id 1234567890/0"}},
+		{
+			"stored id of an invite link",
+			repository.ScrapingTarget{URL: "https://t.me/+AbCdEf123", TgChannelID: &channelID, TgAccessHash: &accessHash},
+			false, []string{"id 1234567890/44"},
+		},
+		{
+			"stored id of a username",
+			repository.ScrapingTarget{URL: "@golang_jobs", TgChannelID: &channelID, TgAccessHash: &accessHash},
+			false, []string{"id 1234567890/44"},
+		},
+		{
+			"stored id not readable",
+			repository.ScrapingTarget{URL: "@golang_jobs", TgChannelID: &channelID, TgAccessHash: &accessHash},
+			true, []string{"id 1234567890/44", "username @golang_jobs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &refClient{private: tt.private}
+			if _, err := resolveChannel(context.Background(), client, &tt.target, false); err != nil {
+				t.Fatalf("resolveChannel() error: %v", err)
+			}
+			if fmt.Sprint(client.calls) != fmt.Sprint(tt.want) {
+				t.Errorf("calls = %v, want %v", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+// test that dry runs only check invite links, without joining them
+func TestResolveChannel_DryRunInvite(t *testing.T) {
+	target := repository.ScrapingTarget{URL: "https://t.me/+AbCdEf123"}
+
+	client := &refClient{}
+	if _, err := resolveChannel(context.Background(), client, &target, true); err != nil {
+		t.Fatalf("resolveChannel() error: %v", err)
+	}
+	if want := "[check invite AbCdEf123]"; fmt.Sprint(client.calls) != want {
+		t.Errorf("calls = %v, want %v", client.calls, want)
+	}
+
+	t.Run("a chat that is not joined yet is not previewed", func(t *testing.T) {
+		tg := &MockTelegramClient{Channel: &telegram.Channel{ID: 2}, InviteNotJoined: true}
+		src := newTestService(tg).sources["TG_CHANNEL"]
+
+		_, err := src.Resolve(context.Background(), &target, ScrapeOptions{DryRun: true})
+		if !errors.Is(err, telegram.ErrChannelPrivate) {
+			t.Errorf("Resolve() error = %v, want ErrChannelPrivate", err)
+		}
+		if tg.Joined != 0 {
+			t.Errorf("dry run joined the invite link %d times", tg.Joined)
+		}
+	})
+}
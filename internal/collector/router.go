@@ -33,10 +33,17 @@ func NewRouter(handler *Handler) http.Handler {
 		r.Post("/scrape/telegram", handler.StartScrape)
 		r.Delete("/scrape/current", handler.StopScrape)
 		r.Get("/scrape/status", handler.Status)
+		r.Get("/scrape/schedule", handler.Schedule)
+		r.Get("/scrape/jobs", handler.ListJobs)
+		r.Get("/scrape/jobs/{id}", handler.GetJob)
+		r.Delete("/scrape/jobs/{id}", handler.CancelJob)
+		r.Post("/scrape/jobs/{id}/move", handler.MoveJob)
+		r.Get("/scrape/runs", handler.ListRuns)
+		r.Get("/scrape/runs/{id}", handler.GetRun)
+		r.Post("/scrape/runs/{id}/resume", handler.ResumeRun)
 
 		// targets endpoints
 		r.Get("/targets", handler.ListTargets)
-		r.Post("/targets", handler.CreateTarget)
 
 		// tools endpoints
 		r.Get("/tools/telegram/topics", handler.ListForumTopics)
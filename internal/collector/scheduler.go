@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// TargetLister returns targets eligible for scheduled scraping
+type TargetLister interface {
+	GetActive(ctx context.Context) ([]repository.ScrapingTarget, error)
+}
+
+// FloodWaiter reports the telegram FLOOD_WAIT backoff
+type FloodWaiter interface {
+	FloodWaitUntil() time.Time
+}
+
+// ScheduleEntry describes the schedule of a single target
+type ScheduleEntry struct {
+	TargetID  uuid.UUID  `json:"target_id"`
+	Name      string     `json:"name"`
+	URL       string     `json:"url"`
+	Interval  string     `json:"interval"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// ScheduleStatus is the scheduler state exposed over the api
+type ScheduleStatus struct {
+	Enabled     bool            `json:"enabled"`
+	PausedUntil *time.Time      `json:"paused_until,omitempty"`
+	Targets     []ScheduleEntry `json:"targets"`
+}
+
+// scheduledTarget is the internal state of a scheduled target
+type scheduledTarget struct {
+	entry    ScheduleEntry
+	target   repository.ScrapingTarget
+	meta     models.TargetMetadata
+	interval time.Duration
+}
+
+// Scheduler periodically scrapes active targets, each on its own interval.
+// the interval comes from metadata.scrape_interval, targets without it are skipped.
+// scrapes are queued in the ScrapeManager, so a target is never scraped twice at once.
+type Scheduler struct {
+	manager *ScrapeManager
+	targets TargetLister
+	flood   FloodWaiter
+	every   time.Duration
+	log     *logger.Logger
+
+	mu          sync.Mutex
+	scheduled   map[uuid.UUID]*scheduledTarget
+	pausedUntil time.Time
+}
+
+// NewScheduler creates a scheduler that checks for due targets every tick
+func NewScheduler(manager *ScrapeManager, targets TargetLister, flood FloodWaiter, every time.Duration, log *logger.Logger) *Scheduler {
+	if every <= 0 {
+		every = time.Minute
+	}
+	return &Scheduler{
+		manager:   manager,
+		targets:   targets,
+		flood:     flood,
+		every:     every,
+		log:       log,
+		scheduled: make(map[uuid.UUID]*scheduledTarget),
+	}
+}
+
+// Run checks the schedule until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	s.log.Info().Dur("tick", s.every).Msg("scheduler: started")
+
+	ticker := time.NewTicker(s.every)
+	defer ticker.Stop()
+
+	s.tick(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info().Msg("scheduler: stopped")
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now)
+		}
+	}
+}
+
+// Status returns the current schedule ordered by next run time
+func (s *Scheduler) Status() ScheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := ScheduleStatus{
+		Enabled: true,
+		Targets: make([]ScheduleEntry, 0, len(s.scheduled)),
+	}
+	if time.Now().Before(s.pausedUntil) {
+		until := s.pausedUntil
+		status.PausedUntil = &until
+	}
+	for _, st := range s.scheduled {
+		status.Targets = append(status.Targets, st.entry)
+	}
+	sort.Slice(status.Targets, func(i, j int) bool {
+		return status.Targets[i].NextRunAt.Before(status.Targets[j].NextRunAt)
+	})
+	return status
+}
+
+// tick refreshes the schedule from the database and starts due scrapes
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	targets, err := s.targets.GetActive(ctx)
+	if err != nil {
+		s.log.Error().Err(err).Msg("scheduler: failed to list active targets")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh(targets, now)
+
+	// respect telegram backoff: no telegram target is started while FLOOD_WAIT
+	// is active, other sources keep their schedule
+	s.pausedUntil = time.Time{}
+	if s.flood != nil {
+		s.pausedUntil = s.flood.FloodWaitUntil()
+	}
+	flooded := now.Before(s.pausedUntil)
+	if flooded {
+		s.log.Info().Time("until", s.pausedUntil).Msg("scheduler: flood wait active, skipping telegram targets")
+	}
+
+	for _, st := range s.due(now) {
+		if flooded && st.target.IsTelegram() {
+			continue
+		}
+		_, err := s.manager.Start(ctx, s.options(st))
+		if err == ErrAlreadyQueued {
+			// target is already being scraped (e.g. started manually), count it as this run
+			s.log.Debug().Str("target_id", st.target.ID.String()).Msg("scheduler: target already queued")
+			st.entry.NextRunAt = now.Add(st.interval)
+			continue
+		}
+
+		last := now
+		st.entry.LastRunAt = &last
+		st.entry.NextRunAt = now.Add(st.interval)
+		st.entry.LastError = ""
+		if err != nil {
+			st.entry.LastError = err.Error()
+			s.log.Error().Err(err).Str("target_id", st.target.ID.String()).Msg("scheduler: failed to start scrape")
+			continue
+		}
+
+		s.log.Info().
+			Str("target_id", st.target.ID.String()).
+			Str("target", st.target.Name).
+			Time("next_run_at", st.entry.NextRunAt).
+			Msg("scheduler: scrape queued")
+	}
+}
+
+// refresh syncs scheduled targets with the active targets list.
+// must be called with mu held.
+func (s *Scheduler) refresh(targets []repository.ScrapingTarget, now time.Time) {
+	seen := make(map[uuid.UUID]bool, len(targets))
+
+	for i := range targets {
+		t := targets[i]
+		if !t.IsTelegram() && !t.IsHH() && !t.IsFeed() {
+			continue
+		}
+
+		meta, err := models.ParseTargetMetadata(t.Metadata)
+		if err != nil {
+			s.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("scheduler: invalid target metadata")
+			continue
+		}
+		interval, err := meta.Interval()
+		if err != nil {
+			s.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("scheduler: invalid scrape interval")
+			continue
+		}
+		if interval == 0 {
+			continue
+		}
+		seen[t.ID] = true
+
+		st, ok := s.scheduled[t.ID]
+		if !ok {
+			st = &scheduledTarget{}
+			s.scheduled[t.ID] = st
+		}
+
+		// recompute next run for new targets and changed intervals
+		if !ok || st.interval != interval {
+			last := st.entry.LastRunAt
+			if last == nil {
+				last = t.LastScrapedAt
+			}
+			next := now
+			if last != nil && last.Add(interval).After(now) {
+				next = last.Add(interval)
+			}
+			st.entry.NextRunAt = next
+		}
+
+		st.target = t
+		st.meta = meta
+		st.interval = interval
+		st.entry.TargetID = t.ID
+		st.entry.Name = t.Name
+		st.entry.URL = t.URL
+		st.entry.Interval = interval.String()
+	}
+
+	// drop targets that were deactivated, deleted or unscheduled
+	for id := range s.scheduled {
+		if !seen[id] {
+			delete(s.scheduled, id)
+		}
+	}
+}
+
+// due returns targets whose next run is not after now, oldest first.
+// must be called with mu held.
+func (s *Scheduler) due(now time.Time) []*scheduledTarget {
+	var due []*scheduledTarget
+	for _, st := range s.scheduled {
+		if !st.entry.NextRunAt.After(now) {
+			due = append(due, st)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].entry.NextRunAt.Before(due[j].entry.NextRunAt)
+	})
+	return due
+}
+
+// options builds scrape options from target metadata
+func (s *Scheduler) options(st *scheduledTarget) ScrapeOptions {
+	opts, err := targetOptions(st.target, st.meta)
+	if err != nil {
+		s.log.Warn().Err(err).Str("target_id", st.target.ID.String()).Msg("scheduler: invalid until date in metadata")
+	}
+	return opts
+}
+
+// targetOptions builds scrape options for a target from its metadata.
+// an invalid until date is reported, the other options are still returned.
+func targetOptions(target repository.ScrapingTarget, meta models.TargetMetadata) (ScrapeOptions, error) {
+	opts := ScrapeOptions{
+		TargetID: target.ID,
+		Channel:  target.URL,
+		Limit:    meta.Limit,
+	}
+	if meta.Until != "" {
+		until, err := time.Parse("2006-01-02", meta.Until)
+		if err != nil {
+			return opts, err
+		}
+		opts.Until = &until
+	}
+	return opts, nil
+}
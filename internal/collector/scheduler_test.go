@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/google/uuid"
+)
+
+// mockTargetLister returns a fixed list of targets
+type mockTargetLister struct {
+	targets []repository.ScrapingTarget
+}
+
+func (m *mockTargetLister) GetActive(ctx context.Context) ([]repository.ScrapingTarget, error) {
+	return m.targets, nil
+}
+
+// mockFloodWaiter reports a fixed flood wait deadline
+type mockFloodWaiter struct {
+	until time.Time
+}
+
+func (m *mockFloodWaiter) FloodWaitUntil() time.Time {
+	return m.until
+}
+
+func scheduledTestTarget(interval string, lastScraped *time.Time) repository.ScrapingTarget {
+	return repository.ScrapingTarget{
+		ID:            uuid.New(),
+		Name:          "Go Jobs",
+		Type:          "TG_CHANNEL",
+		URL:           "@golang_jobs",
+		IsActive:      true,
+		LastScrapedAt: lastScraped,
+		Metadata: map[string]interface{}{
+			"scrape_interval": interval,
+			"limit":           50,
+			"until":           "2024-01-01",
+			"keywords":        []interface{}{"golang", "remote"},
+		},
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	now := time.Now()
+
+	t.Run("starts due target with options from metadata", func(t *testing.T) {
+		target := scheduledTestTarget("1h", nil)
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		running := manager.Running()
+		if len(running) != 1 {
+			t.Fatal("expected scrape to be started")
+		}
+		current := running[0]
+		if current.Options.TargetID != target.ID {
+			t.Errorf("TargetID = %v, want %v", current.Options.TargetID, target.ID)
+		}
+		if current.Options.Limit != 50 {
+			t.Errorf("Limit = %d, want 50", current.Options.Limit)
+		}
+		if current.Options.Until == nil || current.Options.Until.Format("2006-01-02") != "2024-01-01" {
+			t.Errorf("Until = %v, want 2024-01-01", current.Options.Until)
+		}
+
+		status := s.Status()
+		if len(status.Targets) != 1 {
+			t.Fatalf("expected 1 scheduled target, got %d", len(status.Targets))
+		}
+		entry := status.Targets[0]
+		if entry.LastRunAt == nil || !entry.LastRunAt.Equal(now) {
+			t.Errorf("LastRunAt = %v, want %v", entry.LastRunAt, now)
+		}
+		if !entry.NextRunAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("NextRunAt = %v, want %v", entry.NextRunAt, now.Add(time.Hour))
+		}
+	})
+
+	t.Run("waits for interval since last scrape", func(t *testing.T) {
+		last := now.Add(-10 * time.Minute)
+		target := scheduledTestTarget("30m", &last)
+		manager := NewScrapeManager(&MockScraper{})
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		if len(manager.Running()) != 0 {
+			t.Error("expected no scrape before interval elapsed")
+		}
+		status := s.Status()
+		if len(status.Targets) != 1 || !status.Targets[0].NextRunAt.Equal(last.Add(30*time.Minute)) {
+			t.Errorf("unexpected schedule: %+v", status.Targets)
+		}
+	})
+
+	t.Run("skips targets without interval", func(t *testing.T) {
+		target := scheduledTestTarget("", nil)
+		manager := NewScrapeManager(&MockScraper{})
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		if len(manager.Running()) != 0 {
+			t.Error("expected no scrape for unscheduled target")
+		}
+		if len(s.Status().Targets) != 0 {
+			t.Error("unscheduled target should not be listed")
+		}
+	})
+
+	t.Run("schedules hh searches and feeds but not other sources", func(t *testing.T) {
+		hh := scheduledTestTarget("1h", nil)
+		hh.Type = "HH_SEARCH"
+		hh.URL = "https://hh.ru/search/vacancy?text=golang"
+		feed := scheduledTestTarget("1h", nil)
+		feed.Type = "FEED"
+		feed.URL = "https://example.com/jobs.rss"
+		linkedin := scheduledTestTarget("1h", nil)
+		linkedin.Type = "LINKEDIN_SEARCH"
+		manager := NewScrapeManager(&MockScraper{})
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{hh, feed, linkedin}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		scheduled := make(map[uuid.UUID]bool)
+		for _, st := range s.Status().Targets {
+			scheduled[st.TargetID] = true
+		}
+		if len(scheduled) != 2 || !scheduled[hh.ID] || !scheduled[feed.ID] {
+			t.Errorf("only the hh and feed targets should be scheduled, got %+v", s.Status().Targets)
+		}
+	})
+
+	t.Run("pauses during flood wait", func(t *testing.T) {
+		target := scheduledTestTarget("1h", nil)
+		manager := NewScrapeManager(&MockScraper{})
+		flood := &mockFloodWaiter{until: time.Now().Add(time.Minute)}
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, flood, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		if len(manager.Running()) != 0 {
+			t.Error("expected no scrape during flood wait")
+		}
+		if s.Status().PausedUntil == nil {
+			t.Error("expected paused_until to be reported")
+		}
+	})
+
+	t.Run("keeps other sources running during flood wait", func(t *testing.T) {
+		channel := scheduledTestTarget("1h", nil)
+		hh := scheduledTestTarget("1h", nil)
+		hh.Type = "HH_SEARCH"
+		hh.URL = "https://hh.ru/search/vacancy?text=golang"
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		flood := &mockFloodWaiter{until: time.Now().Add(time.Minute)}
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{channel, hh}}, flood, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		started := make(map[uuid.UUID]bool)
+		for _, job := range manager.Running() {
+			started[job.Options.TargetID] = true
+		}
+		if len(started) != 1 || !started[hh.ID] {
+			t.Errorf("only the hh target should start during flood wait, got %v", started)
+		}
+	})
+
+	t.Run("queues behind another running scrape", func(t *testing.T) {
+		target := scheduledTestTarget("1h", nil)
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		_, _ = manager.Start(context.Background(), ScrapeOptions{Channel: "manual"})
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		if manager.Queued() != 1 {
+			t.Errorf("Queued() = %d, want 1", manager.Queued())
+		}
+		entry := s.Status().Targets[0]
+		if entry.LastRunAt == nil {
+			t.Error("target should be marked as run")
+		}
+	})
+
+	t.Run("skips target already queued", func(t *testing.T) {
+		target := scheduledTestTarget("1h", nil)
+		manager := NewScrapeManager(&MockScraper{Delay: time.Second})
+		defer manager.Stop()
+		_, _ = manager.Start(context.Background(), ScrapeOptions{Channel: target.URL})
+
+		s := NewScheduler(manager, &mockTargetLister{targets: []repository.ScrapingTarget{target}}, &mockFloodWaiter{}, time.Minute, logger.Get())
+		s.tick(context.Background(), now)
+
+		if manager.Queued() != 0 {
+			t.Errorf("Queued() = %d, want 0", manager.Queued())
+		}
+		entry := s.Status().Targets[0]
+		if !entry.NextRunAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("NextRunAt = %v, want %v", entry.NextRunAt, now.Add(time.Hour))
+		}
+	})
+}
@@ -3,10 +3,10 @@ package collector
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/models"
 	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/blockedby/positions-os/internal/telegram"
 	"github.com/google/uuid"
@@ -14,9 +14,11 @@ import (
 
 // TelegramClient defines interface for telegram operations
 type TelegramClient interface {
-	ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error)
+	ChannelResolver
 	GetMessages(ctx context.Context, channel *telegram.Channel, offsetID int, limit int) ([]telegram.Message, error)
 	GetTopics(ctx context.Context, channel *telegram.Channel) ([]telegram.Topic, error)
+	GetTopicMessages(ctx context.Context, channel *telegram.Channel, topicID int, offsetID int, limit int) ([]telegram.Message, error)
+	GetReplies(ctx context.Context, channel *telegram.Channel, postID int, offsetID int, limit int) ([]telegram.Message, error)
 	GetStatus() telegram.Status
 }
 
@@ -27,12 +29,19 @@ type Service struct {
 	jobs      *repository.JobsRepository
 	ranges    *repository.RangesRepository
 	publisher EventPublisher
-	log       *logger.Logger
+	sources   map[string]Source // by target type
+	hub       Broadcaster       // nil: no scrape events
+	// checkpoints keeps the progress of recorded runs, nil: not resumable
+	checkpoints CheckpointStore
+	// maxFailures consecutive failed runs deactivate a target (0 = never)
+	maxFailures int
+	log         *logger.Logger
 }
 
 // EventPublisher publishes job events
 type EventPublisher interface {
 	PublishJobNew(ctx context.Context, event JobNewEvent) error
+	PublishJobUpdated(ctx context.Context, event JobUpdatedEvent) error
 }
 
 // JobNewEvent represents a new job event for NATS
@@ -42,6 +51,18 @@ type JobNewEvent struct {
 	ExternalID string    `json:"external_id"`
 	RawContent string    `json:"raw_content"`
 	CreatedAt  time.Time `json:"created_at"`
+	// DuplicateOf is the canonical job when the same vacancy was already collected
+	DuplicateOf *uuid.UUID `json:"duplicate_of,omitempty"`
+}
+
+// JobUpdatedEvent represents an edited job event for NATS (jobs.updated).
+// the job has new raw content and should be analyzed again.
+type JobUpdatedEvent struct {
+	JobID      uuid.UUID `json:"job_id"`
+	TargetID   uuid.UUID `json:"target_id"`
+	ExternalID string    `json:"external_id"`
+	RawContent string    `json:"raw_content"`
+	EditedAt   time.Time `json:"edited_at"`
 }
 
 // NewService creates a new collector service
@@ -53,14 +74,23 @@ func NewService(
 	publisher EventPublisher,
 	log *logger.Logger,
 ) *Service {
-	return &Service{
+	s := &Service{
 		tgClient:  tgClient,
 		targets:   targets,
 		jobs:      jobs,
 		ranges:    ranges,
 		publisher: publisher,
+		sources:   make(map[string]Source),
 		log:       log,
+
+		maxFailures: DefaultMaxFailures,
 	}
+
+	tg := &telegramSource{tg: tgClient, targets: targets, log: log}
+	for _, typ := range telegramTargetTypes {
+		s.RegisterSource(typ, tg)
+	}
+	return s
 }
 
 // ListTopics returns list of topics for a forum channel
@@ -86,15 +116,103 @@ func (s *Service) ListTopics(ctx context.Context, channelURL string) ([]telegram
 
 // ScrapeResult contains scraping statistics
 type ScrapeResult struct {
-	TotalFetched int
-	NewJobs      int
-	SkippedOld   int
-	SkippedEmpty int
-	Errors       int
+	TargetID        uuid.UUID `json:"target_id"`
+	TotalFetched    int       `json:"total_fetched"`
+	NewJobs         int       `json:"new_jobs"`
+	SkippedOld      int       `json:"skipped_old"`
+	SkippedEmpty    int       `json:"skipped_empty"`
+	SkippedFiltered int       `json:"skipped_filtered"` // dropped by the target prefilter
+	EditedJobs      int       `json:"edited_jobs"`      // already parsed messages with a new edit
+	Errors          int       `json:"errors"`
+	// Preview lists the fetched items of a dry run
+	Preview []PreviewItem `json:"preview,omitempty"`
+
+	fetchErr error // last failed fetch, for the target health
+}
+
+// PreviewItem is an item seen by a dry run: a would-be job, or the reason it
+// would be skipped
+type PreviewItem struct {
+	ExternalID string    `json:"external_id"`
+	TopicID    *int64    `json:"topic_id,omitempty"`
+	Date       time.Time `json:"date"`
+	URL        string    `json:"url,omitempty"`
+	Content    string    `json:"content,omitempty"`
+	// Skip is empty for a would-be job, else already_collected, empty or
+	// the target filter reason (exclude_keyword, no_include_match, ...).
+	// duplicate and similar jobs would still be stored and count as new.
+	Skip string `json:"skip,omitempty"`
+	// DuplicateOf is the collected job a duplicate would be stored against
+	DuplicateOf *uuid.UUID `json:"duplicate_of,omitempty"`
+	// ClusterID is the cluster a similar job would join
+	ClusterID *uuid.UUID `json:"cluster_id,omitempty"`
+}
+
+// preview skip reasons besides the target filter ones
+const (
+	SkipAlreadyCollected = "already_collected"
+	SkipEmpty            = "empty"
+	// SkipDuplicate: same content as a collected job (of any target)
+	SkipDuplicate = "duplicate"
+	// SkipSimilar: near duplicate of a recent job (simhash cluster)
+	SkipSimilar = "similar"
+)
+
+// addPreview records an item of a dry run
+func (r *ScrapeResult) addPreview(item *Item, job *repository.Job, skip string) {
+	p := PreviewItem{
+		ExternalID: item.ExternalID,
+		TopicID:    item.TopicID,
+		Date:       item.Date,
+		URL:        item.URL,
+		Content:    item.Text,
+		Skip:       skip,
+	}
+	if job != nil {
+		p.Content = job.RawContent
+		if job.SourceURL != nil {
+			p.URL = *job.SourceURL
+		}
+		p.DuplicateOf, p.ClusterID = job.DuplicateOf, job.ClusterID
+	}
+	r.Preview = append(r.Preview, p)
 }
 
-// Scrape performs scraping for given options
+// previewDuplicate runs the duplicate checks of job creation for a would-be
+// job of a dry run, read only: SkipDuplicate with DuplicateOf set,
+// SkipSimilar with ClusterID set, or empty for a new job
+func (s *Service) previewDuplicate(ctx context.Context, job *repository.Job) string {
+	match, err := s.jobs.FindDuplicate(ctx, job)
+	if err != nil {
+		s.log.Warn().Err(err).Str("external_id", job.ExternalID).Msg("scrape: failed to check duplicates")
+		return ""
+	}
+	switch {
+	case match.DuplicateOf != nil:
+		job.DuplicateOf = match.DuplicateOf
+		return SkipDuplicate
+	case match.ClusterID != nil:
+		job.ClusterID = match.ClusterID
+		return SkipSimilar
+	}
+	return ""
+}
+
+// Scrape performs scraping for given options.
+// the target is scraped by the source registered for its type.
+// a dry run (opts.DryRun) walks the same path with the repository writes
+// turned off and lists the fetched items in result.Preview.
+// start, per-batch progress and the outcome are sent to the hub (SetHub).
+// the outcome is recorded in the target health, see recordHealth.
 func (s *Service) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult, error) {
+	target, result, err := s.scrape(ctx, opts)
+	s.recordHealth(ctx, opts, target, result, err)
+	s.notifyEnd(ctx, opts, target, result, err)
+	return result, err
+}
+
+// scrape runs a scrape, the resolved target is nil if resolving it failed
+func (s *Service) scrape(ctx context.Context, opts ScrapeOptions) (*repository.ScrapingTarget, *ScrapeResult, error) {
 	result := &ScrapeResult{}
 
 	s.log.Info().
@@ -106,7 +224,7 @@ func (s *Service) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult
 	target, err := s.getOrCreateTarget(ctx, opts)
 	if err != nil {
 		s.log.Error().Err(err).Msg("scrape: failed to get target")
-		return nil, fmt.Errorf("get target: %w", err)
+		return nil, nil, fmt.Errorf("get target: %w", err)
 	}
 
 	s.log.Info().
@@ -114,31 +232,193 @@ func (s *Service) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult
 		Str("channel", target.URL).
 		Msg("scrape: target resolved")
 
-	// resolve channel if needed
-	s.log.Debug().Str("channel", target.URL).Msg("scrape: resolving channel")
-	channel, err := s.tgClient.ResolveChannel(ctx, target.URL)
+	result.TargetID = target.ID
+	s.notifyStart(opts, target)
+
+	filter, err := s.targetFilter(target)
 	if err != nil {
-		s.log.Error().Err(err).Str("channel", target.URL).Msg("scrape: failed to resolve channel")
-		return nil, fmt.Errorf("resolve channel: %w", err)
+		s.log.Error().Err(err).Msg("scrape: invalid target filter")
+		return target, nil, err
+	}
+
+	src, ok := s.sources[target.Type]
+	if !ok {
+		return target, nil, fmt.Errorf("%w: %s", ErrUnsupportedTarget, target.Type)
+	}
+
+	streams, err := src.Resolve(ctx, target, opts)
+	if err != nil {
+		return target, nil, err
 	}
 
+	resume, err := s.resumePoints(ctx, opts)
+	if err != nil {
+		return target, nil, err
+	}
+
+	var maxSeq int64
+	for _, stream := range streams {
+		if ctx.Err() != nil {
+			s.log.Info().Msg("scrape: cancelled by context")
+			break
+		}
+
+		from := resume[stream.Key()]
+		if from != nil && from.Done {
+			// walked to its end by the interrupted run, keep it done for this one
+			s.log.Info().Int64("topic_id", from.TopicID).Msg("scrape: stream already finished, skipping")
+			if stream.Key() >= 0 && from.MaxMsgID > maxSeq {
+				maxSeq = from.MaxMsgID
+			}
+			continue
+		}
+
+		errorsBefore := result.Errors
+		streamMax, err := s.scrapeStream(ctx, target, src, stream, opts, filter, result, from)
+		if err != nil {
+			return target, nil, err
+		}
+		if c, ok := stream.(committer); ok && !opts.DryRun && ctx.Err() == nil && result.Errors == errorsBefore {
+			if err := c.Commit(ctx); err != nil {
+				s.log.Warn().Err(err).Msg("scrape: failed to save stream state")
+			}
+		}
+		// comment ids are ids of the discussion group, not of the target
+		if stream.Key() >= 0 && streamMax > maxSeq {
+			maxSeq = streamMax
+		}
+	}
+
+	// update target last scraped
+	if opts.DryRun {
+		s.log.Info().Int("preview", len(result.Preview)).Msg("scrape: dry run, nothing stored")
+	} else if err := s.targets.UpdateLastScraped(ctx, target.ID, maxSeq); err != nil {
+		s.log.Warn().Err(err).Msg("scrape: failed to update last scraped")
+	}
+
+	s.logCompleted(result)
+	return target, result, nil
+}
+
+// logCompleted logs the statistics of a finished scrape
+func (s *Service) logCompleted(result *ScrapeResult) {
 	s.log.Info().
-		Int64("channel_id", channel.ID).
-		Int64("access_hash", channel.AccessHash).
-		Bool("is_forum", channel.IsForum).
-		Msg("scrape: channel resolved")
+		Int("total", result.TotalFetched).
+		Int("new", result.NewJobs).
+		Int("skipped_old", result.SkippedOld).
+		Int("skipped_empty", result.SkippedEmpty).
+		Int("skipped_filtered", result.SkippedFiltered).
+		Int("edited", result.EditedJobs).
+		Int("errors", result.Errors).
+		Msg("scrape: completed successfully")
+}
+
+// Ingest creates a job from a single live message of a target.
+// it takes the same path as a scrape: parsed ranges, empty and prefilter checks,
+// job creation and the jobs.new publish. returns false if the message was skipped.
+// an edit of an already parsed message updates its job instead.
+func (s *Service) Ingest(ctx context.Context, target *repository.ScrapingTarget, msg telegram.Message) (bool, error) {
+	src, ok := s.sources[target.Type]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedTarget, target.Type)
+	}
+
+	item := messageItem(&msg)
+	msgID := item.Seq
+	var topicID int64
+	if item.TopicID != nil {
+		topicID = *item.TopicID
+	}
+
+	parsed, err := s.ranges.NewTopicFilter(ctx, target.ID, topicID)
+	if err != nil {
+		return false, fmt.Errorf("create filter: %w", err)
+	}
+	if len(parsed.FilterNew([]int64{msgID})) == 0 {
+		// already scraped, only an edit matters
+		if _, err := s.applyEdit(ctx, target.ID, &item); err != nil {
+			return false, fmt.Errorf("apply edit: %w", err)
+		}
+		return false, nil
+	}
+
+	filter, err := s.targetFilter(target)
+	if err != nil {
+		return false, err
+	}
+
+	created := false
+	if item.Text != "" || item.Attachment != nil {
+		// a scrape of the same target may have picked the message up meanwhile
+		exists, err := s.jobs.Exists(ctx, target.ID, item.ExternalID)
+		if err != nil {
+			return false, fmt.Errorf("check job: %w", err)
+		}
+		if !exists {
+			job, err := src.Job(ctx, target, &item, ScrapeOptions{})
+			if err != nil {
+				return false, fmt.Errorf("map message: %w", err)
+			}
+			if job != nil && job.RawContent != "" && filter.Skip(job.RawContent) == "" {
+				if err := s.createJob(ctx, job); err != nil {
+					return false, fmt.Errorf("create job: %w", err)
+				}
+				created = true
+			}
+		}
+	}
 
-	// update target with telegram info
-	if err := s.targets.UpdateTelegramInfo(ctx, target.ID, channel.ID, channel.AccessHash); err != nil {
-		s.log.Warn().Err(err).Msg("scrape: failed to update telegram info")
+	if err := s.ranges.UpdateTopicRange(ctx, target.ID, topicID, msgID, msgID); err != nil {
+		s.log.Warn().Err(err).Msg("ingest: failed to update parsed range")
 	}
 
+	return created, nil
+}
+
+// targetFilter builds the prefilter from target metadata
+func (s *Service) targetFilter(target *repository.ScrapingTarget) (*MessageFilter, error) {
+	meta, err := models.ParseTargetMetadata(target.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("target metadata: %w", err)
+	}
+	filter, err := NewMessageFilter(meta)
+	if err != nil {
+		return nil, fmt.Errorf("target filter: %w", err)
+	}
+	return filter, nil
+}
+
+// scrapeStream walks one stream of a target from the newest item down,
+// creates jobs for unseen items and re-checks seen ones for edits.
+// items with a sequence id (telegram messages) are deduplicated by the parsed
+// ranges of the stream: already parsed ranges are jumped over, so gaps between
+// them are filled, and the walked span is added to the ranges. without
+// opts.Backfill the walk stops at the oldest parsed item. other items are
+// deduplicated by external id, and the walk stops after a batch whose oldest
+// item is already stored.
+// a dry run stores nothing and adds every item to result.Preview instead.
+// the position of the walk is checkpointed after every batch; from continues
+// the walk of an interrupted run below the span it covered, down to the same
+// oldest parsed item, nil starts from the newest item.
+// returns the max sequence id seen.
+func (s *Service) scrapeStream(
+	ctx context.Context,
+	target *repository.ScrapingTarget,
+	src Source,
+	stream Stream,
+	opts ScrapeOptions,
+	filter *MessageFilter,
+	result *ScrapeResult,
+	from *repository.ScrapeCheckpoint,
+) (int64, error) {
+	key := stream.Key()
+
 	// get message filter for deduplication
-	s.log.Debug().Msg("scrape: creating message filter")
-	filter, err := s.ranges.NewFilter(ctx, target.ID)
+	s.log.Debug().Int64("topic_id", key).Msg("scrape: creating message filter")
+	parsed, err := s.ranges.NewTopicFilter(ctx, target.ID, key)
 	if err != nil {
 		s.log.Error().Err(err).Msg("scrape: failed to create filter")
-		return nil, fmt.Errorf("create filter: %w", err)
+		return 0, fmt.Errorf("create filter: %w", err)
 	}
 
 	// determine limit
@@ -147,130 +427,221 @@ func (s *Service) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult
 		limit = 100 // default batch size
 	}
 
-	s.log.Info().Int("batch_size", limit).Msg("scrape: starting message fetch loop")
+	s.log.Info().
+		Int64("topic_id", key).
+		Int("batch_size", limit).
+		Msg("scrape: starting fetch loop")
 
 	// Safety limits to prevent infinite loops
-	const maxBatches = 100 // Maximum 100 batches = 10,000 messages max
+	const maxBatches = 100 // Maximum 100 batches = 10,000 items max
+
+	// progress of the walk, the floor is where it stops without backfill
+	cp := &repository.ScrapeCheckpoint{RunID: opts.RunID, TargetID: target.ID, TopicID: key}
+	if !opts.Backfill {
+		cp.Floor = parsed.Ranges().Min()
+	}
+	if from != nil {
+		cp = from
+		s.log.Info().
+			Int64("topic_id", key).
+			Int64("cursor", from.Cursor).
+			Int("batches", from.Batches).
+			Msg("scrape: resuming from checkpoint")
+	}
 
-	var minMsgID, maxMsgID int64
-	offsetID := 0
-	previousOffsetID := -1 // Track previous offset to detect stuck loops
+	minSeq, maxSeq := cp.MinMsgID, cp.MaxMsgID
+	reachedUntil := false
+	done := false // the walk reached its end, nothing left to resume
+	fetched := 0
+	cursor := cp.Cursor
+	previousCursor := int64(-1) // Track previous cursor to detect stuck loops
 	batchNum := 0
 
-	// fetch messages in batches
+	// fetch items in batches
 	for batchNum < maxBatches {
 		batchNum++
 		s.log.Info().
+			Int64("topic_id", key).
 			Int("batch", batchNum).
 			Int("max_batches", maxBatches).
-			Int("offset_id", offsetID).
+			Int64("cursor", cursor).
 			Int("limit", min(limit, 100)).
-			Msg("scrape: fetching messages batch")
+			Msg("scrape: fetching batch")
 
-		// Detect if we're stuck on the same offset (infinite loop protection)
-		if offsetID == previousOffsetID && offsetID != 0 {
+		// Detect if we're stuck on the same cursor (infinite loop protection)
+		if cursor == previousCursor && cursor != 0 {
 			s.log.Warn().
-				Int("offset_id", offsetID).
-				Msg("scrape: offset not changing, exiting to prevent infinite loop")
+				Int64("cursor", cursor).
+				Msg("scrape: cursor not changing, exiting to prevent infinite loop")
+			done = true
 			break
 		}
-		previousOffsetID = offsetID
+		previousCursor = cursor
 
-		select {
-		case <-ctx.Done():
+		if ctx.Err() != nil {
 			s.log.Info().Msg("scrape: cancelled by context")
-			return result, nil
-		default:
+			break
 		}
 
-		messages, err := s.tgClient.GetMessages(ctx, channel, offsetID, min(limit, 100))
+		batch, err := stream.Fetch(ctx, cursor, min(limit, 100))
 		if err != nil {
 			s.log.Error().
 				Err(err).
 				Int("batch", batchNum).
-				Int("offset_id", offsetID).
-				Msg("scrape: failed to get messages")
+				Int64("cursor", cursor).
+				Msg("scrape: failed to fetch batch")
 			result.Errors++
+			result.fetchErr = err
 			break
 		}
 
+		items := batch.Items
 		s.log.Info().
 			Int("batch", batchNum).
-			Int("messages_received", len(messages)).
-			Msg("scrape: received messages")
+			Int("items_received", len(items)).
+			Msg("scrape: received batch")
 
-		if len(messages) == 0 {
-			s.log.Info().Msg("scrape: no more messages, exiting loop")
+		if len(items) == 0 {
+			s.log.Info().Msg("scrape: no more items, exiting loop")
+			done = true
 			break
 		}
 
-		result.TotalFetched += len(messages)
-
-		// extract message IDs for filtering
-		var msgIDs []int64
-		for _, msg := range messages {
-			msgIDs = append(msgIDs, int64(msg.ID))
+		fetched += len(items)
+		result.TotalFetched += len(items)
+
+		// items are newest first, everything after the first one
+		// older than until is out of the requested window
+		if opts.Until != nil {
+			for i, item := range items {
+				if item.Date.Before(*opts.Until) {
+					s.log.Info().
+						Str("external_id", item.ExternalID).
+						Time("until", *opts.Until).
+						Msg("scrape: reached until date, exiting loop")
+					items = items[:i]
+					reachedUntil = true
+					break
+				}
+			}
 		}
 
-		s.log.Debug().
-			Int("batch", batchNum).
-			Int("msg_count", len(msgIDs)).
-			Msg("scrape: extracted message IDs")
-
-		// filter out already processed messages
-		newIDs := filter.FilterNew(msgIDs)
-		newIDSet := make(map[int64]bool)
-		for _, id := range newIDs {
-			newIDSet[id] = true
-		}
+		// filter out already parsed sequence ids
+		sequenced := len(items) > 0 && items[0].Seq > 0
+		newSeqs := make(map[int64]bool)
+		if sequenced {
+			seqs := make([]int64, 0, len(items))
+			for _, item := range items {
+				seqs = append(seqs, item.Seq)
+			}
+			for _, seq := range parsed.FilterNew(seqs) {
+				newSeqs[seq] = true
+			}
 
-		s.log.Info().
-			Int("batch", batchNum).
-			Int("total_messages", len(messages)).
-			Int("new_messages", len(newIDs)).
-			Int("already_processed", len(msgIDs)-len(newIDs)).
-			Msg("scrape: filtered messages")
+			s.log.Info().
+				Int("batch", batchNum).
+				Int("total_items", len(items)).
+				Int("new_items", len(newSeqs)).
+				Int("already_processed", len(items)-len(newSeqs)).
+				Msg("scrape: filtered items")
+		}
 
-		// process new messages
+		// process items
 		processedInBatch := 0
-		for _, msg := range messages {
-			msgID := int64(msg.ID)
+		lastKnown := false
+		for i := range items {
+			item := &items[i]
 
 			// track min/max for range update
-			if minMsgID == 0 || msgID < minMsgID {
-				minMsgID = msgID
+			if item.Seq > 0 {
+				if minSeq == 0 || item.Seq < minSeq {
+					minSeq = item.Seq
+				}
+				if item.Seq > maxSeq {
+					maxSeq = item.Seq
+				}
 			}
-			if msgID > maxMsgID {
-				maxMsgID = msgID
+
+			known, err := s.isKnown(ctx, target.ID, item, newSeqs)
+			if err != nil {
+				s.log.Error().Err(err).Str("external_id", item.ExternalID).Msg("scrape: failed to check item")
+				result.Errors++
+				lastKnown = false
+				continue
 			}
+			lastKnown = known
 
-			// skip if already processed
-			if !newIDSet[msgID] {
+			// skip if already processed, recent items are re-checked for edits
+			if known {
 				result.SkippedOld++
+				if opts.DryRun {
+					result.addPreview(item, nil, SkipAlreadyCollected)
+					continue
+				}
+				edited, err := s.applyEdit(ctx, target.ID, item)
+				if err != nil {
+					s.log.Error().Err(err).Str("external_id", item.ExternalID).Msg("scrape: failed to apply edit")
+					result.Errors++
+				} else if edited {
+					result.EditedJobs++
+				}
+				continue
+			}
+
+			// skip empty items, a post with an attachment may get its text from it
+			if item.Text == "" && item.Attachment == nil {
+				result.SkippedEmpty++
+				if opts.DryRun {
+					result.addPreview(item, nil, SkipEmpty)
+				}
+				s.log.Debug().Str("external_id", item.ExternalID).Msg("scrape: skipped empty item")
 				continue
 			}
 
-			// skip empty messages
-			if msg.Text == "" {
+			job, err := src.Job(ctx, target, item, opts)
+			if err != nil {
+				s.log.Error().Err(err).Str("external_id", item.ExternalID).Msg("scrape: failed to map item to job")
+				result.Errors++
+				continue
+			}
+			if job == nil {
 				result.SkippedEmpty++
-				s.log.Debug().Int64("msg_id", msgID).Msg("scrape: skipped empty message")
+				if opts.DryRun {
+					result.addPreview(item, nil, SkipEmpty)
+				}
+				s.log.Debug().Str("external_id", item.ExternalID).Msg("scrape: item is gone")
+				continue
+			}
+			if job.RawContent == "" {
+				result.SkippedEmpty++
+				if opts.DryRun {
+					result.addPreview(item, job, SkipEmpty)
+				}
+				s.log.Debug().Str("external_id", item.ExternalID).Msg("scrape: no text in item or its attachment")
+				continue
+			}
+
+			// target prefilter: keywords, regexes, hashtags
+			if reason := filter.Skip(job.RawContent); reason != "" {
+				result.SkippedFiltered++
+				if opts.DryRun {
+					result.addPreview(item, job, reason)
+				}
+				s.log.Debug().Str("external_id", item.ExternalID).Str("reason", reason).Msg("scrape: skipped by target filter")
 				continue
 			}
 
-			// check until date
-			if opts.Until != nil && msg.Date.Before(*opts.Until) {
-				s.log.Debug().
-					Int64("msg_id", msgID).
-					Time("msg_date", msg.Date).
-					Time("until", *opts.Until).
-					Msg("scrape: skipped message older than until date")
+			if opts.DryRun {
+				result.addPreview(item, job, s.previewDuplicate(ctx, job))
+				result.NewJobs++
+				processedInBatch++
 				continue
 			}
 
 			// create job
-			s.log.Debug().Int64("msg_id", msgID).Msg("scrape: creating job for message")
-			if err := s.createJob(ctx, target.ID, &msg); err != nil {
-				s.log.Error().Err(err).Int("message_id", msg.ID).Msg("scrape: failed to create job")
+			s.log.Debug().Str("external_id", item.ExternalID).Msg("scrape: creating job")
+			if err := s.createJob(ctx, job); err != nil {
+				s.log.Error().Err(err).Str("external_id", item.ExternalID).Msg("scrape: failed to create job")
 				result.Errors++
 				continue
 			}
@@ -284,67 +655,111 @@ func (s *Service) Scrape(ctx context.Context, opts ScrapeOptions) (*ScrapeResult
 			Int("processed", processedInBatch).
 			Int("total_new_jobs", result.NewJobs).
 			Msg("scrape: batch processed")
+		s.reportProgress(opts, target, result.progress(batchNum, key, cursor))
 
-		// update offset for next batch
-		oldOffsetID := offsetID
-		if len(messages) > 0 {
-			offsetID = messages[len(messages)-1].ID
+		cp.Batches++
+		if reachedUntil || len(items) == 0 || batch.Done {
+			done = true
+			break
 		}
 
+		// update cursor for next batch, jumping over parsed ranges.
+		// a resumed walk finds its own span parsed, it stops at the floor
+		oldCursor := cursor
+		next, more := batch.Next, true
+		if sequenced {
+			next, more = nextOffset(parsed.Ranges(), items[len(items)-1].Seq, opts.Backfill || from != nil)
+			if from != nil && cp.Floor > 0 && next <= cp.Floor {
+				more = false
+			}
+		} else if lastKnown && !opts.Backfill {
+			more = false
+		}
+		cursor = next
+
 		s.log.Info().
 			Int("batch", batchNum).
-			Int("old_offset", oldOffsetID).
-			Int("new_offset", offsetID).
-			Msg("scrape: updated offset for next batch")
+			Int64("old_cursor", oldCursor).
+			Int64("new_cursor", cursor).
+			Msg("scrape: updated cursor for next batch")
+
+		if !more {
+			s.log.Info().
+				Int64("oldest_parsed", parsed.Ranges().Min()).
+				Msg("scrape: reached parsed history, exiting loop (use backfill for older items)")
+			done = true
+			break
+		}
 
 		// check if we've fetched enough
-		if opts.Limit > 0 && result.TotalFetched >= opts.Limit {
+		if opts.Limit > 0 && fetched >= opts.Limit {
 			s.log.Info().
-				Int("total_fetched", result.TotalFetched).
+				Int("total_fetched", fetched).
 				Int("limit", opts.Limit).
 				Msg("scrape: reached fetch limit, exiting loop")
+			done = true
 			break
 		}
 
+		cp.Cursor, cp.MinMsgID, cp.MaxMsgID = cursor, minSeq, maxSeq
+		s.saveCheckpoint(opts, cp)
+
 		// small delay to avoid rate limiting
 		s.log.Debug().Msg("scrape: sleeping 100ms to avoid rate limiting")
 		time.Sleep(100 * time.Millisecond)
 	}
 
+	// cancelled, failed or over the batch limit: resumable from the cursor
+	cp.Cursor, cp.MinMsgID, cp.MaxMsgID, cp.Done = cursor, minSeq, maxSeq, done
+	s.saveCheckpoint(opts, cp)
+
 	// Check if we exited due to max batch limit
-	if batchNum >= maxBatches {
+	if batchNum >= maxBatches && !done {
 		s.log.Warn().
 			Int("batches_processed", batchNum).
 			Int("max_batches", maxBatches).
 			Msg("scrape: reached maximum batch limit, stopping for safety")
 	}
 
-	// update parsed range
-	s.log.Info().
-		Int64("min_msg_id", minMsgID).
-		Int64("max_msg_id", maxMsgID).
-		Msg("scrape: updating parsed range")
+	// the walked span is contiguous together with the ranges it jumped over
+	if maxSeq > 0 && !opts.DryRun {
+		s.log.Info().
+			Int64("topic_id", key).
+			Int64("min_msg_id", minSeq).
+			Int64("max_msg_id", maxSeq).
+			Msg("scrape: updating parsed range")
 
-	if maxMsgID > 0 {
-		if err := s.ranges.UpdateRange(ctx, target.ID, minMsgID, maxMsgID); err != nil {
+		if err := s.ranges.UpdateTopicRange(ctx, target.ID, key, minSeq, maxSeq); err != nil {
 			s.log.Warn().Err(err).Msg("scrape: failed to update parsed range")
 		}
 	}
 
-	// update target last scraped
-	if err := s.targets.UpdateLastScraped(ctx, target.ID, maxMsgID); err != nil {
-		s.log.Warn().Err(err).Msg("scrape: failed to update last scraped")
-	}
+	return maxSeq, nil
+}
 
-	s.log.Info().
-		Int("total", result.TotalFetched).
-		Int("new", result.NewJobs).
-		Int("skipped_old", result.SkippedOld).
-		Int("skipped_empty", result.SkippedEmpty).
-		Int("errors", result.Errors).
-		Msg("scrape: completed successfully")
+// isKnown reports whether an item was already collected: by the parsed
+// ranges for sequenced items (newSeqs holds the unparsed ones of the batch),
+// by an existing job with the same external id otherwise
+func (s *Service) isKnown(ctx context.Context, targetID uuid.UUID, item *Item, newSeqs map[int64]bool) (bool, error) {
+	if item.Seq > 0 {
+		return !newSeqs[item.Seq], nil
+	}
+	return s.jobs.Exists(ctx, targetID, item.ExternalID)
+}
 
-	return result, nil
+// nextOffset returns the offset of the next batch after a batch ending at lastID.
+// if the messages right below lastID are already parsed, the walk jumps below
+// their range. more is false when the rest of the stream is older than the
+// oldest parsed message and backfill is off.
+func nextOffset(ranges repository.RangeSet, lastID int64, backfill bool) (offsetID int64, more bool) {
+	offsetID = lastID
+	if r, ok := ranges.Covering(offsetID - 1); ok {
+		offsetID = r.MinMsgID
+	}
+	if !backfill && len(ranges) > 0 && offsetID <= ranges.Min() {
+		return offsetID, false
+	}
+	return offsetID, true
 }
 
 // getOrCreateTarget gets existing target or creates new one
@@ -370,13 +785,25 @@ func (s *Service) getOrCreateTarget(ctx context.Context, opts ScrapeOptions) (*r
 		return target, nil
 	}
 
-	// create new target
-	target = &repository.ScrapingTarget{
-		Name:     opts.Channel,
-		Type:     "TG_CHANNEL", // default, will be updated if it's a forum
-		URL:      opts.Channel,
-		IsActive: true,
+	// a new channel is resolved for its type and canonical url,
+	// which may belong to a stored target typed differently
+	target = &repository.ScrapingTarget{URL: opts.Channel, IsActive: true}
+	if err := s.resolveTarget(ctx, target, opts.DryRun); err != nil {
+		return nil, err
+	}
+	stored, err := s.targets.GetByURL(ctx, target.URL)
+	if err != nil {
+		return nil, err
 	}
+	if stored != nil {
+		return stored, nil
+	}
+
+	// a dry run previews an unknown channel without adding it
+	if opts.DryRun {
+		return target, nil
+	}
+
 	if err := s.targets.Create(ctx, target); err != nil {
 		return nil, fmt.Errorf("create target: %w", err)
 	}
@@ -384,37 +811,28 @@ func (s *Service) getOrCreateTarget(ctx context.Context, opts ScrapeOptions) (*r
 	return target, nil
 }
 
-// createJob creates a new job from a telegram message
-func (s *Service) createJob(ctx context.Context, targetID uuid.UUID, msg *telegram.Message) error {
-	msgID := int64(msg.ID)
-	sourceDate := msg.Date
-
-	job := &repository.Job{
-		TargetID:    targetID,
-		ExternalID:  strconv.FormatInt(msgID, 10),
-		RawContent:  msg.Text,
-		SourceDate:  &sourceDate,
-		TgMessageID: &msgID,
-		Status:      "RAW",
-	}
-
-	if msg.TopicID != nil {
-		topicID := int64(*msg.TopicID)
-		job.TgTopicID = &topicID
-	}
-
+// createJob stores a new job and publishes jobs.new
+func (s *Service) createJob(ctx context.Context, job *repository.Job) error {
 	if err := s.jobs.Create(ctx, job); err != nil {
 		return err
 	}
+	if job.IsDuplicate() {
+		s.log.Debug().
+			Str("job_id", job.ID.String()).
+			Str("duplicate_of", job.DuplicateOf.String()).
+			Msg("job is a duplicate of an earlier job")
+	}
 
 	// publish event
 	if s.publisher != nil {
 		event := JobNewEvent{
 			JobID:      job.ID,
-			TargetID:   targetID,
+			TargetID:   job.TargetID,
 			ExternalID: job.ExternalID,
 			RawContent: job.RawContent,
 			CreatedAt:  job.CreatedAt,
+
+			DuplicateOf: job.DuplicateOf,
 		}
 		if err := s.publisher.PublishJobNew(ctx, event); err != nil {
 			s.log.Warn().Err(err).Msg("failed to publish job event")
@@ -424,6 +842,84 @@ func (s *Service) createJob(ctx context.Context, targetID uuid.UUID, msg *telegr
 	return nil
 }
 
+// CloseDeleted closes the jobs of deleted messages of a target.
+// returns the number of closed jobs.
+func (s *Service) CloseDeleted(ctx context.Context, target *repository.ScrapingTarget, msgIDs []int) (int, error) {
+	ids := make([]int64, len(msgIDs))
+	for i, id := range msgIDs {
+		ids[i] = int64(id)
+	}
+
+	closed, err := s.jobs.CloseByMessageIDs(ctx, target.ID, ids)
+	if err != nil {
+		return 0, err
+	}
+	if closed > 0 {
+		s.log.Info().
+			Str("target_id", target.ID.String()).
+			Ints("msg_ids", msgIDs).
+			Int("closed", closed).
+			Msg("jobs closed, source messages deleted")
+	}
+	return closed, nil
+}
+
+// applyEdit updates the job of an already parsed item (message) that was edited since
+// its content was stored. the previous content is kept as a revision and
+// jobs.updated is published, so the analyzer refreshes the structured data.
+// returns true if the job content changed.
+func (s *Service) applyEdit(ctx context.Context, targetID uuid.UUID, item *Item) (bool, error) {
+	if item.EditDate == nil || (item.Text == "" && item.Attachment == nil) {
+		return false, nil
+	}
+
+	job, err := s.jobs.GetByExternalID(ctx, targetID, item.ExternalID)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil // no job: empty or filtered out
+	}
+	if job.EditedAt != nil && !item.EditDate.After(*job.EditedAt) {
+		return false, nil // edit already seen
+	}
+
+	// an edited caption keeps the text of the attached document
+	content := attachmentContent(item.Text, job.Attachment)
+	if content == "" {
+		return false, nil
+	}
+
+	changed, err := s.jobs.UpdateContent(ctx, job.ID, content, *item.EditDate)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := s.jobs.UpdateLinks(ctx, job.ID, item.Links); err != nil {
+		return false, err
+	}
+
+	s.log.Info().
+		Str("job_id", job.ID.String()).
+		Str("external_id", item.ExternalID).
+		Time("edited_at", *item.EditDate).
+		Msg("job updated from edited message")
+
+	if s.publisher != nil {
+		event := JobUpdatedEvent{
+			JobID:      job.ID,
+			TargetID:   targetID,
+			ExternalID: job.ExternalID,
+			RawContent: content,
+			EditedAt:   *item.EditDate,
+		}
+		if err := s.publisher.PublishJobUpdated(ctx, event); err != nil {
+			s.log.Warn().Err(err).Msg("failed to publish job updated event")
+		}
+	}
+
+	return true, nil
+}
+
 // GetTelegramStatus returns the current status of the telegram connection
 func (s *Service) GetTelegramStatus() telegram.Status {
 	// If the client wrapper exposes status, we use it.
@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/hh"
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+)
+
+// MockTelegramClient for testing service logic that does not touch the database
+type MockTelegramClient struct {
+	Channel       *telegram.Channel
+	Topics        []telegram.Topic
+	TopicMessages map[int][]telegram.Message
+	Posts         []telegram.Message
+	Replies       map[int][]telegram.Message // by post id
+
+	InviteNotJoined bool // CheckInvite: the invite link is not joined yet, no peek
+	Joined          int  // JoinInvite calls
+}
+
+func (m *MockTelegramClient) ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error) {
+	if m.Channel == nil {
+		return nil, fmt.Errorf("%w: %s", telegram.ErrChannelNotFound, username)
+	}
+	return m.Channel, nil
+}
+
+func (m *MockTelegramClient) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error) {
+	if m.Channel == nil {
+		return nil, fmt.Errorf("%w: channel %d", telegram.ErrChannelNotFound, id)
+	}
+	return m.Channel, nil
+}
+
+func (m *MockTelegramClient) JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	m.Joined++
+	if m.Channel == nil {
+		return nil, fmt.Errorf("%w: invite link", telegram.ErrChannelNotFound)
+	}
+	return m.Channel, nil
+}
+
+func (m *MockTelegramClient) CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	if m.Channel == nil || m.InviteNotJoined {
+		return nil, fmt.Errorf("%w: invite link is not joined yet", telegram.ErrChannelPrivate)
+	}
+	return m.Channel, nil
+}
+
+func (m *MockTelegramClient) GetMessages(ctx context.Context, channel *telegram.Channel, offsetID int, limit int) ([]telegram.Message, error) {
+	if offsetID > 0 {
+		return []telegram.Message{}, nil
+	}
+	return m.Posts, nil
+}
+
+func (m *MockTelegramClient) GetTopics(ctx context.Context, channel *telegram.Channel) ([]telegram.Topic, error) {
+	return m.Topics, nil
+}
+
+func (m *MockTelegramClient) GetTopicMessages(ctx context.Context, channel *telegram.Channel, topicID int, offsetID int, limit int) ([]telegram.Message, error) {
+	return m.TopicMessages[topicID], nil
+}
+
+func (m *MockTelegramClient) GetReplies(ctx context.Context, channel *telegram.Channel, postID int, offsetID int, limit int) ([]telegram.Message, error) {
+	return m.Replies[postID], nil
+}
+
+func (m *MockTelegramClient) GetStatus() telegram.Status {
+	return telegram.StatusReady
+}
+
+func newTestService(tg TelegramClient) *Service {
+	return NewService(tg, nil, nil, nil, nil, logger.Get())
+}
+
+// test that telegram and hh.ru sources are registered by target type
+func TestService_Sources(t *testing.T) {
+	svc := newTestService(&MockTelegramClient{})
+
+	for _, typ := range []string{"TG_CHANNEL", "TG_GROUP", "TG_FORUM"} {
+		if _, ok := svc.sources[typ].(*telegramSource); !ok {
+			t.Errorf("%s should be scraped by the telegram source", typ)
+		}
+	}
+	if _, ok := svc.sources["HH_SEARCH"]; ok {
+		t.Error("HH_SEARCH should need an hh client")
+	}
+
+	svc.SetHHClient(hh.NewClient("", ""))
+	if _, ok := svc.sources["HH_SEARCH"].(*hhSource); !ok {
+		t.Error("HH_SEARCH should be scraped by the hh source")
+	}
+}
+
+// test that live messages are only ingested for targets with a registered source
+func TestService_IngestUnsupportedTarget(t *testing.T) {
+	svc := newTestService(&MockTelegramClient{})
+	target := &repository.ScrapingTarget{Type: "LINKEDIN_SEARCH"}
+
+	_, err := svc.Ingest(context.Background(), target, telegram.Message{ID: 1, Text: "Go developer"})
+	if !errors.Is(err, ErrUnsupportedTarget) {
+		t.Errorf("expected ErrUnsupportedTarget, got %v", err)
+	}
+}
+
+// test offset selection for gap-aware walking
+func TestNextOffset(t *testing.T) {
+	ranges := repository.RangeSet{
+		{MinMsgID: 100, MaxMsgID: 200},
+		{MinMsgID: 500, MaxMsgID: 800},
+	}
+
+	tests := []struct {
+		name     string
+		ranges   repository.RangeSet
+		lastID   int64
+		backfill bool
+		want     int64
+		wantMore bool
+	}{
+		{"no parsed ranges", nil, 950, false, 950, true},
+		{"above parsed ranges", ranges, 900, false, 900, true},
+		{"jumps over parsed range", ranges, 801, false, 500, true},
+		{"continues into gap", ranges, 450, false, 450, true},
+		{"stops at oldest parsed", ranges, 201, false, 100, false},
+		{"backfill walks below oldest parsed", ranges, 201, true, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, more := nextOffset(tt.ranges, tt.lastID, tt.backfill)
+			if got != tt.want || more != tt.wantMore {
+				t.Errorf("nextOffset(%d) = %d, %v, want %d, %v", tt.lastID, got, more, tt.want, tt.wantMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/repository"
+)
+
+// ErrUnsupportedTarget is returned when no source is registered for a target type
+var ErrUnsupportedTarget = errors.New("no source for target type")
+
+// Source is a kind of scraping target (telegram, hh.ru, ...).
+// Service.Scrape resolves a target with the source registered for its type
+// and walks every stream it returns; batching, dedup, parsed ranges,
+// prefilter, job creation and publishing are shared by all sources.
+type Source interface {
+	// Resolve prepares a target for scraping and returns the streams to walk
+	Resolve(ctx context.Context, target *repository.ScrapingTarget, opts ScrapeOptions) ([]Stream, error)
+	// Job maps a new item to a job, nil skips an item that is gone.
+	// a dry run (opts.DryRun) must not store anything, attachments included.
+	Job(ctx context.Context, target *repository.ScrapingTarget, item *Item, opts ScrapeOptions) (*repository.Job, error)
+}
+
+// Stream is one feed of a target (channel history, forum topic, search), newest first
+type Stream interface {
+	// Key is the parsed range key of the stream within its target
+	// (forum topic id, 0 = whole target, -post id = comment thread)
+	Key() int64
+	// Fetch returns a batch of items older than cursor (0 = newest)
+	Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error)
+}
+
+// Batch is one page of a stream
+type Batch struct {
+	Items []Item
+	Next  int64 // cursor of the next, older batch
+	Done  bool  // no older items
+}
+
+// Item is a post of a stream before it becomes a job
+type Item struct {
+	ExternalID string
+	// Seq orders the items of a stream (telegram message id), seq streams
+	// are deduplicated by parsed ranges. 0 = dedup by external id.
+	Seq      int64
+	TopicID  *int64
+	Date     time.Time
+	EditDate *time.Time
+	Text     string
+	URL      string // link to the post, if the source has one
+	// Attachment is the document or photo of the post, Text is then its caption
+	Attachment *repository.Attachment
+	// Links are the urls and contacts marked up in the post (message entities)
+	Links []repository.Link
+	// Parent is the post a comment replies to, nil for posts
+	Parent *Item
+}
+
+// committer is implemented by streams with state to save once they were
+// walked without errors (feed http validators)
+type committer interface {
+	Commit(ctx context.Context) error
+}
+
+// RegisterSource sets the source of a target type
+func (s *Service) RegisterSource(targetType string, src Source) {
+	s.sources[targetType] = src
+}
@@ -0,0 +1,339 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// telegramTargetTypes are the target types scraped by the telegram source
+var telegramTargetTypes = []string{"TG_CHANNEL", "TG_GROUP", "TG_FORUM"}
+
+// telegramSource scrapes telegram channels, groups and forums.
+// forums are walked topic by topic, each topic with its own parsed ranges.
+type telegramSource struct {
+	tg          TelegramClient
+	targets     *repository.TargetsRepository
+	attachments *AttachmentStore // nil: documents are not downloaded
+	log         *logger.Logger
+}
+
+// maxCommentPosts caps the newest posts whose comments are collected
+const maxCommentPosts = 100
+
+// Resolve resolves the channel of a target and returns its history,
+// or one stream per requested topic for forums. with comments on in the
+// target metadata, the comment threads of the newest posts follow.
+func (t *telegramSource) Resolve(ctx context.Context, target *repository.ScrapingTarget, opts ScrapeOptions) ([]Stream, error) {
+	t.log.Debug().Str("channel", target.URL).Msg("scrape: resolving channel")
+	channel, err := resolveChannel(ctx, t.tg, target, opts.DryRun)
+	if err != nil {
+		t.log.Error().Err(err).Str("channel", target.URL).Msg("scrape: failed to resolve channel")
+		return nil, targetFailure(fmt.Errorf("resolve channel: %w", err))
+	}
+
+	t.log.Info().
+		Int64("channel_id", channel.ID).
+		Int64("access_hash", channel.AccessHash).
+		Bool("is_forum", channel.IsForum).
+		Msg("scrape: channel resolved")
+
+	if len(opts.TopicIDs) > 0 && !channel.IsForum {
+		return nil, ErrTopicsForForum
+	}
+
+	// update target with telegram info, a dry run stores nothing
+	if !opts.DryRun {
+		if err := t.targets.UpdateTelegramInfo(ctx, target.ID, channel.ID, channel.AccessHash); err != nil {
+			t.log.Warn().Err(err).Msg("scrape: failed to update telegram info")
+		}
+	}
+	// permalinks of private channels need the channel id
+	target.TgChannelID, target.TgAccessHash = &channel.ID, &channel.AccessHash
+
+	if !channel.IsForum {
+		streams := []Stream{&channelStream{tg: t.tg, channel: channel}}
+		threads, err := t.resolveThreads(ctx, target, channel, opts)
+		if err != nil {
+			t.log.Error().Err(err).Msg("scrape: failed to resolve comment threads")
+			return nil, err
+		}
+		return append(streams, threads...), nil
+	}
+
+	topicIDs, err := t.resolveTopics(ctx, channel, opts.TopicIDs)
+	if err != nil {
+		t.log.Error().Err(err).Msg("scrape: failed to resolve topics")
+		return nil, err
+	}
+
+	t.log.Info().Ints("topic_ids", topicIDs).Msg("scrape: scraping forum topics")
+
+	streams := make([]Stream, 0, len(topicIDs))
+	for _, id := range topicIDs {
+		streams = append(streams, &topicStream{tg: t.tg, channel: channel, topicID: id})
+	}
+	return streams, nil
+}
+
+// resolveThreads returns a stream per commented post among the newest ones
+// (up to the scrape limit) when the target collects comments and the
+// channel has a discussion group
+func (t *telegramSource) resolveThreads(ctx context.Context, target *repository.ScrapingTarget, channel *telegram.Channel, opts ScrapeOptions) ([]Stream, error) {
+	meta, err := models.ParseTargetMetadata(target.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("target metadata: %w", err)
+	}
+	if !meta.Comments || channel.LinkedChat == nil {
+		return nil, nil
+	}
+
+	limit := opts.Limit
+	if limit <= 0 || limit > maxCommentPosts {
+		limit = maxCommentPosts
+	}
+	posts, err := t.tg.GetMessages(ctx, channel, 0, limit)
+	if err != nil {
+		return nil, targetFailure(fmt.Errorf("get posts: %w", err))
+	}
+
+	var streams []Stream
+	for i := range posts {
+		if posts[i].Replies == 0 {
+			continue
+		}
+		post := messageItem(&posts[i])
+		post.URL = target.Permalink(post.Seq, nil)
+		streams = append(streams, &threadStream{tg: t.tg, channel: channel, post: post})
+	}
+
+	t.log.Info().
+		Int64("linked_chat_id", channel.LinkedChat.ID).
+		Int("threads", len(streams)).
+		Msg("scrape: scraping comment threads")
+	return streams, nil
+}
+
+// Job maps a message to a job linking back to the post.
+// the text of an attached document is downloaded and added to the caption;
+// when that fails the post is kept with its caption only.
+// a dry run reads the document in memory, the file is not saved.
+func (t *telegramSource) Job(ctx context.Context, target *repository.ScrapingTarget, item *Item, opts ScrapeOptions) (*repository.Job, error) {
+	job := messageJob(target.ID, item)
+	url := target.Permalink(item.Seq, item.TopicID)
+	if item.Parent != nil {
+		url = target.CommentPermalink(item.Parent.Seq, item.Seq)
+	}
+	if url != "" {
+		job.SourceURL = &url
+	}
+	if item.Attachment == nil {
+		return job, nil
+	}
+
+	att := *item.Attachment
+	if t.attachments != nil {
+		var err error
+		if opts.DryRun {
+			err = t.attachments.Read(ctx, &att)
+		} else {
+			err = t.attachments.Fetch(ctx, target.ID, item.ExternalID, &att)
+		}
+		if err != nil {
+			t.log.Warn().
+				Err(err).
+				Str("external_id", item.ExternalID).
+				Str("file_name", att.FileName).
+				Msg("scrape: failed to read attachment")
+		}
+	}
+	job.Attachment = &att
+	job.RawContent = attachmentContent(item.Text, &att)
+	return job, nil
+}
+
+// resolveTopics returns the topic ids to scrape.
+// empty requested list means all topics of the forum.
+func (t *telegramSource) resolveTopics(ctx context.Context, channel *telegram.Channel, requested []int) ([]int, error) {
+	topics, err := t.tg.GetTopics(ctx, channel)
+	if err != nil {
+		return nil, targetFailure(fmt.Errorf("get topics: %w", err))
+	}
+
+	known := make(map[int]bool, len(topics))
+	for _, topic := range topics {
+		known[topic.ID] = true
+	}
+
+	if len(requested) == 0 {
+		ids := make([]int, 0, len(topics))
+		for _, topic := range topics {
+			ids = append(ids, topic.ID)
+		}
+		return ids, nil
+	}
+
+	for _, id := range requested {
+		if !known[id] {
+			t.log.Warn().Int("topic_id", id).Msg("scrape: requested topic not found in forum")
+			return nil, ErrTopicNotFound
+		}
+	}
+
+	return requested, nil
+}
+
+// channelStream is the message history of a channel or group
+type channelStream struct {
+	tg      TelegramClient
+	channel *telegram.Channel
+}
+
+// Key returns 0, the whole channel is one stream
+func (c *channelStream) Key() int64 {
+	return 0
+}
+
+// Fetch returns messages older than cursor (0 = newest)
+func (c *channelStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	messages, err := c.tg.GetMessages(ctx, c.channel, int(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	return messageBatch(messages), nil
+}
+
+// topicStream is the message history of one forum topic
+type topicStream struct {
+	tg      TelegramClient
+	channel *telegram.Channel
+	topicID int
+}
+
+// Key returns the topic id
+func (t *topicStream) Key() int64 {
+	return int64(t.topicID)
+}
+
+// Fetch returns topic messages older than cursor (0 = newest).
+// messages are stamped with the topic id because the topic root and
+// plain (non-reply) posts do not always carry it in the reply header.
+func (t *topicStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	messages, err := t.tg.GetTopicMessages(ctx, t.channel, t.topicID, int(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		if messages[i].TopicID == nil {
+			tid := t.topicID
+			messages[i].TopicID = &tid
+		}
+	}
+	return messageBatch(messages), nil
+}
+
+// threadStream is the comment thread of a channel post in the linked
+// discussion group
+type threadStream struct {
+	tg      TelegramClient
+	channel *telegram.Channel
+	post    Item
+}
+
+// Key returns the negated post id: comment ids are ids of the discussion
+// group, each thread keeps its own parsed ranges apart from the channel
+func (t *threadStream) Key() int64 {
+	return -t.post.Seq
+}
+
+// Fetch returns comments older than cursor (0 = newest), linked to the post
+func (t *threadStream) Fetch(ctx context.Context, cursor int64, limit int) (*Batch, error) {
+	messages, err := t.tg.GetReplies(ctx, t.channel, int(t.post.Seq), int(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	batch := messageBatch(messages)
+	for i := range batch.Items {
+		batch.Items[i].ExternalID = "comment-" + batch.Items[i].ExternalID
+		batch.Items[i].Parent = &t.post
+	}
+	return batch, nil
+}
+
+// messageBatch converts a batch of messages, newest first
+func messageBatch(messages []telegram.Message) *Batch {
+	batch := &Batch{Items: make([]Item, 0, len(messages))}
+	for i := range messages {
+		batch.Items = append(batch.Items, messageItem(&messages[i]))
+	}
+	if len(messages) == 0 {
+		batch.Done = true
+	} else {
+		batch.Next = int64(messages[len(messages)-1].ID)
+	}
+	return batch
+}
+
+// messageItem converts a telegram message
+func messageItem(msg *telegram.Message) Item {
+	item := Item{
+		ExternalID: strconv.Itoa(msg.ID),
+		Seq:        int64(msg.ID),
+		Date:       msg.Date,
+		EditDate:   msg.EditDate,
+		Text:       msg.Text,
+		Attachment: messageAttachment(msg.Attachment),
+		Links:      messageLinks(msg.Links),
+	}
+	if msg.TopicID != nil {
+		topicID := int64(*msg.TopicID)
+		item.TopicID = &topicID
+	}
+	return item
+}
+
+// messageJob builds a job from a telegram message.
+// a comment is no message of the target: it has no tg_message_id and
+// keeps the post it replies to.
+func messageJob(targetID uuid.UUID, item *Item) *repository.Job {
+	msgID := item.Seq
+	sourceDate := item.Date
+
+	job := &repository.Job{
+		TargetID:    targetID,
+		ExternalID:  item.ExternalID,
+		RawContent:  item.Text,
+		SourceDate:  &sourceDate,
+		TgMessageID: &msgID,
+		TgTopicID:   item.TopicID,
+		Links:       item.Links,
+		Status:      "RAW",
+	}
+	if item.Parent != nil {
+		job.TgMessageID = nil
+		job.Parent = &repository.ParentPost{
+			MessageID: item.Parent.Seq,
+			Text:      item.Parent.Text,
+			URL:       item.Parent.URL,
+		}
+	}
+	return job
+}
+
+// messageLinks converts the entity links of a telegram message
+func messageLinks(links []telegram.Link) []repository.Link {
+	if len(links) == 0 {
+		return nil
+	}
+	converted := make([]repository.Link, len(links))
+	for i, link := range links {
+		converted[i] = repository.Link{Type: link.Type, Text: link.Text, URL: link.URL}
+	}
+	return converted
+}
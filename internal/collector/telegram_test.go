@@ -0,0 +1,213 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// test topic resolution for forum scraping
+func TestTelegramSource_ResolveTopics(t *testing.T) {
+	tg := &MockTelegramClient{
+		Channel: &telegram.Channel{ID: 1, IsForum: true},
+		Topics: []telegram.Topic{
+			{ID: 1, Title: "General"},
+			{ID: 15, Title: "Vacancies"},
+			{ID: 27, Title: "Resumes"},
+		},
+	}
+	src := &telegramSource{tg: tg, log: logger.Get()}
+
+	t.Run("empty list means all topics", func(t *testing.T) {
+		ids, err := src.resolveTopics(context.Background(), tg.Channel, nil)
+		if err != nil {
+			t.Fatalf("resolveTopics() error: %v", err)
+		}
+		if len(ids) != 3 {
+			t.Errorf("resolveTopics() returned %d topics, want 3", len(ids))
+		}
+	})
+
+	t.Run("keeps requested topics", func(t *testing.T) {
+		ids, err := src.resolveTopics(context.Background(), tg.Channel, []int{15})
+		if err != nil {
+			t.Fatalf("resolveTopics() error: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != 15 {
+			t.Errorf("resolveTopics() = %v, want [15]", ids)
+		}
+	})
+
+	t.Run("rejects unknown topic", func(t *testing.T) {
+		_, err := src.resolveTopics(context.Background(), tg.Channel, []int{15, 99})
+		if err != ErrTopicNotFound {
+			t.Errorf("resolveTopics() error = %v, want ErrTopicNotFound", err)
+		}
+	})
+}
+
+// test that topic messages are stamped with their topic id
+func TestTopicStream_Fetch(t *testing.T) {
+	otherTopic := 27
+	tg := &MockTelegramClient{
+		TopicMessages: map[int][]telegram.Message{
+			15: {
+				{ID: 101, Text: "reply", TopicID: &otherTopic},
+				{ID: 100, Text: "root post"},
+			},
+		},
+	}
+
+	stream := &topicStream{tg: tg, channel: &telegram.Channel{ID: 1, IsForum: true}, topicID: 15}
+	if stream.Key() != 15 {
+		t.Errorf("Key() = %d, want topic id 15", stream.Key())
+	}
+
+	batch, err := stream.Fetch(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+
+	if *batch.Items[0].TopicID != 27 {
+		t.Errorf("message topic from reply header should be kept, got %d", *batch.Items[0].TopicID)
+	}
+	if batch.Items[1].TopicID == nil || *batch.Items[1].TopicID != 15 {
+		t.Errorf("message without topic should get topic 15, got %v", batch.Items[1].TopicID)
+	}
+	if batch.Items[1].Seq != 100 || batch.Items[1].ExternalID != "100" {
+		t.Errorf("item ids = %d, %q, want message id 100", batch.Items[1].Seq, batch.Items[1].ExternalID)
+	}
+	if batch.Next != 100 || batch.Done {
+		t.Errorf("Next = %d, Done = %v, want the oldest message id as cursor", batch.Next, batch.Done)
+	}
+
+	if !messageBatch(nil).Done {
+		t.Error("an empty batch should end the stream")
+	}
+}
+
+// test mapping of a message to a job
+func TestMessageJob(t *testing.T) {
+	topic := 15
+	date := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	item := messageItem(&telegram.Message{ID: 42, Text: "Go developer", Date: date, TopicID: &topic})
+
+	targetID := uuid.New()
+	job := messageJob(targetID, &item)
+	if job.TargetID != targetID || job.ExternalID != "42" || job.RawContent != "Go developer" || job.Status != "RAW" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if *job.TgMessageID != 42 || *job.TgTopicID != 15 || !job.SourceDate.Equal(date) {
+		t.Errorf("telegram fields = %d, %d, %v", *job.TgMessageID, *job.TgTopicID, job.SourceDate)
+	}
+	if job.Links != nil {
+		t.Errorf("expected no links, got %+v", job.Links)
+	}
+}
+
+func TestMessageJob_Links(t *testing.T) {
+	item := messageItem(&telegram.Message{ID: 7, Text: "Go developer, write @hr_anna", Links: []telegram.Link{
+		{Type: telegram.LinkMention, URL: "@hr_anna"},
+		{Type: telegram.LinkTextURL, Text: "apply", URL: "https://example.com/apply"},
+	}})
+
+	job := messageJob(uuid.New(), &item)
+	want := []repository.Link{
+		{Type: repository.LinkMention, URL: "@hr_anna"},
+		{Type: repository.LinkTextURL, Text: "apply", URL: "https://example.com/apply"},
+	}
+	if !reflect.DeepEqual(job.Links, want) {
+		t.Errorf("links = %+v, want %+v", job.Links, want)
+	}
+}
+
+// test that jobs link back to their post
+func TestTelegramSource_JobPermalink(t *testing.T) {
+	src := &telegramSource{log: logger.Get()}
+	topic := 15
+	item := messageItem(&telegram.Message{ID: 42, Text: "Go developer", TopicID: &topic})
+
+	job, err := src.Job(context.Background(), &repository.ScrapingTarget{ID: uuid.New(), URL: "@go_forum"}, &item, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Job() error: %v", err)
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://t.me/go_forum/15/42" {
+		t.Errorf("source_url = %v, want forum permalink", job.SourceURL)
+	}
+
+	job, err = src.Job(context.Background(), &repository.ScrapingTarget{ID: uuid.New(), URL: "https://t.me/+AbCdEf"}, &item, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Job() error: %v", err)
+	}
+	if job.SourceURL != nil {
+		t.Errorf("unresolved private target should have no source_url, got %q", *job.SourceURL)
+	}
+}
+
+// test that comment threads are walked for the commented posts of targets collecting comments
+func TestTelegramSource_ResolveThreads(t *testing.T) {
+	channel := &telegram.Channel{ID: 1, Username: "go_jobs", LinkedChat: &telegram.Channel{ID: 2}}
+	tg := &MockTelegramClient{Posts: []telegram.Message{
+		{ID: 12, Text: "Go developer at Acme", Replies: 3},
+		{ID: 11, Text: "Weekly digest"},
+	}}
+	src := &telegramSource{tg: tg, log: logger.Get()}
+	target := &repository.ScrapingTarget{URL: "@go_jobs", Metadata: map[string]any{"comments": true}}
+
+	streams, err := src.resolveThreads(context.Background(), target, channel, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("resolveThreads() error: %v", err)
+	}
+	if len(streams) != 1 {
+		t.Fatalf("got %d threads, want 1 (post without comments left out)", len(streams))
+	}
+	thread := streams[0].(*threadStream)
+	if thread.Key() != -12 || thread.post.URL != "https://t.me/go_jobs/12" {
+		t.Errorf("thread key = %d, post url = %q", thread.Key(), thread.post.URL)
+	}
+
+	target.Metadata = nil
+	if streams, _ := src.resolveThreads(context.Background(), target, channel, ScrapeOptions{}); streams != nil {
+		t.Errorf("comments are off by default, got %d threads", len(streams))
+	}
+}
+
+// test that comments become jobs with their post as context
+func TestThreadStream_Fetch(t *testing.T) {
+	tg := &MockTelegramClient{Replies: map[int][]telegram.Message{
+		12: {{ID: 501, Text: "Is it remote? Yes, write @hr_anna"}},
+	}}
+	post := Item{Seq: 12, Text: "Go developer at Acme", URL: "https://t.me/go_jobs/12"}
+	stream := &threadStream{tg: tg, channel: &telegram.Channel{ID: 1}, post: post}
+
+	batch, err := stream.Fetch(context.Background(), 0, 100)
+	if err != nil {
+		t.Fatalf("Fetch() error: %v", err)
+	}
+	item := batch.Items[0]
+	if item.ExternalID != "comment-501" || item.Seq != 501 || item.Parent == nil || item.Parent.Seq != 12 {
+		t.Fatalf("unexpected comment item: %+v", item)
+	}
+
+	src := &telegramSource{log: logger.Get()}
+	job, err := src.Job(context.Background(), &repository.ScrapingTarget{ID: uuid.New(), URL: "@go_jobs"}, &item, ScrapeOptions{})
+	if err != nil {
+		t.Fatalf("Job() error: %v", err)
+	}
+	if job.TgMessageID != nil {
+		t.Errorf("a comment is no message of the channel, got tg_message_id %d", *job.TgMessageID)
+	}
+	want := &repository.ParentPost{MessageID: 12, Text: "Go developer at Acme", URL: "https://t.me/go_jobs/12"}
+	if !reflect.DeepEqual(job.Parent, want) {
+		t.Errorf("parent = %+v, want %+v", job.Parent, want)
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://t.me/go_jobs/12?comment=501" {
+		t.Errorf("source_url = %v, want comment permalink", job.SourceURL)
+	}
+}
@@ -18,8 +18,12 @@ var (
 	ErrInvalidLimit    = errors.New("limit must be non-negative")
 	ErrTopicsForForum  = errors.New("topic_ids can only be used with TG_FORUM targets")
 	ErrTopicNotFound   = errors.New("one or more topic_ids not found in the forum")
+	ErrBackfillUntil   = errors.New("backfill requires an until date")
 )
 
+// DefaultPreviewLimit is the number of messages fetched by a dry run without a limit
+const DefaultPreviewLimit = 50
+
 // ScrapeRequest represents a request to scrape a telegram channel
 type ScrapeRequest struct {
 	// TargetID - id from scraping_targets table.
@@ -41,6 +45,14 @@ type ScrapeRequest struct {
 	// TopicIDs - list of forum topic ids to scrape.
 	// only for TG_FORUM targets. empty means all topics.
 	TopicIDs []int `json:"topic_ids,omitempty"`
+
+	// Backfill - also walk history older than the oldest parsed message,
+	// filling gaps down to the until date.
+	Backfill bool `json:"backfill,omitempty"`
+
+	// DryRun - fetch and filter messages without storing anything,
+	// the result lists the would-be jobs with their skip reasons.
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 // Validate performs basic validation of the request
@@ -70,6 +82,16 @@ func (r *ScrapeRequest) Validate() error {
 		}
 	}
 
+	// backfill walks old history, it needs a lower bound
+	if r.Backfill && r.Until == "" {
+		return ErrBackfillUntil
+	}
+
+	// a preview is answered right away, it must stay short
+	if r.DryRun && r.Limit == 0 {
+		r.Limit = DefaultPreviewLimit
+	}
+
 	return nil
 }
 
@@ -89,9 +111,10 @@ func (r *ScrapeRequest) UntilTime() *time.Time {
 // ScrapeResponse represents response to scrape request
 type ScrapeResponse struct {
 	ScrapeID  uuid.UUID  `json:"scrape_id"`
-	Status    string     `json:"status"` // "running" | "completed" | "failed"
+	Status    string     `json:"status"` // "queued" | "running"
 	Target    TargetInfo `json:"target"`
-	StartedAt time.Time  `json:"started_at"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
 }
 
 // TargetInfo contains brief info about scraping target
@@ -86,6 +86,31 @@ func TestScrapeRequest_Validate(t *testing.T) {
 			},
 			wantErr: nil, // validation at runtime, not in basic validate
 		},
+		{
+			name: "backfill with until",
+			req: ScrapeRequest{
+				Channel:  "@test",
+				Until:    "2024-01-15",
+				Backfill: true,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "backfill without until",
+			req: ScrapeRequest{
+				Channel:  "@test",
+				Backfill: true,
+			},
+			wantErr: ErrBackfillUntil,
+		},
+		{
+			name: "dry run",
+			req: ScrapeRequest{
+				Channel: "@test",
+				DryRun:  true,
+			},
+			wantErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -108,6 +133,25 @@ func TestScrapeRequest_Validate(t *testing.T) {
 	}
 }
 
+// test that a dry run without a limit fetches a short preview
+func TestScrapeRequest_ValidateDryRunLimit(t *testing.T) {
+	req := ScrapeRequest{Channel: "@test", DryRun: true}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Limit != DefaultPreviewLimit {
+		t.Errorf("Limit = %d, want %d", req.Limit, DefaultPreviewLimit)
+	}
+
+	req = ScrapeRequest{Channel: "@test", DryRun: true, Limit: 10}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Limit != 10 {
+		t.Errorf("Limit = %d, want 10", req.Limit)
+	}
+}
+
 // test until date parsing
 func TestScrapeRequest_UntilTime(t *testing.T) {
 	tests := []struct {
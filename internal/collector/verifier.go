@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// verifyBatchSize is the number of messages re-fetched per request (telegram api limit)
+const verifyBatchSize = 100
+
+// VerifyClient is the telegram side of job verification
+type VerifyClient interface {
+	ChannelResolver
+	GetDeletedMessages(ctx context.Context, channel *telegram.Channel, ids []int) ([]int, error)
+	GetStatus() telegram.Status
+}
+
+// OpenJobStore lists open jobs of a target and closes the ones of deleted messages
+type OpenJobStore interface {
+	ListOpenMessageIDs(ctx context.Context, targetID uuid.UUID, afterID int64, limit int) ([]int64, error)
+	CloseByMessageIDs(ctx context.Context, targetID uuid.UUID, msgIDs []int64) (int, error)
+}
+
+// VerifyResult contains verification statistics of a target
+type VerifyResult struct {
+	TargetID uuid.UUID `json:"target_id"`
+	Checked  int       `json:"checked"`
+	Closed   int       `json:"closed"`
+}
+
+// Verifier periodically re-fetches the messages of open jobs and closes
+// the jobs whose message was deleted (usually the vacancy is filled).
+// messages are fetched in batches, sharing the telegram rate limiter with scrapes.
+type Verifier struct {
+	jobs    OpenJobStore
+	targets TargetLister
+	tg      VerifyClient
+	every   time.Duration
+	log     *logger.Logger
+
+	lastRun time.Time
+}
+
+// NewVerifier creates a verifier that checks all active telegram targets every interval
+func NewVerifier(jobs OpenJobStore, targets TargetLister, tg VerifyClient, every time.Duration, log *logger.Logger) *Verifier {
+	if every <= 0 {
+		every = 24 * time.Hour
+	}
+	return &Verifier{
+		jobs:    jobs,
+		targets: targets,
+		tg:      tg,
+		every:   every,
+		log:     log,
+	}
+}
+
+// Run verifies targets every interval until ctx is cancelled.
+// a pass waits until telegram is ready.
+func (v *Verifier) Run(ctx context.Context) {
+	v.log.Info().Dur("every", v.every).Msg("verifier: started")
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			v.log.Info().Msg("verifier: stopped")
+			return
+		case now := <-ticker.C:
+			v.tick(ctx, now)
+		}
+	}
+}
+
+// tick runs a verification pass when due and telegram is ready
+func (v *Verifier) tick(ctx context.Context, now time.Time) {
+	if now.Sub(v.lastRun) < v.every || v.tg.GetStatus() != telegram.StatusReady {
+		return
+	}
+	v.lastRun = now
+	v.VerifyAll(ctx)
+}
+
+// VerifyAll verifies the open jobs of every active telegram target
+func (v *Verifier) VerifyAll(ctx context.Context) []VerifyResult {
+	targets, err := v.targets.GetActive(ctx)
+	if err != nil {
+		v.log.Error().Err(err).Msg("verifier: failed to list active targets")
+		return nil
+	}
+
+	var results []VerifyResult
+	for _, t := range targets {
+		if !t.IsTelegram() {
+			continue
+		}
+		result, err := v.VerifyTarget(ctx, t)
+		if ctx.Err() != nil {
+			return results
+		}
+		if err != nil {
+			v.log.Warn().Err(err).Str("target_id", t.ID.String()).Msg("verifier: failed to verify target")
+			continue
+		}
+		if result.Closed > 0 {
+			v.log.Info().
+				Str("target_id", t.ID.String()).
+				Int("checked", result.Checked).
+				Int("closed", result.Closed).
+				Msg("verifier: closed jobs of deleted messages")
+		}
+		results = append(results, *result)
+	}
+	return results
+}
+
+// VerifyTarget re-fetches the messages of the open jobs of a target in batches
+// and closes the jobs whose message was deleted
+func (v *Verifier) VerifyTarget(ctx context.Context, target repository.ScrapingTarget) (*VerifyResult, error) {
+	result := &VerifyResult{TargetID: target.ID}
+
+	channel, err := resolveChannel(ctx, v.tg, &target, false)
+	if err != nil {
+		return nil, fmt.Errorf("resolve channel: %w", err)
+	}
+
+	var afterID int64
+	for {
+		msgIDs, err := v.jobs.ListOpenMessageIDs(ctx, target.ID, afterID, verifyBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(msgIDs) == 0 {
+			return result, nil
+		}
+		afterID = msgIDs[len(msgIDs)-1]
+
+		ids := make([]int, len(msgIDs))
+		for i, id := range msgIDs {
+			ids[i] = int(id)
+		}
+		deleted, err := v.tg.GetDeletedMessages(ctx, channel, ids)
+		if err != nil {
+			return result, fmt.Errorf("get messages: %w", err)
+		}
+		result.Checked += len(ids)
+
+		if len(deleted) > 0 {
+			deletedIDs := make([]int64, len(deleted))
+			for i, id := range deleted {
+				deletedIDs[i] = int64(id)
+			}
+			closed, err := v.jobs.CloseByMessageIDs(ctx, target.ID, deletedIDs)
+			if err != nil {
+				return result, err
+			}
+			result.Closed += closed
+		}
+
+		if len(msgIDs) < verifyBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
+	"github.com/google/uuid"
+)
+
+// mockVerifyClient reports a fixed set of message ids as deleted
+type mockVerifyClient struct {
+	status  telegram.Status
+	deleted map[int]bool
+	batches [][]int
+}
+
+func (m *mockVerifyClient) ResolveChannel(ctx context.Context, username string) (*telegram.Channel, error) {
+	return &telegram.Channel{ID: 100, Username: username}, nil
+}
+
+func (m *mockVerifyClient) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error) {
+	return &telegram.Channel{ID: id, AccessHash: accessHash}, nil
+}
+
+func (m *mockVerifyClient) JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return &telegram.Channel{ID: 100}, nil
+}
+
+func (m *mockVerifyClient) CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return &telegram.Channel{ID: 100}, nil
+}
+
+func (m *mockVerifyClient) GetDeletedMessages(ctx context.Context, channel *telegram.Channel, ids []int) ([]int, error) {
+	m.batches = append(m.batches, ids)
+	var deleted []int
+	for _, id := range ids {
+		if m.deleted[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockVerifyClient) GetStatus() telegram.Status {
+	return m.status
+}
+
+// mockOpenJobStore keeps open message ids per target
+type mockOpenJobStore struct {
+	open map[uuid.UUID][]int64
+}
+
+func (m *mockOpenJobStore) ListOpenMessageIDs(ctx context.Context, targetID uuid.UUID, afterID int64, limit int) ([]int64, error) {
+	var ids []int64
+	for _, id := range m.open[targetID] {
+		if id > afterID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockOpenJobStore) CloseByMessageIDs(ctx context.Context, targetID uuid.UUID, msgIDs []int64) (int, error) {
+	closed := make(map[int64]bool, len(msgIDs))
+	for _, id := range msgIDs {
+		closed[id] = true
+	}
+	var open []int64
+	for _, id := range m.open[targetID] {
+		if !closed[id] {
+			open = append(open, id)
+		}
+	}
+	n := len(m.open[targetID]) - len(open)
+	m.open[targetID] = open
+	return n, nil
+}
+
+func TestVerifier(t *testing.T) {
+	channel := liveTestTarget("@golang_jobs", "TG_CHANNEL")
+	hh := liveTestTarget("https://hh.ru/search", "HH_SEARCH")
+	targets := &mockTargetLister{targets: []repository.ScrapingTarget{channel, hh}}
+
+	newStore := func() *mockOpenJobStore {
+		var ids []int64
+		for id := int64(1); id <= 250; id++ {
+			ids = append(ids, id)
+		}
+		return &mockOpenJobStore{open: map[uuid.UUID][]int64{channel.ID: ids}}
+	}
+
+	t.Run("closes jobs of deleted messages in batches", func(t *testing.T) {
+		store := newStore()
+		tg := &mockVerifyClient{status: telegram.StatusReady, deleted: map[int]bool{3: true, 150: true, 250: true}}
+		v := NewVerifier(store, targets, tg, time.Hour, logger.Get())
+
+		results := v.VerifyAll(context.Background())
+
+		if len(results) != 1 || results[0].TargetID != channel.ID {
+			t.Fatalf("expected a result for the telegram target only, got %+v", results)
+		}
+		if results[0].Checked != 250 || results[0].Closed != 3 {
+			t.Errorf("got checked=%d closed=%d, want 250 and 3", results[0].Checked, results[0].Closed)
+		}
+		if len(tg.batches) != 3 || len(tg.batches[0]) != verifyBatchSize || len(tg.batches[2]) != 50 {
+			t.Errorf("expected batches of 100, 100, 50, got %d batches", len(tg.batches))
+		}
+		if len(store.open[channel.ID]) != 247 {
+			t.Errorf("expected 247 open jobs left, got %d", len(store.open[channel.ID]))
+		}
+	})
+
+	t.Run("runs once per interval when telegram is ready", func(t *testing.T) {
+		tg := &mockVerifyClient{status: telegram.StatusUnauthorized}
+		v := NewVerifier(newStore(), targets, tg, time.Hour, logger.Get())
+		now := time.Now()
+
+		v.tick(context.Background(), now)
+		if len(tg.batches) != 0 {
+			t.Fatal("expected no verification while telegram is not ready")
+		}
+
+		tg.status = telegram.StatusReady
+		v.tick(context.Background(), now)
+		v.tick(context.Background(), now.Add(30*time.Minute))
+		if len(tg.batches) != 3 {
+			t.Errorf("expected a single pass within the interval, got %d batches", len(tg.batches))
+		}
+
+		v.tick(context.Background(), now.Add(time.Hour))
+		if len(tg.batches) != 6 {
+			t.Errorf("expected a second pass after the interval, got %d batches", len(tg.batches))
+		}
+	})
+}
@@ -26,6 +26,39 @@ type Config struct {
 	TGApiID   int
 	TGApiHash string
 
+	// scheduler
+	SchedulerEnabled     bool
+	SchedulerTickSeconds int
+
+	// scrape queue
+	ScrapeConcurrency int
+
+	// consecutive failed runs after which a target is deactivated (0 = never)
+	TargetMaxFailures int
+
+	// live ingestion from telegram updates
+	LiveUpdatesEnabled bool
+	LiveCatchUpMinutes int
+
+	// near-duplicate job clustering
+	JobClusterDays        int
+	JobClusterMaxDistance int
+
+	// verification of jobs against deleted source messages
+	VerifyEnabled       bool
+	VerifyIntervalHours int
+
+	// hh.ru vacancies api (HH_SEARCH targets)
+	HHAPIURL    string
+	HHUserAgent string
+
+	// rss/atom and json feeds (FEED targets)
+	FeedUserAgent string
+
+	// documents attached to telegram posts
+	AttachmentsDir  string
+	AttachmentMaxMB int
+
 	// server
 	HTTPPort  int
 	StaticDir string
@@ -53,6 +86,22 @@ func Load() (*Config, error) {
 		TGApiID:   getEnvInt("TG_API_ID", 0),
 	}
 
+	cfg.SchedulerEnabled = getEnvBool("SCHEDULER_ENABLED", true)
+	cfg.SchedulerTickSeconds = getEnvInt("SCHEDULER_TICK_SECONDS", 60)
+	cfg.ScrapeConcurrency = getEnvInt("SCRAPE_CONCURRENCY", 1)
+	cfg.TargetMaxFailures = getEnvInt("TARGET_MAX_FAILURES", 5)
+	cfg.LiveUpdatesEnabled = getEnvBool("LIVE_UPDATES_ENABLED", true)
+	cfg.LiveCatchUpMinutes = getEnvInt("LIVE_CATCHUP_MINUTES", 15)
+	cfg.JobClusterDays = getEnvInt("JOB_CLUSTER_DAYS", 14)
+	cfg.JobClusterMaxDistance = getEnvInt("JOB_CLUSTER_MAX_DISTANCE", 8)
+	cfg.VerifyEnabled = getEnvBool("VERIFY_ENABLED", true)
+	cfg.VerifyIntervalHours = getEnvInt("VERIFY_INTERVAL_HOURS", 24)
+	cfg.HHAPIURL = getEnv("HH_API_URL", "https://api.hh.ru")
+	cfg.HHUserAgent = getEnv("HH_USER_AGENT", "positions-os/1.0")
+	cfg.FeedUserAgent = getEnv("FEED_USER_AGENT", "positions-os/1.0")
+	cfg.AttachmentsDir = getEnv("ATTACHMENTS_DIR", "./storage/attachments")
+	cfg.AttachmentMaxMB = getEnvInt("ATTACHMENT_MAX_MB", 20)
+
 	// float parsing helper
 	cfg.LLMTemperature = getEnvFloat("LLM_TEMPERATURE", 0.1)
 
@@ -77,6 +126,16 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+// getEnvBool returns the boolean value of an environment variable or a default.
+func getEnvBool(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
 func getEnvFloat(key string, defaultVal float64) float64 {
 	if val := os.Getenv(key); val != "" {
 		if f, err := strconv.ParseFloat(val, 64); err == nil {
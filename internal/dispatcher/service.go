@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/blockedby/positions-os/internal/logger"
 	"github.com/blockedby/positions-os/internal/models"
+	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/google/uuid"
 )
 
@@ -23,9 +25,16 @@ type DispatcherService struct {
 	emailSender EmailSenderInterface
 	tracker     DeliveryTrackerInterface
 	repo        ApplicationsRepository
+	jobs        JobsRepository
 	log         *logger.Logger
 }
 
+// JobsRepository looks up the job of an application, its contacts
+// are the default recipients.
+type JobsRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*repository.Job, error)
+}
+
 // EmailSenderInterface defines the interface for sending applications via email.
 // This is a stub from Thread A (Task E.1).
 type EmailSenderInterface interface {
@@ -49,11 +58,17 @@ func NewDispatcherService(
 	}
 }
 
+// SetJobs enables choosing the recipient from the job contacts
+// when a request has none.
+func (s *DispatcherService) SetJobs(jobs JobsRepository) {
+	s.jobs = jobs
+}
+
 // SendRequest represents a request to send a job application.
 type SendRequest struct {
 	JobID     uuid.UUID `json:"job_id"`
 	Channel   string    `json:"channel"`   // "TG_DM" or "EMAIL"
-	Recipient string    `json:"recipient"`
+	Recipient string    `json:"recipient"` // empty: chosen from the job contacts
 }
 
 // SendApplication routes the send request to the appropriate sender based on channel.
@@ -66,7 +81,11 @@ func (s *DispatcherService) SendApplication(ctx context.Context, req *SendReques
 		return errors.New("job ID cannot be empty")
 	}
 	if req.Recipient == "" {
-		return errors.New("recipient cannot be empty")
+		recipient, err := s.jobRecipient(ctx, req.JobID, req.Channel)
+		if err != nil {
+			return err
+		}
+		req.Recipient = recipient
 	}
 
 	// Route based on channel
@@ -102,6 +121,46 @@ func (s *DispatcherService) SendViaTelegram(ctx context.Context, jobID uuid.UUID
 	return s.tgSender.SendApplication(ctx, app.ID, recipient)
 }
 
+// jobRecipient chooses the recipient among the contacts of the job
+func (s *DispatcherService) jobRecipient(ctx context.Context, jobID uuid.UUID, channel string) (string, error) {
+	if s.jobs == nil {
+		return "", errors.New("recipient cannot be empty")
+	}
+	job, err := s.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		return "", fmt.Errorf("get job: %w", err)
+	}
+	if job == nil {
+		return "", fmt.Errorf("job not found: %s", jobID)
+	}
+
+	recipient := ChooseRecipient(channel, job.Contacts())
+	if recipient == "" {
+		return "", fmt.Errorf("recipient cannot be empty: job has no %s contact", channel)
+	}
+	return recipient, nil
+}
+
+// ChooseRecipient returns the first contact the channel can deliver to:
+// a @username for TG_DM, an email address for EMAIL. contacts come in
+// order of preference (post links first, see repository.Job.Contacts).
+func ChooseRecipient(channel string, contacts []string) string {
+	for _, c := range contacts {
+		c = strings.TrimSpace(c)
+		switch channel {
+		case "TG_DM":
+			if strings.HasPrefix(c, "@") && len(c) > 1 {
+				return c
+			}
+		case "EMAIL":
+			if at := strings.Index(c, "@"); at > 0 && at < len(c)-1 && !strings.ContainsAny(c, " /") {
+				return strings.TrimPrefix(c, "mailto:")
+			}
+		}
+	}
+	return ""
+}
+
 // Helper function to get pointer to DeliveryChannel
 func deliveryChannelPtr(c models.DeliveryChannel) *models.DeliveryChannel {
 	return &c
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/blockedby/positions-os/internal/logger"
+	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -158,6 +159,74 @@ func TestSendApplication_ValidatesRequest(t *testing.T) {
 	}
 }
 
+// TestSendApplication_RecipientFromJobContacts tests the recipient is chosen from the job contacts.
+func TestSendApplication_RecipientFromJobContacts(t *testing.T) {
+	jobID := uuid.New()
+	jobs := &mockJobsRepository{jobs: map[uuid.UUID]*repository.Job{
+		jobID: {
+			ID:             jobID,
+			Links:          []repository.Link{{Type: repository.LinkEmail, URL: "hr@example.com"}},
+			StructuredData: map[string]interface{}{"contacts": []interface{}{"@recruiter", "+7 900 000-00-00"}},
+		},
+	}}
+
+	var sentTo string
+	service := NewDispatcherService(&mockTelegramSenderForService{
+		sendApplicationFunc: func(ctx context.Context, appID uuid.UUID, recipient string) error {
+			sentTo = recipient
+			return nil
+		},
+	}, &mockEmailSenderForService{}, &mockDeliveryTracker{}, &mockApplicationsRepository{}, logger.Get())
+	service.SetJobs(jobs)
+
+	req := &SendRequest{JobID: jobID, Channel: "TG_DM"}
+	require.NoError(t, service.SendApplication(context.Background(), req))
+	assert.Equal(t, "@recruiter", sentTo)
+	assert.Equal(t, "@recruiter", req.Recipient)
+
+	err := service.SendApplication(context.Background(), &SendRequest{JobID: uuid.New(), Channel: "TG_DM"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job not found")
+
+	jobs.jobs[jobID].StructuredData = nil
+	err = service.SendApplication(context.Background(), &SendRequest{JobID: jobID, Channel: "TG_DM"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recipient cannot be empty")
+}
+
+// TestChooseRecipient tests the contact picked for each channel.
+func TestChooseRecipient(t *testing.T) {
+	contacts := []string{"https://example.com/apply", "+7 900 000-00-00", "hr@example.com", "@hr_anna", "@team_lead"}
+
+	tests := []struct {
+		name     string
+		channel  string
+		contacts []string
+		want     string
+	}{
+		{name: "first username for telegram", channel: "TG_DM", contacts: contacts, want: "@hr_anna"},
+		{name: "first email for email", channel: "EMAIL", contacts: contacts, want: "hr@example.com"},
+		{name: "mailto prefix dropped", channel: "EMAIL", contacts: []string{"mailto:cv@example.com"}, want: "cv@example.com"},
+		{name: "no matching contact", channel: "TG_DM", contacts: []string{"hr@example.com"}, want: ""},
+		{name: "unknown channel", channel: "HH", contacts: contacts, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ChooseRecipient(tt.channel, tt.contacts))
+		})
+	}
+}
+
+// Mock JobsRepository for testing
+type mockJobsRepository struct {
+	jobs map[uuid.UUID]*repository.Job
+}
+
+func (m *mockJobsRepository) GetByID(ctx context.Context, id uuid.UUID) (*repository.Job, error) {
+	return m.jobs[id], nil
+}
+
 // Mock TelegramSender for testing - implements interface that can be used by service
 type mockTelegramSenderForService struct {
 	sendApplicationFunc func(ctx context.Context, appID uuid.UUID, recipient string) error
@@ -0,0 +1,65 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// testDOCX builds a docx archive with the given word/document.xml body
+func testDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`,
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			body + `</w:body></w:document>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestText_DOCX(t *testing.T) {
+	t.Run("paragraphs, runs, tabs and breaks", func(t *testing.T) {
+		data := testDOCX(t,
+			`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Golang</w:t></w:r><w:r><w:t xml:space="preserve"> разработчик</w:t></w:r></w:p>`+
+				`<w:p/>`+
+				`<w:p><w:r><w:t>Salary:</w:t><w:tab/><w:t>300k</w:t><w:br/><w:t>Remote &amp; office</w:t></w:r></w:p>`)
+
+		text, err := Text("job.docx", "", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "Golang разработчик\n\nSalary: 300k\nRemote & office"
+		if text != want {
+			t.Errorf("got %q, want %q", text, want)
+		}
+	})
+
+	t.Run("not a zip archive", func(t *testing.T) {
+		if _, err := Text("job.docx", "", []byte("plain text")); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("archive without document body", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		zw.Create("word/styles.xml")
+		zw.Close()
+		if _, err := Text("job.docx", "", buf.Bytes()); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Package extract pulls plain text out of documents attached to vacancy posts:
+// PDF, DOCX and plain text files.
+package extract
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ErrUnsupported is returned for documents of other formats
+var ErrUnsupported = errors.New("unsupported document format")
+
+// document formats
+const (
+	FormatPDF  = "pdf"
+	FormatDOCX = "docx"
+	FormatTXT  = "txt"
+)
+
+// Format detects the document format by mime type, then by file name.
+// empty for unsupported documents.
+func Format(fileName, mimeType string) string {
+	switch strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0])) {
+	case "application/pdf":
+		return FormatPDF
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return FormatDOCX
+	case "text/plain":
+		return FormatTXT
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".pdf":
+		return FormatPDF
+	case ".docx":
+		return FormatDOCX
+	case ".txt":
+		return FormatTXT
+	}
+	return ""
+}
+
+// Supported reports whether text can be extracted from the document
+func Supported(fileName, mimeType string) bool {
+	return Format(fileName, mimeType) != ""
+}
+
+// Text extracts the text of a document.
+// lines are trimmed and runs of blank lines collapsed.
+func Text(fileName, mimeType string, data []byte) (string, error) {
+	var (
+		text string
+		err  error
+	)
+	switch Format(fileName, mimeType) {
+	case FormatPDF:
+		text, err = pdfText(data)
+	case FormatDOCX:
+		text, err = docxText(data)
+	case FormatTXT:
+		text = plainText(data)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+	return tidy(text), nil
+}
+
+// plainText decodes a text file: utf-8 (with or without BOM),
+// anything else is read as windows-1251
+func plainText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	return string(decoded)
+}
+
+// tidy trims lines, collapses inner whitespace and blank line runs
+func tidy(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
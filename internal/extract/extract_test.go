@@ -0,0 +1,60 @@
+package extract
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		fileName, mimeType, want string
+	}{
+		{"vacancy.pdf", "application/pdf", FormatPDF},
+		{"", "application/pdf", FormatPDF},
+		{"job.docx", "application/octet-stream", FormatDOCX},
+		{"", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", FormatDOCX},
+		{"JOB.TXT", "", FormatTXT},
+		{"", "text/plain; charset=utf-8", FormatTXT},
+		{"job.doc", "application/msword", ""},
+		{"photo.jpg", "image/jpeg", ""},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.fileName, tt.mimeType); got != tt.want {
+			t.Errorf("Format(%q, %q) = %q, want %q", tt.fileName, tt.mimeType, got, tt.want)
+		}
+		if got := Supported(tt.fileName, tt.mimeType); got != (tt.want != "") {
+			t.Errorf("Supported(%q, %q) = %v", tt.fileName, tt.mimeType, got)
+		}
+	}
+}
+
+func TestText_Plain(t *testing.T) {
+	t.Run("utf-8 with BOM, whitespace tidied", func(t *testing.T) {
+		data := []byte("\xef\xbb\xbf  Go   developer \r\n\r\n\r\n Remote\t300k\n\n")
+		text, err := Text("job.txt", "", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "Go developer\n\nRemote 300k" {
+			t.Errorf("got %q", text)
+		}
+	})
+
+	t.Run("windows-1251", func(t *testing.T) {
+		// "Вакансия Go" in windows-1251
+		data := []byte{0xc2, 0xe0, 0xea, 0xe0, 0xed, 0xf1, 0xe8, 0xff, ' ', 'G', 'o'}
+		text, err := Text("job.txt", "text/plain", data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "Вакансия Go" {
+			t.Errorf("got %q", text)
+		}
+	})
+}
+
+func TestText_Unsupported(t *testing.T) {
+	if _, err := Text("photo.jpg", "image/jpeg", []byte{0xff, 0xd8}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,737 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdf text extraction: pages are walked in page tree order, their content
+// streams are run through the text operators (Tj, TJ, ', ") and glyph codes
+// are mapped to text with the ToUnicode cmaps of the page fonts.
+// scanned pages (images only) give no text.
+
+var (
+	pdfObjHeader   = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfStreamStart = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfRef         = regexp.MustCompile(`^(\d+)\s+\d+\s+R\b`)
+	pdfRefs        = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	pdfNamedRef    = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	pdfPageType    = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfPagesType   = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfObjStmType  = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+)
+
+// pdfObject is an indirect object: its dictionary (or whole body)
+// and the decoded stream, if any
+type pdfObject struct {
+	dict   []byte
+	stream []byte
+}
+
+// pdfFile is a parsed pdf document
+type pdfFile struct {
+	objects map[int]*pdfObject
+	cmaps   map[int]*pdfCMap // by font object number
+}
+
+// pdfText extracts the text of all pages
+func pdfText(data []byte) (string, error) {
+	f, err := parsePDF(data)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, page := range f.pages() {
+		content, fonts := f.pageContent(page)
+		if len(content) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		writeContentText(&b, content, fonts)
+	}
+	return b.String(), nil
+}
+
+// parsePDF reads the objects of a pdf file, including the ones packed into
+// object streams
+func parsePDF(data []byte) (*pdfFile, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return nil, errors.New("pdf: not a pdf file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, errors.New("pdf: encrypted documents are not supported")
+	}
+
+	f := &pdfFile{objects: make(map[int]*pdfObject), cmaps: make(map[int]*pdfCMap)}
+	headers := pdfObjHeader.FindAllSubmatchIndex(data, -1)
+	for i, loc := range headers {
+		num, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		if err != nil {
+			continue
+		}
+		end := len(data)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := data[loc[1]:end]
+
+		obj := &pdfObject{dict: body}
+		if s := pdfStreamStart.FindIndex(body); s != nil {
+			obj.dict = body[:s[0]+2]
+			raw := body[s[1]:]
+			if e := bytes.LastIndex(raw, []byte("endstream")); e >= 0 {
+				raw = raw[:e]
+			}
+			obj.stream = decodeStream(obj.dict, raw)
+		} else if e := bytes.Index(body, []byte("endobj")); e >= 0 {
+			obj.dict = body[:e]
+		}
+		// later definitions (incremental updates) replace earlier ones
+		f.objects[num] = obj
+	}
+
+	for _, obj := range f.sortedObjects() {
+		if pdfObjStmType.Match(obj.dict) && obj.stream != nil {
+			f.unpackObjectStream(obj)
+		}
+	}
+	return f, nil
+}
+
+// sortedObjects returns the objects ordered by object number
+func (f *pdfFile) sortedObjects() []*pdfObject {
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	objects := make([]*pdfObject, len(nums))
+	for i, num := range nums {
+		objects[i] = f.objects[num]
+	}
+	return objects
+}
+
+// unpackObjectStream adds the objects compressed into an object stream
+func (f *pdfFile) unpackObjectStream(stm *pdfObject) {
+	n, _ := strconv.Atoi(string(dictValue(stm.dict, "N")))
+	first, _ := strconv.Atoi(string(dictValue(stm.dict, "First")))
+	if n <= 0 || first <= 0 || first > len(stm.stream) {
+		return
+	}
+
+	header := strings.Fields(string(stm.stream[:first]))
+	if len(header) < 2*n {
+		return
+	}
+	for i := 0; i < n; i++ {
+		num, err1 := strconv.Atoi(header[2*i])
+		off, err2 := strconv.Atoi(header[2*i+1])
+		if err1 != nil || err2 != nil || first+off > len(stm.stream) {
+			continue
+		}
+		end := len(stm.stream)
+		if i+1 < n {
+			if next, err := strconv.Atoi(header[2*i+3]); err == nil && first+next <= end && next >= off {
+				end = first + next
+			}
+		}
+		if _, ok := f.objects[num]; !ok {
+			f.objects[num] = &pdfObject{dict: stm.stream[first+off : end]}
+		}
+	}
+}
+
+// decodeStream decodes a FlateDecode or unfiltered stream.
+// streams with other filters (images) are dropped.
+func decodeStream(dict, raw []byte) []byte {
+	filter := dictValue(dict, "Filter")
+	if len(filter) == 0 {
+		return raw
+	}
+	if !bytes.Contains(filter, []byte("FlateDecode")) || bytes.Count(filter, []byte("/")) > 1 {
+		return nil
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	// a truncated stream still gives the text read so far
+	decoded, _ := io.ReadAll(r)
+	return decoded
+}
+
+// pages returns the page objects in page tree order
+func (f *pdfFile) pages() []int {
+	var roots []int
+	for num, obj := range f.objects {
+		if pdfPagesType.Match(obj.dict) && len(dictValue(obj.dict, "Parent")) == 0 {
+			roots = append(roots, num)
+		}
+	}
+	sort.Ints(roots)
+
+	var pages []int
+	seen := make(map[int]bool)
+	var walk func(num int)
+	walk = func(num int) {
+		obj, ok := f.objects[num]
+		if !ok || seen[num] {
+			return
+		}
+		seen[num] = true
+		if pdfPagesType.Match(obj.dict) {
+			for _, kid := range refs(f.resolve(dictValue(obj.dict, "Kids"))) {
+				walk(kid)
+			}
+			return
+		}
+		if pdfPageType.Match(obj.dict) {
+			pages = append(pages, num)
+		}
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	// no page tree: pages in object order
+	for num, obj := range f.objects {
+		if pdfPageType.Match(obj.dict) {
+			pages = append(pages, num)
+		}
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+// pageContent returns the content streams of a page and its fonts by resource name
+func (f *pdfFile) pageContent(page int) ([]byte, map[string]*pdfCMap) {
+	obj := f.objects[page]
+
+	// a single content stream, or an array of them (maybe an indirect one)
+	contents := dictValue(obj.dict, "Contents")
+	if ref := pdfRef.FindSubmatch(contents); ref != nil {
+		num, _ := strconv.Atoi(string(ref[1]))
+		if c, ok := f.objects[num]; ok && c.stream == nil {
+			contents = bytes.TrimSpace(c.dict)
+		}
+	}
+
+	var content []byte
+	for _, num := range refs(contents) {
+		if c, ok := f.objects[num]; ok && c.stream != nil {
+			content = append(content, c.stream...)
+			content = append(content, '\n')
+		}
+	}
+
+	// resources are inherited from the parent pages
+	resources := f.resolve(dictValue(obj.dict, "Resources"))
+	for parent, depth := obj, 0; len(resources) == 0 && depth < 32; depth++ {
+		ref := pdfRef.FindSubmatch(dictValue(parent.dict, "Parent"))
+		if ref == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(ref[1]))
+		if parent = f.objects[num]; parent == nil {
+			break
+		}
+		resources = f.resolve(dictValue(parent.dict, "Resources"))
+	}
+
+	fonts := make(map[string]*pdfCMap)
+	for _, m := range pdfNamedRef.FindAllSubmatch(f.resolve(dictValue(resources, "Font")), -1) {
+		num, _ := strconv.Atoi(string(m[2]))
+		fonts[string(m[1])] = f.fontCMap(num)
+	}
+	return content, fonts
+}
+
+// fontCMap returns the ToUnicode cmap of a font, nil if it has none
+func (f *pdfFile) fontCMap(num int) *pdfCMap {
+	if cm, ok := f.cmaps[num]; ok {
+		return cm
+	}
+	var cm *pdfCMap
+	if font, ok := f.objects[num]; ok {
+		if ref := pdfRef.FindSubmatch(dictValue(font.dict, "ToUnicode")); ref != nil {
+			n, _ := strconv.Atoi(string(ref[1]))
+			if stream, ok := f.objects[n]; ok && stream.stream != nil {
+				cm = parseCMap(stream.stream)
+			}
+		}
+		if cm != nil && cm.width == 0 {
+			cm.width = 1
+			if bytes.Contains(dictValue(font.dict, "Subtype"), []byte("Type0")) {
+				cm.width = 2
+			}
+		}
+	}
+	f.cmaps[num] = cm
+	return cm
+}
+
+// resolve returns the dictionary of a referenced object, or the value itself
+func (f *pdfFile) resolve(value []byte) []byte {
+	if ref := pdfRef.FindSubmatch(value); ref != nil {
+		num, _ := strconv.Atoi(string(ref[1]))
+		if obj, ok := f.objects[num]; ok {
+			return bytes.TrimSpace(obj.dict)
+		}
+		return nil
+	}
+	return value
+}
+
+// refs returns the object numbers referenced by a value
+func refs(value []byte) []int {
+	var nums []int
+	for _, m := range pdfRefs.FindAllSubmatch(value, -1) {
+		if num, err := strconv.Atoi(string(m[1])); err == nil {
+			nums = append(nums, num)
+		}
+	}
+	return nums
+}
+
+// dictValue returns the raw value of the first /key entry of a dictionary:
+// a reference, a nested dictionary or array, or a single token
+func dictValue(dict []byte, key string) []byte {
+	name := []byte("/" + key)
+	for from := 0; ; {
+		i := bytes.Index(dict[from:], name)
+		if i < 0 {
+			return nil
+		}
+		start := from + i + len(name)
+		from = start
+		if start < len(dict) && !isPDFDelimiter(dict[start]) && !isPDFSpace(dict[start]) {
+			continue // longer name with the same prefix
+		}
+		return readValue(bytes.TrimLeft(dict[start:], " \t\r\n"))
+	}
+}
+
+// readValue returns the raw bytes of the value at the start of b
+func readValue(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	if ref := pdfRef.Find(b); ref != nil {
+		return ref
+	}
+	switch {
+	case bytes.HasPrefix(b, []byte("<<")):
+		return balanced(b, "<<", ">>")
+	case bytes.HasPrefix(b, []byte("[")):
+		return balanced(b, "[", "]")
+	}
+	end := 1
+	for end < len(b) && !isPDFSpace(b[end]) && !isPDFDelimiter(b[end]) {
+		end++
+	}
+	return b[:end]
+}
+
+// balanced returns b up to the close token matching its leading open token
+func balanced(b []byte, open, close string) []byte {
+	depth := 0
+	for i := 0; i < len(b); {
+		switch {
+		case bytes.HasPrefix(b[i:], []byte(open)):
+			depth++
+			i += len(open)
+		case bytes.HasPrefix(b[i:], []byte(close)):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return b[:i]
+			}
+		default:
+			i++
+		}
+	}
+	return b
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// pdfCMap maps glyph codes of a font to text
+type pdfCMap struct {
+	width int // code length in bytes
+	chars map[int]string
+}
+
+// parseCMap reads the codespace and bfchar/bfrange mappings of a ToUnicode cmap
+func parseCMap(data []byte) *pdfCMap {
+	cm := &pdfCMap{chars: make(map[int]string)}
+	lex := &pdfLexer{b: data}
+	var operands []pdfToken
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch string(tok.value) {
+		case "endcodespacerange":
+			if len(operands) > 0 && operands[0].kind == tokString && len(operands[0].value) > 0 {
+				cm.width = len(operands[0].value)
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				cm.chars[code(operands[i].value)] = utf16Text(operands[i+1].value)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, hi := code(operands[i].value), code(operands[i+1].value)
+				if hi < lo || hi-lo > 0xffff {
+					continue
+				}
+				dst := operands[i+2]
+				if dst.kind == tokArray {
+					for j, item := range dst.items {
+						if lo+j > hi {
+							break
+						}
+						cm.chars[lo+j] = utf16Text(item.value)
+					}
+					continue
+				}
+				base := []rune(utf16Text(dst.value))
+				if len(base) == 0 {
+					continue
+				}
+				for c := lo; c <= hi; c++ {
+					r := make([]rune, len(base))
+					copy(r, base)
+					r[len(r)-1] += rune(c - lo)
+					cm.chars[c] = string(r)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return cm
+}
+
+// code reads a big-endian glyph code
+func code(b []byte) int {
+	c := 0
+	for _, x := range b {
+		c = c<<8 | int(x)
+	}
+	return c
+}
+
+// utf16Text decodes big-endian utf-16
+func utf16Text(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// decode maps the bytes of a shown string to text
+func (cm *pdfCMap) decode(s []byte) string {
+	if cm == nil || len(cm.chars) == 0 {
+		if bytes.HasPrefix(s, []byte{0xfe, 0xff}) {
+			return utf16Text(s[2:])
+		}
+		r := make([]rune, len(s))
+		for i, c := range s {
+			r[i] = rune(c) // latin-1 for simple fonts without a cmap
+		}
+		return string(r)
+	}
+
+	var b strings.Builder
+	for i := 0; i+cm.width <= len(s); i += cm.width {
+		c := code(s[i : i+cm.width])
+		if text, ok := cm.chars[c]; ok {
+			b.WriteString(text)
+		} else if cm.width == 1 {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// writeContentText runs the text operators of a content stream
+func writeContentText(b *strings.Builder, content []byte, fonts map[string]*pdfCMap) {
+	var (
+		font     *pdfCMap
+		operands []pdfToken
+		lineY    float64
+		hasLine  bool
+	)
+	newline := func() { b.WriteByte('\n') }
+	space := func() { b.WriteByte(' ') }
+
+	lex := &pdfLexer{b: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			return
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch op := string(tok.value); op {
+		case "Tf":
+			if len(operands) > 0 && operands[0].kind == tokName {
+				font = fonts[string(operands[0].value)]
+			}
+		case "Tj", "'", "\"":
+			if op != "Tj" {
+				newline()
+			}
+			if n := len(operands); n > 0 && operands[n-1].kind == tokString {
+				b.WriteString(font.decode(operands[n-1].value))
+			}
+		case "TJ":
+			if len(operands) > 0 && operands[0].kind == tokArray {
+				for _, item := range operands[0].items {
+					switch {
+					case item.kind == tokString:
+						b.WriteString(font.decode(item.value))
+					case item.kind == tokNumber && item.number < -250:
+						space() // wide kerning gap between words
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) == 2 && operands[1].number != 0 {
+				newline()
+			} else {
+				space()
+			}
+		case "T*":
+			newline()
+		case "Tm":
+			if len(operands) == 6 {
+				y := operands[5].number
+				if !hasLine || y != lineY {
+					newline()
+				} else {
+					space()
+				}
+				lineY, hasLine = y, true
+			}
+		case "ET":
+			space()
+		case "ID":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// pdf content stream tokens
+const (
+	tokNumber = iota
+	tokString
+	tokName
+	tokArray
+	tokDict
+	tokOperator
+)
+
+type pdfToken struct {
+	kind   int
+	value  []byte // string bytes, name, operator
+	number float64
+	items  []pdfToken // array items
+}
+
+// pdfLexer splits a content stream or cmap into tokens
+type pdfLexer struct {
+	b   []byte
+	pos int
+}
+
+func (l *pdfLexer) next() (pdfToken, bool) {
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return pdfToken{kind: tokString, value: l.literal()}, true
+		case c == '<' && l.pos+1 < len(l.b) && l.b[l.pos+1] == '<':
+			end := len(balanced(l.b[l.pos:], "<<", ">>"))
+			l.pos += end
+			return pdfToken{kind: tokDict}, true
+		case c == '<':
+			return pdfToken{kind: tokString, value: l.hex()}, true
+		case c == '[':
+			l.pos++
+			arr := pdfToken{kind: tokArray}
+			for {
+				tok, ok := l.next()
+				if !ok || (tok.kind == tokOperator && string(tok.value) == "]") {
+					return arr, true
+				}
+				arr.items = append(arr.items, tok)
+			}
+		case c == ']':
+			l.pos++
+			return pdfToken{kind: tokOperator, value: []byte("]")}, true
+		case c == '/':
+			l.pos++
+			return pdfToken{kind: tokName, value: l.regular()}, true
+		case c == '>' || c == ')' || c == '{' || c == '}':
+			l.pos++
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			word := l.regular()
+			n, err := strconv.ParseFloat(string(word), 64)
+			if err != nil {
+				return pdfToken{kind: tokOperator, value: word}, true
+			}
+			return pdfToken{kind: tokNumber, number: n}, true
+		default:
+			return pdfToken{kind: tokOperator, value: l.regular()}, true
+		}
+	}
+	return pdfToken{}, false
+}
+
+// regular reads a run of regular characters
+func (l *pdfLexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.b) && !isPDFSpace(l.b[l.pos]) && !isPDFDelimiter(l.b[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start && l.pos < len(l.b) {
+		l.pos++ // lone delimiter
+	}
+	return l.b[start:l.pos]
+}
+
+// literal reads a (string) with nested parentheses and escapes
+func (l *pdfLexer) literal() []byte {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			e := l.b[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+				// line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// hex reads a <hex string>
+func (l *pdfLexer) hex() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.b) && l.b[l.pos] != '>' {
+		c := l.b[l.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// skipInlineImage jumps over the binary data of an inline image (ID ... EI)
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos+2 < len(l.b) {
+		if isPDFSpace(l.b[l.pos]) && l.b[l.pos+1] == 'E' && l.b[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.b) || isPDFSpace(l.b[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.b)
+}
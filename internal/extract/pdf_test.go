@@ -0,0 +1,145 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testPDF builds a pdf from object bodies numbered from 1.
+// bodies with a stream are given as [dict, stream]; compressed streams get
+// /Filter /FlateDecode. no xref table: the extractor does not need it.
+type testPDF struct {
+	objects []string
+}
+
+func (p *testPDF) add(body string) int {
+	p.objects = append(p.objects, body)
+	return len(p.objects)
+}
+
+func (p *testPDF) addStream(dict, data string, compress bool) int {
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write([]byte(data))
+		w.Close()
+		data = buf.String()
+		dict += " /Filter /FlateDecode"
+	}
+	return p.add(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+}
+
+func (p *testPDF) bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i, body := range p.objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+// cyrillicCMap maps 2-byte glyph codes 0x0001.. to "Го разработчик"
+const cyrillicCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+3 beginbfchar
+<0001> <0413>
+<0002> <043E>
+<0003> <0020>
+endbfchar
+1 beginbfrange
+<0004> <0006> <0440>
+endbfrange
+1 beginbfrange
+<0007> <0008> [<0430> <0431>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func TestPDFText(t *testing.T) {
+	t.Run("pages in page tree order with inherited fonts", func(t *testing.T) {
+		p := &testPDF{}
+		p.add("<< /Type /Catalog /Pages 2 0 R >>")
+		p.add("<< /Type /Pages /Kids [4 0 R 3 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>")
+		p.add("<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>")
+		p.add("<< /Type /Page /Parent 2 0 R /Contents [9 0 R 10 0 R] >>")
+		p.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+		p.add("<< /Type /Font /Subtype /Type0 /BaseFont /Arial /ToUnicode 7 0 R >>")
+		p.addStream("", cyrillicCMap, true)
+		p.addStream("", "BT /F1 12 Tf 72 700 Td (Second page) Tj ET", false)
+		p.addStream("", "BT /F1 12 Tf 72 700 Td (Golang \\(Senior\\)) Tj 0 -14 Td [(Sal) 20 (ary) -300 (300k)] TJ T* (Remote) Tj ET", true)
+		p.addStream("", "BT /F2 12 Tf 1 0 0 1 72 600 Tm <000100020003000400050006> Tj 1 0 0 1 72 580 Tm <00070008> Tj ET", true)
+
+		text, err := Text("vacancy.pdf", "application/pdf", p.bytes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "Golang (Senior)\nSalary 300k\nRemote\nГо рст\nаб\n\nSecond page"
+		if text != want {
+			t.Errorf("got %q, want %q", text, want)
+		}
+	})
+
+	t.Run("objects packed into an object stream", func(t *testing.T) {
+		pages := "<< /Type /Pages /Kids [5 0 R] /Count 1 >>"
+		page := "<< /Type /Page /Parent 4 0 R /Contents 2 0 R /Resources << /Font << /F1 6 0 R >> >> >>"
+		font := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+		header := fmt.Sprintf("4 0 5 %d 6 %d ", len(pages)+1, len(pages)+len(page)+2)
+		packed := header + pages + "\n" + page + "\n" + font
+
+		p := &testPDF{}
+		p.add("<< /Type /Catalog /Pages 4 0 R >>")
+		p.addStream("", "BT /F1 10 Tf 50 50 Td (Packed vacancy) Tj ET", true)
+		p.addStream(fmt.Sprintf("/Type /ObjStm /N 3 /First %d", len(header)), packed, true)
+
+		text, err := Text("", "application/pdf", p.bytes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "Packed vacancy" {
+			t.Errorf("got %q", text)
+		}
+	})
+
+	t.Run("image only pages give no text", func(t *testing.T) {
+		p := &testPDF{}
+		p.add("<< /Type /Catalog /Pages 2 0 R >>")
+		p.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+		p.add("<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /XObject << /Im1 5 0 R >> >> >>")
+		p.addStream("", "q 600 0 0 800 0 0 cm /Im1 Do Q", false)
+		p.add("<< /Type /XObject /Subtype /Image /Filter /DCTDecode /Length 4 >>\nstream\n\xff\xd8\xff\xe0\nendstream")
+
+		text, err := Text("scan.pdf", "", p.bytes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "" {
+			t.Errorf("expected no text, got %q", text)
+		}
+	})
+
+	t.Run("not a pdf", func(t *testing.T) {
+		if _, err := Text("cv.pdf", "application/pdf", []byte("PK\x03\x04")); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		p := &testPDF{}
+		p.add("<< /Filter /Standard /V 2 >>")
+		data := append(p.bytes(), []byte("trailer << /Encrypt 1 0 R >>")...)
+		_, err := Text("cv.pdf", "application/pdf", data)
+		if err == nil || !strings.Contains(err.Error(), "encrypted") {
+			t.Errorf("expected encrypted error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+// package feed fetches and parses rss, atom and json feeds.
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is sent when none is configured
+const DefaultUserAgent = "positions-os/1.0"
+
+// MaxSize caps the size of a fetched feed
+const MaxSize = 10 << 20
+
+// ErrNotModified is returned by Fetch when the feed did not change since the given validators
+var ErrNotModified = errors.New("feed not modified")
+
+// StatusError is a non-2xx response of a feed url
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// Validators are the http cache validators of a fetched feed
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// Response is a fetched feed with its validators
+type Response struct {
+	Feed       *Feed
+	Validators Validators
+}
+
+// Client fetches feeds over http
+type Client struct {
+	userAgent string
+	http      *http.Client
+}
+
+// NewClient creates a feed client (DefaultUserAgent if empty)
+func NewClient(userAgent string) *Client {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &Client{
+		userAgent: userAgent,
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Fetch downloads and parses a feed.
+// non-empty validators make the request conditional: an unchanged feed (304)
+// returns ErrNotModified. other non-2xx statuses return a *StatusError.
+func (c *Client) Fetch(ctx context.Context, url string, cache Validators) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("feed request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, application/xml;q=0.9, text/xml;q=0.8, */*;q=0.5")
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("feed larger than %d bytes", MaxSize)
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Feed: f,
+		Validators: Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}, nil
+}
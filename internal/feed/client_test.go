@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/feed/feedtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *feedtest.Server {
+	t.Helper()
+	srv := feedtest.NewServer("testdata")
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Fetch(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient("")
+
+	resp, err := c.Fetch(context.Background(), srv.URL+"/rss.xml", Validators{})
+	require.NoError(t, err)
+
+	assert.Len(t, resp.Feed.Items, 3)
+	assert.NotEmpty(t, resp.Validators.ETag)
+	assert.Equal(t, feedtest.LastModified.Format(http.TimeFormat), resp.Validators.LastModified)
+}
+
+func TestClient_Fetch_Conditional(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient("")
+
+	resp, err := c.Fetch(context.Background(), srv.URL+"/atom.xml", Validators{})
+	require.NoError(t, err)
+
+	// unchanged by etag
+	_, err = c.Fetch(context.Background(), srv.URL+"/atom.xml", resp.Validators)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	// unchanged by date only
+	_, err = c.Fetch(context.Background(), srv.URL+"/atom.xml", Validators{LastModified: resp.Validators.LastModified})
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	// validators of another feed
+	other, err := c.Fetch(context.Background(), srv.URL+"/feed.json", Validators{ETag: `"stale"`})
+	require.NoError(t, err)
+	assert.Len(t, other.Feed.Items, 3)
+
+	requests := srv.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, http.StatusOK, requests[0].Status)
+	assert.Equal(t, http.StatusNotModified, requests[1].Status)
+	assert.Equal(t, http.StatusNotModified, requests[2].Status)
+	assert.Equal(t, http.StatusOK, requests[3].Status)
+}
+
+func TestClient_Fetch_HTTPErrors(t *testing.T) {
+	srv := newTestServer(t)
+	c := NewClient("")
+
+	for path, code := range map[string]int{
+		"/missing.xml": http.StatusNotFound,
+		"/status/403":  http.StatusForbidden,
+		"/status/503":  http.StatusServiceUnavailable,
+	} {
+		_, err := c.Fetch(context.Background(), srv.URL+path, Validators{})
+		var statusErr *StatusError
+		require.True(t, errors.As(err, &statusErr), "%s: err = %v, want a status error", path, err)
+		assert.Equal(t, code, statusErr.StatusCode, path)
+	}
+}
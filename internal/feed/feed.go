@@ -0,0 +1,381 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrUnknownFormat is returned for documents that are not rss, atom or json feed
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Feed is a parsed rss, atom or json feed
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is one entry of a feed
+type Item struct {
+	// GUID is the item id: guid / id, the link if there is none,
+	// a hash of title and content as the last resort
+	GUID      string
+	Title     string
+	Link      string
+	Content   string // plain text body
+	Published time.Time
+}
+
+// Text returns the item as plain text for raw_content: title, link and body
+func (i *Item) Text() string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{i.Title, i.Link, i.Content} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Parse parses an rss 2.0 / rss 1.0 (rdf), atom or json feed.
+// items are returned newest first when all of them are dated, in feed order otherwise.
+func Parse(data []byte) (*Feed, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	var (
+		f   *Feed
+		err error
+	)
+	if trimmed[0] == '{' {
+		f, err = parseJSON(trimmed)
+	} else {
+		f, err = parseXML(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := f.Items[:0]
+	for _, item := range f.Items {
+		if item.GUID == "" {
+			item.GUID = fallbackGUID(&item)
+		}
+		if item.GUID != "" {
+			items = append(items, item)
+		}
+	}
+	f.Items = items
+	sortNewestFirst(f.Items)
+	return f, nil
+}
+
+// fallbackGUID identifies an item without guid by its link or content
+func fallbackGUID(item *Item) string {
+	if item.Link != "" {
+		return item.Link
+	}
+	if item.Title == "" && item.Content == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(item.Title + "\n" + item.Content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// sortNewestFirst orders dated items by publication date, newest first
+func sortNewestFirst(items []Item) {
+	for _, item := range items {
+		if item.Published.IsZero() {
+			return
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Published.After(items[j].Published)
+	})
+}
+
+// xml formats
+
+type rssDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// rss 1.0 items are siblings of the channel
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	About       string `xml:"about,attr"`
+}
+
+type atomFeed struct {
+	Title   atomText    `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Content   atomText   `xml:"content"`
+	Summary   atomText   `xml:"summary"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// atomText is an atom text construct: text, html or xhtml
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// plain returns the text construct as plain text
+func (t atomText) plain() string {
+	switch t.Type {
+	case "xhtml":
+		return StripHTML(t.Inner)
+	case "html":
+		return StripHTML(t.Text)
+	default:
+		return collapse(t.Text)
+	}
+}
+
+func parseXML(data []byte) (*Feed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+
+	switch root.XMLName.Local {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse rss: %w", err)
+		}
+		f := &Feed{Title: collapse(doc.Channel.Title)}
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			f.Items = append(f.Items, it.item())
+		}
+		return f, nil
+	case "feed":
+		var doc atomFeed
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse atom: %w", err)
+		}
+		f := &Feed{Title: doc.Title.plain()}
+		for _, e := range doc.Entries {
+			f.Items = append(f.Items, e.item())
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("%w: root element %q", ErrUnknownFormat, root.XMLName.Local)
+	}
+}
+
+func (it rssItem) item() Item {
+	body := it.Encoded
+	if strings.TrimSpace(body) == "" {
+		body = it.Description
+	}
+	guid := strings.TrimSpace(it.GUID)
+	if guid == "" {
+		guid = strings.TrimSpace(it.About)
+	}
+	date := it.PubDate
+	if date == "" {
+		date = it.Date
+	}
+	return Item{
+		GUID:      guid,
+		Title:     StripHTML(it.Title),
+		Link:      strings.TrimSpace(it.Link),
+		Content:   StripHTML(body),
+		Published: parseDate(date),
+	}
+}
+
+func (e atomEntry) item() Item {
+	content := e.Content.plain()
+	if content == "" {
+		content = e.Summary.plain()
+	}
+	date := e.Published
+	if date == "" {
+		date = e.Updated
+	}
+	return Item{
+		GUID:      strings.TrimSpace(e.ID),
+		Title:     e.Title.plain(),
+		Link:      e.link(),
+		Content:   content,
+		Published: parseDate(date),
+	}
+}
+
+// link returns the alternate link of an entry
+func (e atomEntry) link() string {
+	for _, l := range e.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+// json feed (https://www.jsonfeed.org/version/1.1/)
+
+type jsonFeed struct {
+	Version string     `json:"version"`
+	Title   string     `json:"title"`
+	Items   []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            jsonID `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	ContentHTML   string `json:"content_html"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// jsonID accepts string and numeric item ids
+type jsonID string
+
+func (id *jsonID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = jsonID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("item id: %w", err)
+	}
+	*id = jsonID(n.String())
+	return nil
+}
+
+func parseJSON(data []byte) (*Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse json feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("%w: json without jsonfeed version", ErrUnknownFormat)
+	}
+
+	f := &Feed{Title: doc.Title}
+	for _, it := range doc.Items {
+		content := collapse(it.ContentText)
+		if content == "" {
+			content = StripHTML(it.ContentHTML)
+		}
+		if content == "" {
+			content = collapse(it.Summary)
+		}
+		link := it.URL
+		if link == "" {
+			link = it.ExternalURL
+		}
+		date := it.DatePublished
+		if date == "" {
+			date = it.DateModified
+		}
+		f.Items = append(f.Items, Item{
+			GUID:      strings.TrimSpace(string(it.ID)),
+			Title:     collapse(it.Title),
+			Link:      strings.TrimSpace(link),
+			Content:   content,
+			Published: parseDate(date),
+		})
+	}
+	return f, nil
+}
+
+// dateLayouts are the date formats seen in feeds: rfc 822 variants for rss, rfc 3339 for atom and json
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseDate parses a feed date, zero time if unknown
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	blockTagRe = regexp.MustCompile(`(?i)</?(p|br|ul|ol|div|h[1-6]|tr|blockquote)\b[^>]*>|<li\b[^>]*>`)
+	tagRe      = regexp.MustCompile(`<[^>]*>`)
+	blankRe    = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// StripHTML converts an html body to plain text, keeping paragraphs
+func StripHTML(s string) string {
+	s = blockTagRe.ReplaceAllString(s, "\n")
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// collapse trims plain text and drops blank line runs
+func collapse(s string) string {
+	return strings.TrimSpace(blankRe.ReplaceAllString(s, "\n\n"))
+}
@@ -0,0 +1,119 @@
+package feed
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFile(t *testing.T, name string) *Feed {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	f, err := Parse(data)
+	require.NoError(t, err)
+	return f
+}
+
+func TestParse_RSS(t *testing.T) {
+	f := parseFile(t, "rss.xml")
+
+	assert.Equal(t, "Acme Careers", f.Title)
+	require.Len(t, f.Items, 3)
+
+	first := f.Items[0]
+	assert.Equal(t, "acme-1042", first.GUID)
+	assert.Equal(t, "Senior Go Developer", first.Title)
+	assert.Equal(t, "https://acme.example/careers/senior-go", first.Link)
+	assert.Equal(t, "Remote, Go and PostgreSQL.\n\n5+ years\nKubernetes", first.Content)
+	assert.True(t, first.Published.Equal(time.Date(2025, 10, 14, 9, 30, 0, 0, time.UTC)))
+
+	// no guid: the link identifies the item, content:encoded wins over description
+	second := f.Items[1]
+	assert.Equal(t, "https://acme.example/careers/platform", second.GUID)
+	assert.Equal(t, "Terraform & AWS.\n\nOffice in Berlin, 80–95k EUR.", second.Content)
+
+	third := f.Items[2]
+	assert.Equal(t, "acme-1001", third.GUID)
+	assert.Empty(t, third.Link)
+	assert.Equal(t, 2025, third.Published.Year())
+}
+
+func TestParse_Atom(t *testing.T) {
+	f := parseFile(t, "atom.xml")
+
+	assert.Equal(t, "Jobs at Example Corp", f.Title)
+	require.Len(t, f.Items, 2)
+
+	// sorted newest first
+	first := f.Items[0]
+	assert.Equal(t, "tag:example.com,2025:job-78", first.GUID)
+	assert.Equal(t, "Go <Backend> Developer", first.Title)
+	assert.Equal(t, "https://example.com/jobs/78", first.Link, "alternate link, not edit")
+	assert.Equal(t, "Go, gRPC, Kafka.\n\nRemote within EU.", first.Content)
+	assert.True(t, first.Published.Equal(time.Date(2025, 10, 14, 9, 0, 0, 0, time.UTC)), "published wins over updated")
+
+	second := f.Items[1]
+	assert.Equal(t, "https://example.com/jobs/77", second.Link)
+	assert.Equal(t, "Spark and Airflow.", second.Content, "summary without content")
+	assert.Equal(t, 12, second.Published.Day(), "updated without published")
+}
+
+func TestParse_JSONFeed(t *testing.T) {
+	f := parseFile(t, "feed.json")
+
+	assert.Equal(t, "Remote Jobs", f.Title)
+	require.Len(t, f.Items, 3, "the empty item is dropped")
+
+	byTitle := map[string]Item{}
+	for _, item := range f.Items {
+		byTitle[item.Title] = item
+	}
+
+	rust := byTitle["Rust Engineer"]
+	assert.Equal(t, "501", rust.GUID, "numeric id")
+	assert.Equal(t, "Tokio, async.\n\n$120k–$150k", rust.Content)
+
+	sre := byTitle["Go SRE"]
+	assert.Equal(t, "job-502", sre.GUID)
+	assert.Equal(t, "https://jobs.example/502", sre.Link)
+	assert.Equal(t, "On-call rotation, Prometheus.", sre.Content)
+
+	draft := byTitle["Untitled draft"]
+	assert.True(t, strings.HasPrefix(draft.GUID, "sha256:"), "no id and no link: content hash")
+
+	// an undated item keeps the feed order
+	assert.Equal(t, "Rust Engineer", f.Items[0].Title)
+}
+
+func TestParse_Unknown(t *testing.T) {
+	for _, doc := range []string{"", "<html><body>hi</body></html>", `{"items": []}`, "not a feed"} {
+		_, err := Parse([]byte(doc))
+		assert.ErrorIs(t, err, ErrUnknownFormat, "doc %q", doc)
+	}
+}
+
+func TestItem_Text(t *testing.T) {
+	item := Item{Title: "Go Developer", Link: "https://example.com/1", Content: "Remote."}
+	assert.Equal(t, "Go Developer\n\nhttps://example.com/1\n\nRemote.", item.Text())
+
+	item.Link = ""
+	assert.Equal(t, "Go Developer\n\nRemote.", item.Text())
+}
+
+func TestParseDate(t *testing.T) {
+	tests := map[string]time.Time{
+		"Tue, 14 Oct 2025 09:30:00 +0000": time.Date(2025, 10, 14, 9, 30, 0, 0, time.UTC),
+		"Fri, 3 Oct 2025 08:00:00 GMT":    time.Date(2025, 10, 3, 8, 0, 0, 0, time.UTC),
+		"2025-10-14T09:00:00Z":            time.Date(2025, 10, 14, 9, 0, 0, 0, time.UTC),
+		"2025-10-14":                      time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range tests {
+		assert.True(t, parseDate(in).Equal(want), "parseDate(%q) = %v", in, parseDate(in))
+	}
+	assert.True(t, parseDate("yesterday").IsZero())
+}
@@ -0,0 +1,109 @@
+// package feedtest serves feed files with http cache validators for tests.
+package feedtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LastModified is the Last-Modified of every served file
+var LastModified = time.Date(2025, 10, 14, 10, 0, 0, 0, time.UTC)
+
+// Request is a request received by the server
+type Request struct {
+	Path   string
+	Status int
+}
+
+// Server serves the files of a data dir like a feed host:
+// GET /{name} serves {name} with an ETag (content hash) and Last-Modified,
+// answers 304 to a matching If-None-Match / If-Modified-Since and 404 to missing files.
+// GET /status/{code} answers with that status.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewServer starts a server for the files in dir
+func NewServer(dir string) *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		s.serve(rec, r, dir)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Path: r.URL.Path, Status: rec.status})
+		s.mu.Unlock()
+	}))
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, dir string) {
+	if code, ok := strings.CutPrefix(r.URL.Path, "/status/"); ok {
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 400 || status > 599 {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.Base(r.URL.Path)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	lastModified := LastModified.Format(http.TimeFormat)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified)
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if match == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since := r.Header.Get("If-Modified-Since"); since == lastModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	switch filepath.Ext(r.URL.Path) {
+	case ".json":
+		w.Header().Set("Content-Type", "application/feed+json")
+	default:
+		w.Header().Set("Content-Type", "application/xml")
+	}
+	_, _ = w.Write(data)
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// statusRecorder keeps the response status of a request
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
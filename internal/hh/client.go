@@ -0,0 +1,195 @@
+// package hh is a client of the hh.ru vacancies api.
+package hh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the hh.ru api endpoint
+const DefaultBaseURL = "https://api.hh.ru"
+
+// MaxPerPage is the max page size of a vacancy search
+const MaxPerPage = 100
+
+// MaxDepth is the max number of vacancies a search can page through (page * per_page)
+const MaxDepth = 2000
+
+// DefaultUserAgent is sent as HH-User-Agent when none is configured
+const DefaultUserAgent = "positions-os/1.0"
+
+// minRequestInterval spaces requests to stay below the api rate limit
+const minRequestInterval = 200 * time.Millisecond
+
+// ErrNotFound is returned for unknown (removed) vacancies
+var ErrNotFound = errors.New("vacancy not found")
+
+// Client calls the hh.ru api
+type Client struct {
+	baseURL   string
+	userAgent string
+	http      *http.Client
+
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+// NewClient creates a client for the given api base url (DefaultBaseURL if empty)
+func NewClient(baseURL, userAgent string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &Client{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		interval:  minRequestInterval,
+	}
+}
+
+// SetRequestInterval sets the min interval between requests
+func (c *Client) SetRequestInterval(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interval = d
+}
+
+// Search returns one page of a vacancy search, newest first.
+// params are search parameters as produced by ParseSearchURL.
+func (c *Client) Search(ctx context.Context, params url.Values, page, perPage int) (*SearchPage, error) {
+	if perPage <= 0 || perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	query.Set("order_by", "publication_time")
+
+	var result SearchPage
+	if err := c.get(ctx, "/vacancies?"+query.Encode(), &result); err != nil {
+		return nil, fmt.Errorf("search vacancies: %w", err)
+	}
+	return &result, nil
+}
+
+// GetVacancy returns a vacancy with its full description and key skills
+func (c *Client) GetVacancy(ctx context.Context, id string) (*Vacancy, error) {
+	var v Vacancy
+	if err := c.get(ctx, "/vacancies/"+url.PathEscape(id), &v); err != nil {
+		return nil, fmt.Errorf("get vacancy %s: %w", id, err)
+	}
+	return &v, nil
+}
+
+// get performs a GET request and decodes the json response
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("HH-User-Agent", c.userAgent)
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// wait blocks until the min interval since the previous request has passed
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	next := c.last.Add(c.interval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.last = next
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(next)):
+		return nil
+	}
+}
+
+// ParseSearchURL converts a saved hh.ru search into api search parameters.
+// accepts a web search url (https://hh.ru/search/vacancy?text=golang&area=1),
+// a raw query string (text=golang&area=1) or plain search text (golang developer).
+// paging and ordering parameters are dropped, the client sets them.
+func ParseSearchURL(raw string) (url.Values, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("empty search")
+	}
+
+	var query string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search url: %w", err)
+		}
+		query = u.RawQuery
+	case strings.Contains(raw, "="):
+		query = strings.TrimPrefix(raw, "?")
+	default:
+		return url.Values{"text": {raw}}, nil
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	for k := range params {
+		switch {
+		case k == "page", k == "per_page", k == "order_by", k == "items_on_page":
+			delete(params, k)
+		case strings.HasPrefix(k, "hhtm"):
+			// web analytics
+			delete(params, k)
+		}
+	}
+	if len(params) == 0 {
+		return nil, errors.New("search has no parameters")
+	}
+	return params, nil
+}
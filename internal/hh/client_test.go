@@ -0,0 +1,112 @@
+package hh
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/hh/hhtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*Client, *hhtest.Server) {
+	t.Helper()
+	srv := hhtest.NewServer("testdata")
+	t.Cleanup(srv.Close)
+
+	c := NewClient(srv.URL, "positions-os-test/1.0 (test@example.com)")
+	c.SetRequestInterval(0)
+	return c, srv
+}
+
+func TestClient_Search(t *testing.T) {
+	c, srv := newTestClient(t)
+
+	page, err := c.Search(context.Background(), url.Values{"text": {"golang"}, "area": {"1"}}, 0, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, page.Found)
+	assert.Equal(t, 2, page.Pages)
+	require.Len(t, page.Items, 2)
+
+	v := page.Items[0]
+	assert.Equal(t, "93353083", v.ID)
+	assert.Equal(t, "Golang-разработчик", v.Name)
+	assert.Equal(t, "https://hh.ru/vacancy/93353083", v.AlternateURL)
+	assert.Equal(t, "Яндекс", v.Employer.Name)
+	require.NotNil(t, v.Salary)
+	assert.Equal(t, 250000, *v.Salary.From)
+	assert.Equal(t, int64(1709629262), v.PublishedAt.Unix())
+
+	requests := srv.Requests()
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0], "order_by=publication_time")
+	assert.Contains(t, requests[0], "per_page=2")
+	assert.Contains(t, requests[0], "text=golang")
+}
+
+func TestClient_GetVacancy(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	v, err := c.GetVacancy(context.Background(), "93353083")
+	require.NoError(t, err)
+	assert.Contains(t, v.Description, "высоконагруженных")
+	require.Len(t, v.KeySkills, 3)
+	assert.Equal(t, "Go", v.KeySkills[0].Name)
+
+	_, err = c.GetVacancy(context.Background(), "1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestParseSearchURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    url.Values
+		wantErr bool
+	}{
+		{
+			name: "web search url",
+			raw:  "https://hh.ru/search/vacancy?text=golang&area=1&schedule=remote&page=3&hhtmFrom=vacancy_search_list",
+			want: url.Values{"text": {"golang"}, "area": {"1"}, "schedule": {"remote"}},
+		},
+		{
+			name: "query string",
+			raw:  "?text=go+developer&area=1&area=2",
+			want: url.Values{"text": {"go developer"}, "area": {"1", "2"}},
+		},
+		{
+			name: "plain text",
+			raw:  "golang developer",
+			want: url.Values{"text": {"golang developer"}},
+		},
+		{name: "empty", raw: "  ", wantErr: true},
+		{name: "url without search", raw: "https://hh.ru/search/vacancy?page=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSearchURL(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_UserAgent(t *testing.T) {
+	srv := hhtest.NewServer("testdata")
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/", "")
+	c.SetRequestInterval(0)
+	_, err := c.Search(context.Background(), url.Values{"text": {"go"}}, 0, 0)
+	require.NoError(t, err, "default user agent is sent")
+	assert.True(t, strings.Contains(srv.Requests()[0], "per_page=100"))
+}
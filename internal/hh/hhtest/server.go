@@ -0,0 +1,65 @@
+// package hhtest serves recorded hh.ru api responses for tests.
+package hhtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is a local stub of the hh.ru vacancies api.
+// GET /vacancies?page=N serves search_pageN.json,
+// GET /vacancies/{id} serves vacancy_{id}.json from the data dir.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// NewServer starts a stub serving the recorded responses in dir
+func NewServer(dir string) *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		s.mu.Unlock()
+
+		if r.Header.Get("HH-User-Agent") == "" {
+			http.Error(w, `{"errors":[{"type":"bad_user_agent"}]}`, http.StatusBadRequest)
+			return
+		}
+
+		var file string
+		switch {
+		case r.URL.Path == "/vacancies":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			file = "search_page" + strconv.Itoa(page) + ".json"
+		case strings.HasPrefix(r.URL.Path, "/vacancies/"):
+			file = "vacancy_" + strings.TrimPrefix(r.URL.Path, "/vacancies/") + ".json"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			http.Error(w, `{"errors":[{"type":"not_found"}]}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	return s
+}
+
+// Requests returns the request uris received so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
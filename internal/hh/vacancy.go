@@ -0,0 +1,219 @@
+package hh
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SearchPage is one page of a vacancy search
+type SearchPage struct {
+	Items   []Vacancy `json:"items"`
+	Found   int       `json:"found"`
+	Page    int       `json:"page"`
+	Pages   int       `json:"pages"`
+	PerPage int       `json:"per_page"`
+}
+
+// Vacancy is a vacancy of a search page or a single vacancy.
+// Description and KeySkills are only filled by GetVacancy.
+type Vacancy struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	AlternateURL string     `json:"alternate_url"`
+	PublishedAt  Time       `json:"published_at"`
+	Salary       *Salary    `json:"salary"`
+	Area         *Named     `json:"area"`
+	Employer     *Named     `json:"employer"`
+	Schedule     *Named     `json:"schedule"`
+	Experience   *Named     `json:"experience"`
+	Employment   *Named     `json:"employment"`
+	Snippet      *Snippet   `json:"snippet"`
+	Description  string     `json:"description"` // html
+	KeySkills    []KeySkill `json:"key_skills"`
+	Archived     bool       `json:"archived"`
+}
+
+// Salary is the salary range of a vacancy
+type Salary struct {
+	From     *int   `json:"from"`
+	To       *int   `json:"to"`
+	Currency string `json:"currency"` // RUR, USD, EUR, ...
+	Gross    *bool  `json:"gross"`
+}
+
+// Named is an api dictionary entry (area, employer, schedule, ...)
+type Named struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Snippet is the short requirement/responsibility text of a search item
+type Snippet struct {
+	Requirement    string `json:"requirement"`
+	Responsibility string `json:"responsibility"`
+}
+
+// KeySkill is a key skill of a vacancy
+type KeySkill struct {
+	Name string `json:"name"`
+}
+
+// Time is an api timestamp (2024-03-05T12:01:02+0300)
+type Time struct {
+	time.Time
+}
+
+// UnmarshalJSON parses api timestamps, with or without a colon in the offset
+func (t *Time) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05-0700", time.RFC3339} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time %q", s)
+}
+
+var (
+	tagRe      = regexp.MustCompile(`<[^>]*>`)
+	blockTagRe = regexp.MustCompile(`(?i)</?(p|br|ul|ol|div|h[1-6])\b[^>]*>|<li\b[^>]*>`)
+	blankRe    = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// StripHTML converts an html description to plain text, keeping paragraphs
+func StripHTML(s string) string {
+	s = blockTagRe.ReplaceAllString(s, "\n")
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// Text returns the vacancy as plain text for raw_content:
+// title, employer, area, salary, schedule and the description (or the snippet)
+func (v *Vacancy) Text() string {
+	var b strings.Builder
+	b.WriteString(v.Name)
+	if v.Employer != nil && v.Employer.Name != "" {
+		b.WriteString("\n" + v.Employer.Name)
+	}
+	if v.Area != nil && v.Area.Name != "" {
+		b.WriteString("\n" + v.Area.Name)
+	}
+	if salary := v.Salary.String(); salary != "" {
+		b.WriteString("\n" + salary)
+	}
+	if v.Schedule != nil && v.Schedule.Name != "" {
+		b.WriteString("\n" + v.Schedule.Name)
+	}
+	if v.Experience != nil && v.Experience.Name != "" {
+		b.WriteString("\n" + v.Experience.Name)
+	}
+
+	switch {
+	case v.Description != "":
+		b.WriteString("\n\n" + StripHTML(v.Description))
+	case v.Snippet != nil:
+		for _, part := range []string{v.Snippet.Responsibility, v.Snippet.Requirement} {
+			if part = StripHTML(part); part != "" {
+				b.WriteString("\n\n" + part)
+			}
+		}
+	}
+
+	if len(v.KeySkills) > 0 {
+		skills := make([]string, len(v.KeySkills))
+		for i, s := range v.KeySkills {
+			skills[i] = s.Name
+		}
+		b.WriteString("\n\n" + strings.Join(skills, ", "))
+	}
+	return b.String()
+}
+
+// String formats a salary range, e.g. "250000–350000 RUB"
+func (s *Salary) String() string {
+	if s == nil || (s.From == nil && s.To == nil) {
+		return ""
+	}
+	currency := Currency(s.Currency)
+	switch {
+	case s.From != nil && s.To != nil:
+		return fmt.Sprintf("%d–%d %s", *s.From, *s.To, currency)
+	case s.From != nil:
+		return fmt.Sprintf("от %d %s", *s.From, currency)
+	default:
+		return fmt.Sprintf("до %d %s", *s.To, currency)
+	}
+}
+
+// Currency maps api currency codes to the ones used in structured data (RUR -> RUB)
+func Currency(code string) string {
+	if code == "RUR" {
+		return "RUB"
+	}
+	return code
+}
+
+// experienceYears maps api experience ids to the min years of experience
+var experienceYears = map[string]int{
+	"noExperience": 0,
+	"between1And3": 1,
+	"between3And6": 3,
+	"moreThan6":    6,
+}
+
+// StructuredData returns the fields the api already provides, in the
+// structured_data format of the analyzer (title, company, salary_min, ...).
+// fields the api does not have are left out.
+func (v *Vacancy) StructuredData() map[string]interface{} {
+	data := map[string]interface{}{
+		"title": v.Name,
+	}
+	if v.Employer != nil && v.Employer.Name != "" {
+		data["company"] = v.Employer.Name
+	}
+	if v.Area != nil && v.Area.Name != "" {
+		data["location"] = v.Area.Name
+	}
+	if v.Schedule != nil {
+		data["is_remote"] = v.Schedule.ID == "remote"
+	}
+	if v.Salary != nil {
+		if v.Salary.From != nil {
+			data["salary_min"] = *v.Salary.From
+		}
+		if v.Salary.To != nil {
+			data["salary_max"] = *v.Salary.To
+		}
+		if v.Salary.Currency != "" {
+			data["currency"] = Currency(v.Salary.Currency)
+		}
+	}
+	if v.Experience != nil {
+		if years, ok := experienceYears[v.Experience.ID]; ok {
+			data["experience_years"] = years
+		}
+	}
+	if len(v.KeySkills) > 0 {
+		techs := make([]string, 0, len(v.KeySkills))
+		for _, s := range v.KeySkills {
+			techs = append(techs, strings.ToLower(s.Name))
+		}
+		data["technologies"] = techs
+	}
+	return data
+}
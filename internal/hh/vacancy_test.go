@@ -0,0 +1,63 @@
+package hh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVacancy_StructuredData(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	v, err := c.GetVacancy(context.Background(), "93353083")
+	require.NoError(t, err)
+
+	data := v.StructuredData()
+	assert.Equal(t, "Golang-разработчик", data["title"])
+	assert.Equal(t, "Яндекс", data["company"])
+	assert.Equal(t, "Москва", data["location"])
+	assert.Equal(t, true, data["is_remote"])
+	assert.Equal(t, 250000, data["salary_min"])
+	assert.Equal(t, 350000, data["salary_max"])
+	assert.Equal(t, "RUB", data["currency"])
+	assert.Equal(t, 3, data["experience_years"])
+	assert.Equal(t, []string{"go", "postgresql", "kafka"}, data["technologies"])
+
+	// open-ended salary, no key skills in search results
+	page, err := c.Search(context.Background(), nil, 0, 2)
+	require.NoError(t, err)
+	data = page.Items[1].StructuredData()
+	assert.Equal(t, 3000, data["salary_min"])
+	assert.NotContains(t, data, "salary_max")
+	assert.Equal(t, "USD", data["currency"])
+	assert.Equal(t, false, data["is_remote"])
+	assert.NotContains(t, data, "technologies")
+}
+
+func TestVacancy_Text(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	v, err := c.GetVacancy(context.Background(), "93353083")
+	require.NoError(t, err)
+
+	text := v.Text()
+	assert.Contains(t, text, "Golang-разработчик\nЯндекс\nМосква\n250000–350000 RUB")
+	assert.Contains(t, text, "разработка высоконагруженных сервисов на Go;")
+	assert.Contains(t, text, "удаленную работу & ДМС")
+	assert.Contains(t, text, "Go, PostgreSQL, Kafka")
+	assert.NotContains(t, text, "<")
+
+	// search item falls back to the snippet
+	page, err := c.Search(context.Background(), nil, 1, 2)
+	require.NoError(t, err)
+	text = page.Items[0].Text()
+	assert.Contains(t, text, "Go, Kubernetes.")
+	assert.NotContains(t, text, "highlighttext")
+}
+
+func TestStripHTML(t *testing.T) {
+	got := StripHTML("<p><strong>Задачи:</strong></p><ul><li>писать  код;</li><li>ревью</li></ul><p>A &amp; B</p>")
+	assert.Equal(t, "Задачи:\n\nписать код;\nревью\n\nA & B", got)
+}
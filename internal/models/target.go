@@ -2,6 +2,9 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +19,7 @@ const (
 	TargetTypeTGForum   ScrapingTargetType = "TG_FORUM"
 	TargetTypeHHSearch  ScrapingTargetType = "HH_SEARCH"
 	TargetTypeLinkedIn  ScrapingTargetType = "LINKEDIN_SEARCH"
+	TargetTypeFeed      ScrapingTargetType = "FEED"
 )
 
 // ScrapingTarget represents a source for job parsing.
@@ -44,8 +48,73 @@ type ScrapingTarget struct {
 
 // TargetMetadata represents parsing configuration in metadata field.
 type TargetMetadata struct {
-	Keywords      []string `json:"keywords,omitempty"`
+	Keywords      []string `json:"keywords,omitempty"` // include: message must contain any of them
 	Limit         int      `json:"limit,omitempty"`
 	IncludeTopics bool     `json:"include_topics,omitempty"`
 	Until         string   `json:"until,omitempty"` // date string YYYY-MM-DD
+
+	// hh.ru: saved search, a search url (https://hh.ru/search/vacancy?text=golang&area=1)
+	// or a query string. empty = the target url
+	Search string `json:"search,omitempty"`
+
+	// telegram channels: also collect the comments on the newest posts from
+	// the linked discussion group, the post is kept as context of each job
+	Comments bool `json:"comments,omitempty"`
+
+	// scheduling: go duration string, e.g. "30m" or "6h". empty = not scheduled
+	ScrapeInterval string `json:"scrape_interval,omitempty"`
+
+	// prefilter applied before a message becomes a job.
+	// exclude rules win; if any include rule is set, one of them must match.
+	ExcludeKeywords []string `json:"exclude_keywords,omitempty"`
+	IncludeRegex    []string `json:"include_regex,omitempty"` // case-insensitive
+	ExcludeRegex    []string `json:"exclude_regex,omitempty"`
+	IncludeHashtags []string `json:"include_hashtags,omitempty"` // with or without #
+	ExcludeHashtags []string `json:"exclude_hashtags,omitempty"`
+}
+
+// ParseTargetMetadata decodes the raw metadata map of a target.
+func ParseTargetMetadata(raw map[string]any) (TargetMetadata, error) {
+	var meta TargetMetadata
+	if len(raw) == 0 {
+		return meta, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return meta, fmt.Errorf("marshal metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("unmarshal metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// Validate checks the scrape interval and filter regexes.
+func (m TargetMetadata) Validate() error {
+	if _, err := m.Interval(); err != nil {
+		return err
+	}
+	for _, expr := range append(append([]string{}, m.IncludeRegex...), m.ExcludeRegex...) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+	}
+	return nil
+}
+
+// Interval returns the parsed scrape interval.
+// returns 0 if the target has no schedule.
+func (m TargetMetadata) Interval() (time.Duration, error) {
+	if m.ScrapeInterval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(m.ScrapeInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scrape_interval %q: %w", m.ScrapeInterval, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid scrape_interval %q: must be positive", m.ScrapeInterval)
+	}
+	return d, nil
 }
@@ -37,3 +37,17 @@ func (p *NATSPublisher) PublishJobNew(ctx context.Context, event collector.JobNe
 
 	return nil
 }
+
+// PublishJobUpdated publishes an edited job event
+func (p *NATSPublisher) PublishJobUpdated(ctx context.Context, event collector.JobUpdatedEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	if err := p.js.Publish("jobs.updated", data); err != nil {
+		return fmt.Errorf("publish event: %w", err)
+	}
+
+	return nil
+}
@@ -49,3 +49,30 @@ func TestNATSPublisher_PublishJobNew(t *testing.T) {
 		t.Error("payload should not be empty")
 	}
 }
+
+func TestNATSPublisher_PublishJobUpdated(t *testing.T) {
+	mock := &MockNATSClient{}
+	pub := &NATSPublisher{
+		js: mock,
+	}
+
+	event := collector.JobUpdatedEvent{
+		JobID:      uuid.New(),
+		TargetID:   uuid.New(),
+		ExternalID: "123",
+		RawContent: "edited",
+		EditedAt:   time.Now(),
+	}
+
+	if err := pub.PublishJobUpdated(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mock.PublishedSubject != "jobs.updated" {
+		t.Errorf("subject = %s, want jobs.updated", mock.PublishedSubject)
+	}
+
+	if len(mock.PublishedData) == 0 {
+		t.Error("payload should not be empty")
+	}
+}
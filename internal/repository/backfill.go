@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// backfillBatchSize is the number of jobs read per backfill batch
+const backfillBatchSize = 500
+
+// RehashContent recomputes content_hash of every job with the current
+// normalization (ComputeHash) and regroups duplicates by the new hashes.
+// one-off for jobs collected before the normalization, run with the
+// collector stopped. returns the number of rehashed jobs.
+func (r *JobsRepository) RehashContent(ctx context.Context) (int, error) {
+	rehashed := 0
+	after := uuid.Nil
+	for {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id, raw_content, content_hash FROM jobs
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2
+		`, after, backfillBatchSize)
+		if err != nil {
+			return rehashed, fmt.Errorf("rehash jobs: %w", err)
+		}
+
+		var ids []uuid.UUID
+		var hashes []string
+		n := 0
+		for rows.Next() {
+			var j Job
+			if err := rows.Scan(&j.ID, &j.RawContent, &j.ContentHash); err != nil {
+				rows.Close()
+				return rehashed, fmt.Errorf("scan job: %w", err)
+			}
+			n++
+			after = j.ID
+			if hash := j.ComputeHash(); j.ContentHash == nil || *j.ContentHash != hash {
+				ids = append(ids, j.ID)
+				hashes = append(hashes, hash)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rehashed, fmt.Errorf("rehash jobs: %w", err)
+		}
+
+		if len(ids) > 0 {
+			_, err := r.pool.Exec(ctx, `
+				UPDATE jobs SET content_hash = u.hash
+				FROM unnest($1::uuid[], $2::text[]) AS u(id, hash)
+				WHERE jobs.id = u.id
+			`, ids, hashes)
+			if err != nil {
+				return rehashed, fmt.Errorf("rehash jobs: %w", err)
+			}
+			rehashed += len(ids)
+		}
+		if n < backfillBatchSize {
+			break
+		}
+	}
+
+	if err := r.RegroupDuplicates(ctx); err != nil {
+		return rehashed, err
+	}
+	return rehashed, nil
+}
+
+// RegroupDuplicates rebuilds duplicate_of from content_hash: the oldest job
+// of each hash is canonical, the others point at it. RAW duplicates of an
+// analyzed canonical job share its analysis, like UpdateStructuredData does.
+func (r *JobsRepository) RegroupDuplicates(ctx context.Context) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("regroup duplicates: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE jobs SET duplicate_of = NULLIF(g.canonical, jobs.id), updated_at = NOW()
+		FROM (
+			SELECT id, first_value(id) OVER (PARTITION BY content_hash ORDER BY created_at, id) AS canonical
+			FROM jobs
+			WHERE content_hash IS NOT NULL
+		) g
+		WHERE jobs.id = g.id AND jobs.duplicate_of IS DISTINCT FROM NULLIF(g.canonical, jobs.id)
+	`)
+	if err != nil {
+		return fmt.Errorf("regroup duplicates: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE jobs d
+		SET structured_data = c.structured_data, status = 'ANALYZED',
+		    analyzed_at = c.analyzed_at, updated_at = NOW()
+		FROM jobs c
+		WHERE d.duplicate_of = c.id AND d.status = 'RAW' AND c.analyzed_at IS NOT NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("regroup duplicates: share analysis: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("regroup duplicates: commit: %w", err)
+	}
+	return nil
+}
+
+// BackfillSimHashes computes simhash for jobs stored without one (before
+// clustering) and clusters them oldest first: a job joins the cluster of the
+// closest job from the clustering window before its own creation.
+// returns the number of fingerprinted jobs.
+func (r *JobsRepository) BackfillSimHashes(ctx context.Context) (int, error) {
+	filled := 0
+	afterTime, afterID := time.Time{}, uuid.Nil
+	for {
+		rows, err := r.pool.Query(ctx, `
+			SELECT id, raw_content, created_at FROM jobs
+			WHERE simhash IS NULL AND (created_at, id) > ($1, $2)
+			ORDER BY created_at, id
+			LIMIT $3
+		`, afterTime, afterID, backfillBatchSize)
+		if err != nil {
+			return filled, fmt.Errorf("backfill simhash: %w", err)
+		}
+
+		var batch []Job
+		for rows.Next() {
+			var j Job
+			if err := rows.Scan(&j.ID, &j.RawContent, &j.CreatedAt); err != nil {
+				rows.Close()
+				return filled, fmt.Errorf("scan job: %w", err)
+			}
+			batch = append(batch, j)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return filled, fmt.Errorf("backfill simhash: %w", err)
+		}
+
+		for _, j := range batch {
+			afterTime, afterID = j.CreatedAt, j.ID
+			hash := SimHash(j.RawContent)
+			if hash == 0 {
+				continue
+			}
+			if err := r.fillSimHash(ctx, &j, int64(hash)); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+		if len(batch) < backfillBatchSize {
+			break
+		}
+	}
+	return filled, nil
+}
+
+// fillSimHash stores the simhash of an old job and joins it to the cluster
+// of the closest job created within the window before it
+func (r *JobsRepository) fillSimHash(ctx context.Context, j *Job, simhash int64) error {
+	if r.clusterWindow <= 0 {
+		if _, err := r.pool.Exec(ctx, `UPDATE jobs SET simhash = $2 WHERE id = $1`, j.ID, simhash); err != nil {
+			return fmt.Errorf("backfill simhash: %w", err)
+		}
+		return nil
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE jobs SET simhash = $2, cluster_id = COALESCE((
+			SELECT COALESCE(c.cluster_id, c.id) FROM jobs c
+			WHERE c.id <> $1 AND c.simhash IS NOT NULL
+			  AND c.created_at <= $3 AND c.created_at >= $4
+			  AND bit_count((c.simhash # $2)::bit(64)) <= $5
+			ORDER BY bit_count((c.simhash # $2)::bit(64)), c.created_at DESC
+			LIMIT 1
+		), cluster_id, id)
+		WHERE id = $1
+	`, j.ID, simhash, j.CreatedAt, j.CreatedAt.Add(-r.clusterWindow), r.clusterMaxDistance)
+	if err != nil {
+		return fmt.Errorf("backfill simhash: %w", err)
+	}
+	return nil
+}
+
+// BackfillSourceURLs sets source_url of telegram jobs stored without one to
+// the permalink of their post (ScrapingTarget.Permalink). jobs of private
+// targets never resolved (no channel id) are left as is.
+// returns the number of linked jobs.
+func (r *JobsRepository) BackfillSourceURLs(ctx context.Context) (int, error) {
+	linked := 0
+	after := uuid.Nil
+	for {
+		rows, err := r.pool.Query(ctx, `
+			SELECT j.id, j.tg_message_id, j.tg_topic_id, t.url, t.tg_channel_id
+			FROM jobs j
+			JOIN scraping_targets t ON t.id = j.target_id
+			WHERE j.source_url IS NULL AND j.tg_message_id IS NOT NULL
+			  AND t.type LIKE 'TG\_%' AND j.id > $1
+			ORDER BY j.id
+			LIMIT $2
+		`, after, backfillBatchSize)
+		if err != nil {
+			return linked, fmt.Errorf("backfill source urls: %w", err)
+		}
+
+		var ids []uuid.UUID
+		var urls []string
+		n := 0
+		for rows.Next() {
+			var (
+				id      uuid.UUID
+				msgID   int64
+				topicID *int64
+				target  ScrapingTarget
+			)
+			if err := rows.Scan(&id, &msgID, &topicID, &target.URL, &target.TgChannelID); err != nil {
+				rows.Close()
+				return linked, fmt.Errorf("scan job: %w", err)
+			}
+			n++
+			after = id
+			if url := target.Permalink(msgID, topicID); url != "" {
+				ids = append(ids, id)
+				urls = append(urls, url)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return linked, fmt.Errorf("backfill source urls: %w", err)
+		}
+
+		if len(ids) > 0 {
+			_, err := r.pool.Exec(ctx, `
+				UPDATE jobs SET source_url = u.url
+				FROM unnest($1::uuid[], $2::text[]) AS u(id, url)
+				WHERE jobs.id = u.id
+			`, ids, urls)
+			if err != nil {
+				return linked, fmt.Errorf("backfill source urls: %w", err)
+			}
+			linked += len(ids)
+		}
+		if n < backfillBatchSize {
+			break
+		}
+	}
+	return linked, nil
+}
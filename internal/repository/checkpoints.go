@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScrapeCheckpoint is the progress of a scrape run in one stream of its
+// target, saved after every batch
+type ScrapeCheckpoint struct {
+	RunID    uuid.UUID `json:"run_id"`
+	TargetID uuid.UUID `json:"target_id"`
+	TopicID  int64     `json:"topic_id"` // stream key: 0 = whole channel, forum topic, -post id for comments
+
+	Cursor   int64 `json:"cursor"` // offset of the next batch (0 = newest)
+	MinMsgID int64 `json:"min_msg_id"`
+	MaxMsgID int64 `json:"max_msg_id"`
+	Floor    int64 `json:"floor"` // oldest parsed message when the walk started, 0 = backfill
+	Batches  int   `json:"batches"`
+	Done     bool  `json:"done"` // the walk reached its end
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointsRepository handles scrape_checkpoints table operations
+type CheckpointsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCheckpointsRepository creates a new checkpoints repository
+func NewCheckpointsRepository(pool *pgxpool.Pool) *CheckpointsRepository {
+	return &CheckpointsRepository{pool: pool}
+}
+
+// Save inserts or replaces the checkpoint of a run and stream
+func (r *CheckpointsRepository) Save(ctx context.Context, cp *ScrapeCheckpoint) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO scrape_checkpoints
+			(run_id, target_id, topic_id, next_cursor, min_msg_id, max_msg_id, floor_msg_id, batches, done)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (run_id, topic_id) DO UPDATE
+		SET next_cursor = EXCLUDED.next_cursor,
+		    min_msg_id = EXCLUDED.min_msg_id,
+		    max_msg_id = EXCLUDED.max_msg_id,
+		    floor_msg_id = EXCLUDED.floor_msg_id,
+		    batches = EXCLUDED.batches,
+		    done = EXCLUDED.done,
+		    updated_at = NOW()
+		RETURNING updated_at
+	`, cp.RunID, cp.TargetID, cp.TopicID, cp.Cursor, cp.MinMsgID, cp.MaxMsgID, cp.Floor, cp.Batches, cp.Done,
+	).Scan(&cp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save scrape checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Transfer moves the checkpoints of an interrupted run to the run resuming
+// it and returns them. the interrupted run is no longer resumable.
+func (r *CheckpointsRepository) Transfer(ctx context.Context, fromRun, toRun uuid.UUID) ([]ScrapeCheckpoint, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE scrape_checkpoints
+		SET run_id = $2, updated_at = NOW()
+		WHERE run_id = $1
+		RETURNING run_id, target_id, topic_id, next_cursor, min_msg_id, max_msg_id, floor_msg_id, batches, done, updated_at
+	`, fromRun, toRun)
+	if err != nil {
+		return nil, fmt.Errorf("transfer scrape checkpoints: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckpoints(rows)
+}
+
+func scanCheckpoints(rows pgx.Rows) ([]ScrapeCheckpoint, error) {
+	checkpoints := []ScrapeCheckpoint{}
+	for rows.Next() {
+		var cp ScrapeCheckpoint
+		if err := rows.Scan(
+			&cp.RunID, &cp.TargetID, &cp.TopicID, &cp.Cursor, &cp.MinMsgID, &cp.MaxMsgID, &cp.Floor, &cp.Batches, &cp.Done, &cp.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan scrape checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
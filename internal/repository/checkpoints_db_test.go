@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCheckpointsRepository_SaveTransfer(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+
+	runs := NewRunsRepository(db.Pool)
+	repo := NewCheckpointsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Test Channel", "@test")
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+
+	// 1. A run checkpointed after two batches, then left running by a restart
+	run := &ScrapeRun{ID: uuid.New(), TargetID: &targetID, Channel: "@test"}
+	if err := runs.Create(ctx, run); err != nil {
+		t.Fatalf("Create run failed: %v", err)
+	}
+	cp := &ScrapeCheckpoint{RunID: run.ID, TargetID: targetID, Cursor: 900, MinMsgID: 900, MaxMsgID: 1000, Batches: 1}
+	if err := repo.Save(ctx, cp); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	cp.Cursor, cp.MinMsgID, cp.Batches = 800, 800, 2
+	if err := repo.Save(ctx, cp); err != nil {
+		t.Fatalf("Save (update) failed: %v", err)
+	}
+	if err := repo.Save(ctx, &ScrapeCheckpoint{RunID: run.ID, TargetID: targetID, TopicID: -42, Done: true}); err != nil {
+		t.Fatalf("Save (done stream) failed: %v", err)
+	}
+
+	fetched, err := runs.GetByID(ctx, run.ID)
+	if err != nil || fetched == nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if fetched.Resumable {
+		t.Error("a running run should not be resumable")
+	}
+
+	n, err := runs.MarkInterrupted(ctx)
+	if err != nil {
+		t.Fatalf("MarkInterrupted failed: %v", err)
+	}
+	if n < 1 {
+		t.Errorf("MarkInterrupted() = %d, want at least 1", n)
+	}
+	fetched, _ = runs.GetByID(ctx, run.ID)
+	if fetched.Status != "interrupted" || fetched.FinishedAt == nil || !fetched.Resumable {
+		t.Errorf("expected a resumable interrupted run, got %+v", fetched)
+	}
+
+	// 2. The resuming run takes the checkpoints over
+	resumed := &ScrapeRun{ID: uuid.New(), TargetID: &targetID, Channel: "@test"}
+	if err := runs.Create(ctx, resumed); err != nil {
+		t.Fatalf("Create resumed run failed: %v", err)
+	}
+	moved, err := repo.Transfer(ctx, run.ID, resumed.ID)
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if len(moved) != 2 {
+		t.Fatalf("expected 2 checkpoints, got %d", len(moved))
+	}
+	for _, m := range moved {
+		if m.RunID != resumed.ID {
+			t.Errorf("checkpoint not moved: %+v", m)
+		}
+		if m.TopicID == 0 && (m.Cursor != 800 || m.MinMsgID != 800 || m.MaxMsgID != 1000 || m.Batches != 2 || m.Done) {
+			t.Errorf("unexpected channel checkpoint: %+v", m)
+		}
+	}
+
+	fetched, _ = runs.GetByID(ctx, run.ID)
+	if fetched.Resumable {
+		t.Error("the interrupted run should not be resumable after the transfer")
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,29 +20,92 @@ type Job struct {
 	TargetID       uuid.UUID              `json:"target_id"`
 	ExternalID     string                 `json:"external_id"`
 	ContentHash    *string                `json:"content_hash,omitempty"`
+	DuplicateOf    *uuid.UUID             `json:"duplicate_of,omitempty"`
+	ClusterID      *uuid.UUID             `json:"cluster_id,omitempty"`
+	SimHash        *int64                 `json:"-"`
 	RawContent     string                 `json:"-"`
 	StructuredData map[string]interface{} `json:"structured_data"`
 	SourceURL      *string                `json:"source_url,omitempty"`
 	SourceDate     *time.Time             `json:"source_date,omitempty"`
 	TgMessageID    *int64                 `json:"tg_message_id,omitempty"`
 	TgTopicID      *int64                 `json:"tg_topic_id,omitempty"`
-	Status         string                 `json:"status"` // RAW, ANALYZED, REJECTED, INTERESTED, TAILORED, SENT, RESPONDED
+	Status         string                 `json:"status"` // RAW, ANALYZED, REJECTED, INTERESTED, TAILORED, SENT, RESPONDED, CLOSED
 	CreatedAt      time.Time              `json:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at"`
 	AnalyzedAt     *time.Time             `json:"analyzed_at,omitempty"`
+	EditedAt       *time.Time             `json:"edited_at,omitempty"` // edit date of the source message
+	ClosedAt       *time.Time             `json:"closed_at,omitempty"` // when the source message was found deleted
+	Attachment     *Attachment            `json:"attachment,omitempty"`
+	Links          []Link                 `json:"links,omitempty"`  // from telegram message entities
+	Parent         *ParentPost            `json:"parent,omitempty"` // post a comment replies to
+}
+
+// ParentPost is the channel post a comment job replies to, kept as its context
+type ParentPost struct {
+	MessageID int64  `json:"message_id"`
+	Text      string `json:"text,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// link types of message entities
+const (
+	LinkURL     = "url"
+	LinkTextURL = "text_url" // link hidden behind words, e.g. "apply"
+	LinkMention = "mention"
+	LinkEmail   = "email"
+	LinkPhone   = "phone"
+)
+
+// Link is a url or contact of a source post
+type Link struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"` // visible text of a text_url
+	URL  string `json:"url"`            // url, @username, email or phone number
+}
+
+// Attachment is the document or photo of a source post.
+// text documents are downloaded to storage and their text is kept in Text,
+// so an edited caption can be combined with it again.
+type Attachment struct {
+	Type     string `json:"type"` // document, photo
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+
+	// telegram file location, needed to download the file later
+	FileID        int64  `json:"file_id,omitempty"`
+	AccessHash    int64  `json:"access_hash,omitempty"`
+	FileReference []byte `json:"file_reference,omitempty"`
+	DCID          int    `json:"dc_id,omitempty"`
+
+	Path string `json:"path,omitempty"` // downloaded file in storage, or the file of an imported export
+	Text string `json:"text,omitempty"` // text extracted from the document
+}
+
+// JobRevision is an earlier version of an edited job post
+type JobRevision struct {
+	ID          uuid.UUID  `json:"id"`
+	JobID       uuid.UUID  `json:"job_id"`
+	RawContent  string     `json:"raw_content"`
+	ContentHash *string    `json:"content_hash,omitempty"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"` // nil for the original post
+	CreatedAt   time.Time  `json:"created_at"`          // when it was replaced
 }
 
 // JobFilter defines criteria for listing jobs
 type JobFilter struct {
-	Status    string
-	SalaryMin int
-	SalaryMax int
-	Tech      string // Search in structured_data -> tech
-	Query     string // Full text search
-	Page      int
-	Limit     int
-	Sort      string
-	Order     string // ASC/DESC
+	Status     string
+	SalaryMin  int
+	SalaryMax  int
+	Tech       string // Search in structured_data -> tech
+	Query      string // Full text search
+	Page       int
+	Limit      int
+	Sort       string
+	Order      string // ASC/DESC
+	Canonical  bool   // Only canonical jobs, duplicates hidden
+	Collapse   bool   // Only the newest job of each near-duplicate cluster
+	HideClosed bool   // Closed jobs hidden
 }
 
 // IsValidStatus checks if job status is valid
@@ -47,15 +113,30 @@ func (j *Job) IsValidStatus() bool {
 	valid := map[string]bool{
 		"RAW": true, "ANALYZED": true, "REJECTED": true,
 		"INTERESTED": true, "TAILORED": true, "SENT": true, "RESPONDED": true,
+		"CLOSED": true,
 	}
 	return valid[j.Status]
 }
 
+// IsClosed checks if job is closed (source message deleted)
+func (j *Job) IsClosed() bool {
+	return j.Status == "CLOSED"
+}
+
 // IsNew checks if job is in RAW state
 func (j *Job) IsNew() bool {
 	return j.Status == "RAW"
 }
 
+// AnalysisContent returns the text to analyze: the raw content, followed by
+// the post a comment replies to, which often names the company or role
+func (j *Job) AnalysisContent() string {
+	if j.Parent == nil || j.Parent.Text == "" {
+		return j.RawContent
+	}
+	return j.RawContent + "\n\n---\nIn reply to the channel post:\n" + j.Parent.Text
+}
+
 // Title returns job title from structured data or fallback
 func (j *Job) Title() string {
 	if title, ok := j.StructuredData["title"].(string); ok && title != "" {
@@ -81,44 +162,249 @@ func (j *Job) Salary() string {
 	return ""
 }
 
-// ComputeHash computes sha256 hash of raw content
+// LinkContacts returns the contacts among the links of the post: mentions,
+// emails, phones, telegram user links (as @username) and links hidden behind
+// words (apply forms). plain urls are not contacts.
+func (j *Job) LinkContacts() []string {
+	var contacts []string
+	for _, link := range j.Links {
+		switch link.Type {
+		case LinkMention, LinkEmail, LinkPhone:
+			contacts = append(contacts, link.URL)
+		case LinkURL, LinkTextURL:
+			if username := TelegramUsername(link.URL); username != "" {
+				contacts = append(contacts, "@"+username)
+			} else if link.Type == LinkTextURL {
+				contacts = append(contacts, link.URL)
+			}
+		}
+	}
+	return contacts
+}
+
+// Contacts returns the contacts of the job: the ones from the post links
+// first, then the ones extracted by the analyzer, without duplicates
+func (j *Job) Contacts() []string {
+	contacts := j.LinkContacts()
+	if extracted, ok := j.StructuredData["contacts"].([]interface{}); ok {
+		for _, c := range extracted {
+			if s, ok := c.(string); ok && s != "" {
+				contacts = append(contacts, s)
+			}
+		}
+	}
+	return UniqueContacts(contacts)
+}
+
+// UniqueContacts drops repeated contacts (case-insensitive), keeping the first ones
+func UniqueContacts(contacts []string) []string {
+	seen := make(map[string]bool, len(contacts))
+	unique := make([]string, 0, len(contacts))
+	for _, c := range contacts {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, strings.TrimSpace(c))
+	}
+	return unique
+}
+
+// TelegramUsername returns the username of a t.me/<username> link,
+// empty for other links, post links (t.me/<channel>/<id>) and invite links
+func TelegramUsername(link string) string {
+	path := telegramPath(link)
+	if path == "" || strings.ContainsAny(path, "/+") || strings.EqualFold(path, "joinchat") {
+		return ""
+	}
+	return path
+}
+
+// telegramPath returns the path of a t.me link without its query,
+// empty for other links
+func telegramPath(link string) string {
+	rest := strings.TrimPrefix(strings.TrimPrefix(link, "https://"), "http://")
+	host, path, _ := strings.Cut(rest, "/")
+	switch strings.ToLower(host) {
+	case "t.me", "telegram.me", "www.t.me":
+	default:
+		return ""
+	}
+	path, _, _ = strings.Cut(path, "?")
+	return strings.TrimSuffix(path, "/")
+}
+
+// IsDuplicate checks if job is a duplicate of another (canonical) job
+func (j *Job) IsDuplicate() bool {
+	return j.DuplicateOf != nil
+}
+
+// ComputeHash computes sha256 hash of normalized raw content,
+// so reposts differing only in case, emoji or whitespace get the same hash
 func (j *Job) ComputeHash() string {
-	h := sha256.Sum256([]byte(j.RawContent))
+	h := sha256.Sum256([]byte(NormalizeContent(j.RawContent)))
 	return hex.EncodeToString(h[:])
 }
 
+// NormalizeContent lowercases text, drops emoji and other symbols
+// and collapses whitespace
+func NormalizeContent(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+		case unicode.In(r, unicode.So, unicode.Sk, unicode.Cf, unicode.Me, unicode.Variation_Selector):
+			// emoji, skin tones, zero-width joiners, keycaps
+		default:
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// default near-duplicate clustering settings
+const (
+	DefaultClusterWindow      = 14 * 24 * time.Hour
+	DefaultClusterMaxDistance = 8
+)
+
 // JobsRepository handles jobs table operations
 type JobsRepository struct {
 	pool *pgxpool.Pool
+
+	clusterWindow      time.Duration
+	clusterMaxDistance int
 }
 
 // NewJobsRepository creates a new jobs repository
 func NewJobsRepository(pool *pgxpool.Pool) *JobsRepository {
-	return &JobsRepository{pool: pool}
+	return &JobsRepository{
+		pool:               pool,
+		clusterWindow:      DefaultClusterWindow,
+		clusterMaxDistance: DefaultClusterMaxDistance,
+	}
 }
 
-// Create creates a new job
+// SetClustering sets how far back new jobs look for similar ones
+// and the max simhash distance (bits of 64) to join their cluster.
+// window <= 0 disables clustering: every job is its own cluster.
+func (r *JobsRepository) SetClustering(window time.Duration, maxDistance int) {
+	r.clusterWindow = window
+	r.clusterMaxDistance = maxDistance
+}
+
+// Create creates a new job.
+// a job whose content hash matches an earlier canonical job (from any target)
+// is stored as its duplicate, DuplicateOf is set on return. a duplicate of an
+// already analyzed job shares its analysis right away: it is stored ANALYZED
+// with the canonical structured data (the analyzer skips duplicates).
+// the job joins the cluster of the most similar recent job (simhash),
+// or starts its own, ClusterID is set on return.
+// StructuredData pre-filled by the source (e.g. hh.ru) is stored as is.
 func (r *JobsRepository) Create(ctx context.Context, j *Job) error {
-	// compute hash if not set
-	if j.ContentHash == nil || *j.ContentHash == "" {
-		hash := j.ComputeHash()
-		j.ContentHash = &hash
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
 	}
+	j.fingerprint()
 
 	err := r.pool.QueryRow(ctx, `
-		INSERT INTO jobs (target_id, external_id, content_hash, raw_content, 
-		                  source_url, source_date, tg_message_id, tg_topic_id, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at, updated_at
+		WITH cluster AS (
+			SELECT COALESCE(cluster_id, id) AS id FROM jobs
+			WHERE simhash IS NOT NULL AND created_at >= $12
+			  AND bit_count((simhash # $11)::bit(64)) <= $13
+			ORDER BY bit_count((simhash # $11)::bit(64)), created_at DESC
+			LIMIT 1
+		), canonical AS (
+			SELECT id, structured_data, analyzed_at FROM jobs
+			WHERE content_hash = $3 AND duplicate_of IS NULL
+			ORDER BY created_at
+			LIMIT 1
+		), analysis AS (
+			SELECT structured_data, analyzed_at FROM canonical
+			WHERE analyzed_at IS NOT NULL AND $9 = 'RAW'
+		)
+		INSERT INTO jobs (id, target_id, external_id, content_hash, raw_content, 
+		                  source_url, source_date, tg_message_id, tg_topic_id, status,
+		                  duplicate_of, simhash, cluster_id, structured_data, analyzed_at, attachment, links, parent)
+		VALUES ($10, $1, $2, $3, $4, $5, $6, $7, $8,
+			CASE WHEN EXISTS (SELECT 1 FROM analysis) THEN 'ANALYZED' ELSE $9 END::job_status,
+			(SELECT id FROM canonical), $11, COALESCE((SELECT id FROM cluster), $10),
+			COALESCE((SELECT structured_data FROM analysis), $14::jsonb, '{}'),
+			(SELECT analyzed_at FROM analysis), $15, $16, $17)
+		RETURNING duplicate_of, cluster_id, status, structured_data, analyzed_at, created_at, updated_at
 	`, j.TargetID, j.ExternalID, j.ContentHash, j.RawContent,
 		j.SourceURL, j.SourceDate, j.TgMessageID, j.TgTopicID, j.Status,
-	).Scan(&j.ID, &j.CreatedAt, &j.UpdatedAt)
+		j.ID, j.SimHash, r.clusterSince(), r.clusterMaxDistance, j.StructuredData, j.Attachment, j.Links, j.Parent,
+	).Scan(&j.DuplicateOf, &j.ClusterID, &j.Status, &j.StructuredData, &j.AnalyzedAt, &j.CreatedAt, &j.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create job: %w", err)
 	}
 	return nil
 }
 
+// DuplicateMatch is what a new job would be stored against
+type DuplicateMatch struct {
+	DuplicateOf *uuid.UUID // canonical job with the same content hash
+	ClusterID   *uuid.UUID // cluster of the most similar recent job
+}
+
+// FindDuplicate runs the duplicate and cluster lookups of Create without
+// storing the job (dry runs). nil fields: the job would be canonical and
+// start its own cluster.
+func (r *JobsRepository) FindDuplicate(ctx context.Context, j *Job) (*DuplicateMatch, error) {
+	j.fingerprint()
+
+	var m DuplicateMatch
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT id FROM jobs
+			 WHERE content_hash = $1 AND duplicate_of IS NULL
+			 ORDER BY created_at
+			 LIMIT 1),
+			(SELECT COALESCE(cluster_id, id) FROM jobs
+			 WHERE simhash IS NOT NULL AND created_at >= $3
+			   AND bit_count((simhash # $2)::bit(64)) <= $4
+			 ORDER BY bit_count((simhash # $2)::bit(64)), created_at DESC
+			 LIMIT 1)
+	`, j.ContentHash, j.SimHash, r.clusterSince(), r.clusterMaxDistance).Scan(&m.DuplicateOf, &m.ClusterID)
+	if err != nil {
+		return nil, fmt.Errorf("find duplicate job: %w", err)
+	}
+	return &m, nil
+}
+
+// fingerprint computes the content hash and simhash of a job if not set
+func (j *Job) fingerprint() {
+	if j.ContentHash == nil || *j.ContentHash == "" {
+		hash := j.ComputeHash()
+		j.ContentHash = &hash
+	}
+	if j.SimHash == nil {
+		if hash := SimHash(j.RawContent); hash != 0 {
+			signed := int64(hash)
+			j.SimHash = &signed
+		}
+	}
+}
+
+// clusterSince is the creation time from which jobs are cluster
+// candidates, jobs older than the window are not
+func (r *JobsRepository) clusterSince() time.Time {
+	since := time.Now()
+	if r.clusterWindow > 0 {
+		since = since.Add(-r.clusterWindow)
+	}
+	return since
+}
+
 // Exists checks if a job with given target_id and external_id exists
 func (r *JobsRepository) Exists(ctx context.Context, targetID uuid.UUID, externalID string) (bool, error) {
 	var exists bool
@@ -135,15 +421,15 @@ func (r *JobsRepository) Exists(ctx context.Context, targetID uuid.UUID, externa
 func (r *JobsRepository) GetByExternalID(ctx context.Context, targetID uuid.UUID, externalID string) (*Job, error) {
 	var j Job
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, target_id, external_id, content_hash, raw_content,
+		SELECT id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
 		       structured_data, source_url, source_date, tg_message_id, tg_topic_id,
-		       status, created_at, updated_at, analyzed_at
+		       status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent
 		FROM jobs
 		WHERE target_id = $1 AND external_id = $2
 	`, targetID, externalID).Scan(
-		&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.RawContent,
+		&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
 		&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
-		&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt,
+		&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
 	)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -157,28 +443,34 @@ func (r *JobsRepository) GetByExternalID(ctx context.Context, targetID uuid.UUID
 // List returns jobs matching filter
 // List returns jobs matching filter
 func (r *JobsRepository) List(ctx context.Context, filter JobFilter) ([]*Job, int, error) {
-	query := `
-		SELECT 
-			id, target_id, external_id, content_hash, raw_content,
+	columns := `
+			id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
 			structured_data, source_url, source_date, tg_message_id, tg_topic_id,
-			status, created_at, updated_at, analyzed_at,
+			status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent,
 			COUNT(*) OVER() as total_count
-		FROM jobs
-		WHERE 1=1
 	`
+	where := " WHERE 1=1"
 	var args []interface{}
 	argID := 1
 
 	if filter.Status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argID)
+		where += fmt.Sprintf(" AND status = $%d", argID)
 		args = append(args, filter.Status)
 		argID++
 	}
 
+	if filter.Canonical {
+		where += " AND duplicate_of IS NULL"
+	}
+
+	if filter.HideClosed {
+		where += " AND status <> 'CLOSED'"
+	}
+
 	if filter.Query != "" {
 		// Search in raw_content OR title
 		q := "%" + filter.Query + "%"
-		query += fmt.Sprintf(" AND (raw_content ILIKE $%d OR structured_data->>'title' ILIKE $%d)", argID, argID+1)
+		where += fmt.Sprintf(" AND (raw_content ILIKE $%d OR structured_data->>'title' ILIKE $%d)", argID, argID+1)
 		args = append(args, q, q)
 		argID += 2
 	}
@@ -197,17 +489,24 @@ func (r *JobsRepository) List(ctx context.Context, filter JobFilter) ([]*Job, in
 		// If needed to split:
 		// techs := strings.Split(filter.Tech, ",")
 
-		query += fmt.Sprintf(" AND structured_data->'technologies' ?| $%d", argID)
+		where += fmt.Sprintf(" AND structured_data->'technologies' ?| $%d", argID)
 		args = append(args, techs)
 		argID++
 	}
 
 	if filter.SalaryMin > 0 {
-		query += fmt.Sprintf(" AND COALESCE((structured_data->>'salary_min')::int, 0) >= $%d", argID)
+		where += fmt.Sprintf(" AND COALESCE((structured_data->>'salary_min')::int, 0) >= $%d", argID)
 		args = append(args, filter.SalaryMin)
 		argID++
 	}
 
+	query := "SELECT " + columns + " FROM jobs" + where
+	if filter.Collapse {
+		// newest job of each cluster among the matching ones
+		query = "SELECT " + columns + " FROM (SELECT DISTINCT ON (COALESCE(cluster_id, id)) * FROM jobs" + where +
+			" ORDER BY COALESCE(cluster_id, id), created_at DESC) jobs"
+	}
+
 	// Order
 	query += " ORDER BY created_at DESC"
 
@@ -240,9 +539,9 @@ func (r *JobsRepository) List(ctx context.Context, filter JobFilter) ([]*Job, in
 	for rows.Next() {
 		var j Job
 		err := rows.Scan(
-			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.RawContent,
+			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
 			&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
-			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt,
+			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
 			&total, // Window function result
 		)
 		if err != nil {
@@ -257,9 +556,9 @@ func (r *JobsRepository) List(ctx context.Context, filter JobFilter) ([]*Job, in
 // GetByStatus returns jobs with given status
 func (r *JobsRepository) GetByStatus(ctx context.Context, status string, limit int) ([]Job, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, target_id, external_id, content_hash, raw_content,
+		SELECT id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
 		       structured_data, source_url, source_date, tg_message_id, tg_topic_id,
-		       status, created_at, updated_at, analyzed_at
+		       status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent
 		FROM jobs
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -274,9 +573,9 @@ func (r *JobsRepository) GetByStatus(ctx context.Context, status string, limit i
 	for rows.Next() {
 		var j Job
 		if err := rows.Scan(
-			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.RawContent,
+			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
 			&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
-			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt,
+			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
 		); err != nil {
 			return nil, fmt.Errorf("scan job: %w", err)
 		}
@@ -297,10 +596,26 @@ func (r *JobsRepository) CountByStatus(ctx context.Context, status string) (int,
 	return count, nil
 }
 
-// UpdateStatus updates job status
+// UpdateLinks replaces the links of a job (edited message entities)
+func (r *JobsRepository) UpdateLinks(ctx context.Context, id uuid.UUID, links []Link) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE jobs SET links = $2, updated_at = NOW() WHERE id = $1
+	`, id, links)
+	if err != nil {
+		return fmt.Errorf("update job links: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus updates job status.
+// closed_at is set when the job is closed and cleared when it is reopened.
 func (r *JobsRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
 	_, err := r.pool.Exec(ctx, `
-		UPDATE jobs SET status = $2, updated_at = NOW() WHERE id = $1
+		UPDATE jobs
+		SET status = $2,
+		    closed_at = CASE WHEN $2 = 'CLOSED' THEN COALESCE(closed_at, NOW()) END,
+		    updated_at = NOW()
+		WHERE id = $1
 	`, id, status)
 	if err != nil {
 		return fmt.Errorf("update job status: %w", err)
@@ -308,19 +623,65 @@ func (r *JobsRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	return nil
 }
 
+// closableStatuses are the statuses of jobs closed when their source message is deleted.
+// jobs the user rejected or already applied to keep their status.
+const closableStatuses = "('RAW', 'ANALYZED', 'INTERESTED', 'TAILORED')"
+
+// ListOpenMessageIDs returns telegram message ids of closable jobs of a target
+// above afterID, ascending. used to verify jobs in batches.
+func (r *JobsRepository) ListOpenMessageIDs(ctx context.Context, targetID uuid.UUID, afterID int64, limit int) ([]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT tg_message_id FROM jobs
+		WHERE target_id = $1 AND tg_message_id > $2 AND status IN `+closableStatuses+`
+		ORDER BY tg_message_id
+		LIMIT $3
+	`, targetID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list open message ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan message id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CloseByMessageIDs closes the closable jobs of a target whose telegram
+// messages were deleted. returns the number of closed jobs.
+func (r *JobsRepository) CloseByMessageIDs(ctx context.Context, targetID uuid.UUID, msgIDs []int64) (int, error) {
+	if len(msgIDs) == 0 {
+		return 0, nil
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'CLOSED', closed_at = NOW(), updated_at = NOW()
+		WHERE target_id = $1 AND tg_message_id = ANY($2) AND status IN `+closableStatuses+`
+	`, targetID, msgIDs)
+	if err != nil {
+		return 0, fmt.Errorf("close jobs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
 // GetByID returns a job by ID
 func (r *JobsRepository) GetByID(ctx context.Context, id uuid.UUID) (*Job, error) {
 	var j Job
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, target_id, external_id, content_hash, raw_content,
+		SELECT id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
 		       structured_data, source_url, source_date, tg_message_id, tg_topic_id,
-		       status, created_at, updated_at, analyzed_at
+		       status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent
 		FROM jobs
 		WHERE id = $1
 	`, id).Scan(
-		&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.RawContent,
+		&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
 		&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
-		&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt,
+		&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
 	)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -331,18 +692,245 @@ func (r *JobsRepository) GetByID(ctx context.Context, id uuid.UUID) (*Job, error
 	return &j, nil
 }
 
-// UpdateStructuredData updates job structured data and sets status to ANALYZED
+// UpdateStructuredData updates job structured data and sets status RAW to ANALYZED.
+// a re-analysis (after an edit) keeps statuses set by the user.
+// duplicates of the job that are still RAW share the analysis.
 func (r *JobsRepository) UpdateStructuredData(ctx context.Context, id uuid.UUID, data map[string]interface{}) error {
 	_, err := r.pool.Exec(ctx, `
 		UPDATE jobs
 		SET structured_data = $2,
-		    status = 'ANALYZED',
+		    status = CASE WHEN status = 'RAW' THEN 'ANALYZED'::job_status ELSE status END,
 		    updated_at = NOW(),
 		    analyzed_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 OR (duplicate_of = $1 AND status = 'RAW')
 	`, id, data)
 	if err != nil {
 		return fmt.Errorf("update structured data: %w", err)
 	}
 	return nil
 }
+
+// UpdateContent replaces the content of a job with an edited version of its message.
+// the previous content is kept as a revision. edits not newer than the stored one
+// are ignored; an edit that leaves the normalized text unchanged only moves edited_at.
+// returns true if the content changed.
+func (r *JobsRepository) UpdateContent(ctx context.Context, id uuid.UUID, rawContent string, editedAt time.Time) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("update job content: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var oldContent string
+	var oldHash *string
+	var oldEditedAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT raw_content, content_hash, edited_at FROM jobs WHERE id = $1 FOR UPDATE
+	`, id).Scan(&oldContent, &oldHash, &oldEditedAt)
+	if err != nil {
+		return false, fmt.Errorf("update job content: lock job: %w", err)
+	}
+	if oldEditedAt != nil && !editedAt.After(*oldEditedAt) {
+		return false, nil
+	}
+
+	j := Job{RawContent: rawContent}
+	hash := j.ComputeHash()
+	if oldHash != nil && *oldHash == hash {
+		if _, err := tx.Exec(ctx, `UPDATE jobs SET edited_at = $2 WHERE id = $1`, id, editedAt); err != nil {
+			return false, fmt.Errorf("update job content: %w", err)
+		}
+		return false, tx.Commit(ctx)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO job_revisions (job_id, raw_content, content_hash, edited_at)
+		VALUES ($1, $2, $3, $4)
+	`, id, oldContent, oldHash, oldEditedAt)
+	if err != nil {
+		return false, fmt.Errorf("update job content: save revision: %w", err)
+	}
+
+	var simhash *int64
+	if h := SimHash(rawContent); h != 0 {
+		signed := int64(h)
+		simhash = &signed
+	}
+
+	// the duplicates of the old text lose their canonical job
+	if err := promoteDuplicates(ctx, tx, id); err != nil {
+		return false, fmt.Errorf("update job content: %w", err)
+	}
+
+	// the edited post may now be an exact duplicate of another job, or no longer one
+	_, err = tx.Exec(ctx, `
+		UPDATE jobs
+		SET raw_content = $2,
+		    content_hash = $3,
+		    simhash = $4,
+		    edited_at = $5,
+		    duplicate_of = (
+		        SELECT d.id FROM jobs d
+		        WHERE d.content_hash = $3 AND d.duplicate_of IS NULL AND d.id <> $1
+		        ORDER BY d.created_at
+		        LIMIT 1
+		    ),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, rawContent, hash, simhash, editedAt)
+	if err != nil {
+		return false, fmt.Errorf("update job content: %w", err)
+	}
+
+	if err := r.recluster(ctx, tx, id, simhash); err != nil {
+		return false, fmt.Errorf("update job content: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("update job content: commit: %w", err)
+	}
+	return true, nil
+}
+
+// promoteDuplicates makes the oldest duplicate of a job canonical and points
+// the other duplicates at it, so no duplicate_of chains are left when the job
+// changes its content
+func promoteDuplicates(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		WITH promoted AS (
+			SELECT id FROM jobs WHERE duplicate_of = $1
+			ORDER BY created_at, id
+			LIMIT 1
+		)
+		UPDATE jobs
+		SET duplicate_of = NULLIF((SELECT id FROM promoted), jobs.id), updated_at = NOW()
+		WHERE duplicate_of = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("promote duplicates: %w", err)
+	}
+	return nil
+}
+
+// recluster moves an edited job to the cluster of the closest recent job,
+// or to a cluster of its own. jobs left in a cluster labeled with the id of
+// the edited job are relabeled with the id of their oldest member.
+func (r *JobsRepository) recluster(ctx context.Context, tx pgx.Tx, id uuid.UUID, simhash *int64) error {
+	_, err := tx.Exec(ctx, `
+		WITH members AS (
+			SELECT id, created_at FROM jobs WHERE cluster_id = $1 AND id <> $1
+		)
+		UPDATE jobs SET cluster_id = (SELECT id FROM members ORDER BY created_at, id LIMIT 1)
+		WHERE id IN (SELECT id FROM members)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("relabel cluster: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE jobs SET cluster_id = COALESCE((
+			SELECT COALESCE(c.cluster_id, c.id) FROM jobs c
+			WHERE c.id <> $1 AND c.simhash IS NOT NULL AND c.created_at >= $3
+			  AND bit_count((c.simhash # $2)::bit(64)) <= $4
+			ORDER BY bit_count((c.simhash # $2)::bit(64)), c.created_at DESC
+			LIMIT 1
+		), $1)
+		WHERE id = $1
+	`, id, simhash, r.clusterSince(), r.clusterMaxDistance)
+	if err != nil {
+		return fmt.Errorf("recluster job: %w", err)
+	}
+	return nil
+}
+
+// GetRevisions returns earlier versions of a job, newest first
+func (r *JobsRepository) GetRevisions(ctx context.Context, jobID uuid.UUID) ([]JobRevision, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, job_id, raw_content, content_hash, edited_at, created_at
+		FROM job_revisions
+		WHERE job_id = $1
+		ORDER BY created_at DESC
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("get job revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []JobRevision{}
+	for rows.Next() {
+		var rev JobRevision
+		if err := rows.Scan(&rev.ID, &rev.JobID, &rev.RawContent, &rev.ContentHash, &rev.EditedAt, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan job revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetDuplicates returns the duplicate group of a job: the canonical job first,
+// then its duplicates oldest first. a job without duplicates is a group of one.
+func (r *JobsRepository) GetDuplicates(ctx context.Context, id uuid.UUID) ([]*Job, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH canonical AS (
+			SELECT COALESCE(duplicate_of, id) AS id FROM jobs WHERE id = $1
+		)
+		SELECT id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
+		       structured_data, source_url, source_date, tg_message_id, tg_topic_id,
+		       status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent
+		FROM jobs
+		WHERE id = (SELECT id FROM canonical) OR duplicate_of = (SELECT id FROM canonical)
+		ORDER BY duplicate_of IS NOT NULL, created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("get job duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(
+			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
+			&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
+			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
+		); err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, rows.Err()
+}
+
+// GetCluster returns the near-duplicate cluster of a job, newest first.
+// a job without similar jobs is a cluster of one.
+func (r *JobsRepository) GetCluster(ctx context.Context, id uuid.UUID) ([]*Job, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH cluster AS (
+			SELECT COALESCE(cluster_id, id) AS id FROM jobs WHERE id = $1
+		)
+		SELECT id, target_id, external_id, content_hash, duplicate_of, cluster_id, raw_content,
+		       structured_data, source_url, source_date, tg_message_id, tg_topic_id,
+		       status, created_at, updated_at, analyzed_at, edited_at, closed_at, attachment, links, parent
+		FROM jobs
+		WHERE COALESCE(cluster_id, id) = (SELECT id FROM cluster)
+		ORDER BY created_at DESC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("get job cluster: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(
+			&j.ID, &j.TargetID, &j.ExternalID, &j.ContentHash, &j.DuplicateOf, &j.ClusterID, &j.RawContent,
+			&j.StructuredData, &j.SourceURL, &j.SourceDate, &j.TgMessageID, &j.TgTopicID,
+			&j.Status, &j.CreatedAt, &j.UpdatedAt, &j.AnalyzedAt, &j.EditedAt, &j.ClosedAt, &j.Attachment, &j.Links, &j.Parent,
+		); err != nil {
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, rows.Err()
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -103,8 +105,10 @@ func setupSchema(t *testing.T, db *database.DB) {
 
 	// Cleanup
 	_, _ = db.Pool.Exec(ctx, `
+		DROP TABLE IF EXISTS scrape_runs CASCADE;
 		DROP TABLE IF EXISTS job_applications CASCADE;
 		DROP TABLE IF EXISTS job_listings CASCADE;
+		DROP TABLE IF EXISTS job_revisions CASCADE;
 		DROP TABLE IF EXISTS parsed_ranges CASCADE;
 		DROP TABLE IF EXISTS jobs CASCADE;
 		DROP TABLE IF EXISTS scraping_targets CASCADE;
@@ -116,6 +120,18 @@ func setupSchema(t *testing.T, db *database.DB) {
 	files := []string{
 		"../../migrations/0001_create_scraping_targets.up.sql",
 		"../../migrations/0002_create_jobs.up.sql",
+		"../../migrations/0007_create_scrape_runs.up.sql",
+		"../../migrations/0009_add_skipped_filtered_to_scrape_runs.up.sql",
+		"../../migrations/0010_add_duplicate_of_to_jobs.up.sql",
+		"../../migrations/0011_add_job_clusters.up.sql",
+		"../../migrations/0012_create_job_revisions.up.sql",
+		"../../migrations/0013_add_closed_status_to_jobs.up.sql",
+		"../../migrations/0014_add_feed_targets.up.sql",
+		"../../migrations/0015_add_job_attachments.up.sql",
+		"../../migrations/0016_add_job_links.up.sql",
+		"../../migrations/0017_add_job_parent.up.sql",
+		"../../migrations/0018_add_target_health.up.sql",
+		"../../migrations/0019_create_scrape_checkpoints.up.sql",
 	}
 
 	for _, f := range files {
@@ -232,6 +248,809 @@ func TestJobsRepository_List(t *testing.T) {
 	}
 }
 
+func TestJobsRepository_Duplicates(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	// the same vacancy posted in two channels
+	var targets []uuid.UUID
+	for i, url := range []string{"http://t.me/dup1", "http://t.me/dup2"} {
+		id := uuid.New()
+		_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", id, fmt.Sprintf("Dup %d", i), url)
+		requireNoError(t, err)
+		targets = append(targets, id)
+	}
+
+	canonical := &Job{TargetID: targets[0], ExternalID: "1", RawContent: "Go Developer 🚀\n\nRemote", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, canonical))
+	if canonical.IsDuplicate() {
+		t.Fatalf("first job should be canonical, got duplicate_of %v", canonical.DuplicateOf)
+	}
+
+	dup := &Job{TargetID: targets[1], ExternalID: "7", RawContent: "go developer remote", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, dup))
+	if dup.DuplicateOf == nil || *dup.DuplicateOf != canonical.ID {
+		t.Fatalf("expected duplicate of %s, got %v", canonical.ID, dup.DuplicateOf)
+	}
+
+	other := &Job{TargetID: targets[1], ExternalID: "8", RawContent: "Python Developer", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, other))
+	if other.IsDuplicate() {
+		t.Errorf("different content should not be a duplicate")
+	}
+
+	// group is the same from either member, canonical first
+	group, err := repo.GetDuplicates(ctx, dup.ID)
+	requireNoError(t, err)
+	if len(group) != 2 || group[0].ID != canonical.ID || group[1].ID != dup.ID {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+
+	// analysis of the canonical job is shared with its duplicates
+	requireNoError(t, repo.UpdateStructuredData(ctx, canonical.ID, map[string]interface{}{"title": "Go Developer"}))
+	got, err := repo.GetByID(ctx, dup.ID)
+	requireNoError(t, err)
+	if got.Status != "ANALYZED" || got.StructuredData["title"] != "Go Developer" {
+		t.Errorf("duplicate should share the analysis, got status %s data %v", got.Status, got.StructuredData)
+	}
+
+	// canonical listing hides duplicates
+	_, total, err := repo.List(ctx, JobFilter{Canonical: true})
+	requireNoError(t, err)
+	if total != 2 {
+		t.Errorf("expected 2 canonical jobs, got %d", total)
+	}
+}
+
+func TestJobsRepository_DuplicateAfterAnalysis(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	var targets []uuid.UUID
+	for i, url := range []string{"http://t.me/first", "http://t.me/repost"} {
+		id := uuid.New()
+		_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", id, fmt.Sprintf("Channel %d", i), url)
+		requireNoError(t, err)
+		targets = append(targets, id)
+	}
+
+	// the canonical job is analyzed before the repost arrives
+	canonical := &Job{TargetID: targets[0], ExternalID: "1", RawContent: "Go Developer\nRemote", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, canonical))
+	requireNoError(t, repo.UpdateStructuredData(ctx, canonical.ID, map[string]interface{}{"title": "Go Developer"}))
+
+	repost := &Job{TargetID: targets[1], ExternalID: "5", RawContent: "GO DEVELOPER 🔥 remote", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, repost))
+	if repost.DuplicateOf == nil || *repost.DuplicateOf != canonical.ID {
+		t.Fatalf("expected duplicate of %s, got %v", canonical.ID, repost.DuplicateOf)
+	}
+	if repost.Status != "ANALYZED" || repost.AnalyzedAt == nil || repost.StructuredData["title"] != "Go Developer" {
+		t.Errorf("returned repost should share the analysis, got status %s data %v", repost.Status, repost.StructuredData)
+	}
+
+	got, err := repo.GetByID(ctx, repost.ID)
+	requireNoError(t, err)
+	if got.Status != "ANALYZED" || got.AnalyzedAt == nil || got.StructuredData["title"] != "Go Developer" {
+		t.Errorf("stored repost should share the analysis, got status %s data %v", got.Status, got.StructuredData)
+	}
+
+	// a repost of a job not analyzed yet stays RAW until the canonical one is
+	pending := &Job{TargetID: targets[0], ExternalID: "2", RawContent: "Rust Developer", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, pending))
+	pendingDup := &Job{TargetID: targets[1], ExternalID: "6", RawContent: "rust developer", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, pendingDup))
+	if pendingDup.Status != "RAW" || pendingDup.AnalyzedAt != nil {
+		t.Errorf("duplicate of an unanalyzed job should stay RAW, got %s", pendingDup.Status)
+	}
+}
+
+func TestJobsRepository_RehashContent(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, 'Old', 'http://t.me/old', 'TG_CHANNEL', true, NOW(), NOW())", targetID)
+	requireNoError(t, err)
+
+	// jobs stored before the normalized hash: raw-content sha256, not grouped
+	var ids []uuid.UUID
+	for i, text := range []string{"Go Developer 🚀", "go developer", "Python Developer"} {
+		id := uuid.New()
+		_, err = db.Pool.Exec(ctx, `
+			INSERT INTO jobs (id, target_id, external_id, raw_content, content_hash, status, created_at)
+			VALUES ($1, $2, $3, $4, encode(sha256(convert_to($4, 'UTF8')), 'hex'), 'RAW', NOW() + make_interval(mins => $5))
+		`, id, targetID, fmt.Sprint(i), text, i)
+		requireNoError(t, err)
+		ids = append(ids, id)
+	}
+	requireNoError(t, repo.UpdateStructuredData(ctx, ids[0], map[string]interface{}{"title": "Go Developer"}))
+
+	n, err := repo.RehashContent(ctx)
+	requireNoError(t, err)
+	if n != 3 {
+		t.Errorf("RehashContent() = %d, want 3", n)
+	}
+
+	dup, err := repo.GetByID(ctx, ids[1])
+	requireNoError(t, err)
+	if dup.DuplicateOf == nil || *dup.DuplicateOf != ids[0] {
+		t.Fatalf("expected duplicate of %s, got %v", ids[0], dup.DuplicateOf)
+	}
+	if dup.Status != "ANALYZED" || dup.StructuredData["title"] != "Go Developer" {
+		t.Errorf("regrouped duplicate should share the analysis, got status %s", dup.Status)
+	}
+	other, err := repo.GetByID(ctx, ids[2])
+	requireNoError(t, err)
+	if other.IsDuplicate() {
+		t.Errorf("different content should not be a duplicate")
+	}
+
+	// a second run has nothing to do
+	n, err = repo.RehashContent(ctx)
+	requireNoError(t, err)
+	if n != 0 {
+		t.Errorf("second RehashContent() = %d, want 0", n)
+	}
+}
+
+func TestJobsRepository_BackfillSimHashes(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, 'Old', 'http://t.me/old', 'TG_CHANNEL', true, NOW(), NOW())", targetID)
+	requireNoError(t, err)
+
+	post := "Senior Go Developer\nTechCorp\nSalary 300 000 - 400 000\nRemote, full time\nGo, PostgreSQL, Kafka, Kubernetes\nContact @hr_techcorp"
+	texts := []string{
+		post,
+		strings.Replace(post, "300 000 - 400 000", "350 000 - 450 000", 1) + "\n#vacancy",
+		"Python Backend Developer\nDataSoft\nSalary 250 000\nOffice Moscow\nPython, Django, Redis\nContact @hr_datasoft",
+		"",
+	}
+
+	// jobs stored before clustering: no simhash, clusters of one
+	var ids []uuid.UUID
+	for i, text := range texts {
+		id := uuid.New()
+		_, err = db.Pool.Exec(ctx, `
+			INSERT INTO jobs (id, target_id, external_id, raw_content, cluster_id, status, created_at)
+			VALUES ($1, $2, $3, $4, $1, 'RAW', NOW() - interval '1 day' + make_interval(mins => $5))
+		`, id, targetID, fmt.Sprint(i), text, i)
+		requireNoError(t, err)
+		ids = append(ids, id)
+	}
+
+	n, err := repo.BackfillSimHashes(ctx)
+	requireNoError(t, err)
+	if n != 3 {
+		t.Errorf("BackfillSimHashes() = %d, want 3 (empty text has no fingerprint)", n)
+	}
+
+	cluster, err := repo.GetCluster(ctx, ids[0])
+	requireNoError(t, err)
+	if len(cluster) != 2 || cluster[0].ID != ids[1] {
+		t.Fatalf("expected the repost in the cluster of the first job, got %+v", cluster)
+	}
+	other, err := repo.GetByID(ctx, ids[2])
+	requireNoError(t, err)
+	if other.ClusterID == nil || *other.ClusterID != other.ID {
+		t.Errorf("unrelated job should stay its own cluster, got %v", other.ClusterID)
+	}
+
+	// a new repost can now join the old cluster
+	fresh := &Job{TargetID: targetID, ExternalID: "9", RawContent: post + "\nStill open", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, fresh))
+	if fresh.ClusterID == nil || *fresh.ClusterID != ids[0] {
+		t.Errorf("new repost should join backfilled cluster %s, got %v", ids[0], fresh.ClusterID)
+	}
+}
+
+func TestJobsRepository_BackfillSourceURLs(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	forumID, privateID, inviteID := uuid.New(), uuid.New(), uuid.New()
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO scraping_targets (id, name, url, type, tg_channel_id, is_active, created_at, updated_at) VALUES
+		($1, 'Forum', '@go_forum', 'TG_FORUM', NULL, true, NOW(), NOW()),
+		($2, 'Private', 'https://t.me/+AbCdEf', 'TG_CHANNEL', 1234567890, true, NOW(), NOW()),
+		($3, 'Unresolved', 'https://t.me/+GhIjKl', 'TG_CHANNEL', NULL, true, NOW(), NOW())
+	`, forumID, privateID, inviteID)
+	requireNoError(t, err)
+
+	msgID, topicID := int64(42), int64(15)
+	forumJob := &Job{TargetID: forumID, ExternalID: "42", RawContent: "Go developer", Status: "RAW", TgMessageID: &msgID, TgTopicID: &topicID}
+	privateJob := &Job{TargetID: privateID, ExternalID: "42", RawContent: "Python developer", Status: "RAW", TgMessageID: &msgID}
+	inviteJob := &Job{TargetID: inviteID, ExternalID: "42", RawContent: "Rust developer", Status: "RAW", TgMessageID: &msgID}
+	linked := "https://t.me/go_forum/99"
+	linkedJob := &Job{TargetID: forumID, ExternalID: "99", RawContent: "Java developer", Status: "RAW", TgMessageID: &msgID, SourceURL: &linked}
+	for _, j := range []*Job{forumJob, privateJob, inviteJob, linkedJob} {
+		requireNoError(t, repo.Create(ctx, j))
+	}
+
+	n, err := repo.BackfillSourceURLs(ctx)
+	requireNoError(t, err)
+	if n != 2 {
+		t.Errorf("BackfillSourceURLs() = %d, want 2", n)
+	}
+
+	want := map[uuid.UUID]string{
+		forumJob.ID:   "https://t.me/go_forum/15/42",
+		privateJob.ID: "https://t.me/c/1234567890/42",
+		inviteJob.ID:  "",
+		linkedJob.ID:  linked,
+	}
+	for id, url := range want {
+		got, err := repo.GetByID(ctx, id)
+		requireNoError(t, err)
+		if (url == "" && got.SourceURL != nil) || (url != "" && (got.SourceURL == nil || *got.SourceURL != url)) {
+			t.Errorf("job %s source_url = %v, want %q", id, got.SourceURL, url)
+		}
+	}
+
+	n, err = repo.BackfillSourceURLs(ctx)
+	requireNoError(t, err)
+	if n != 0 {
+		t.Errorf("second BackfillSourceURLs() = %d, want 0", n)
+	}
+}
+
+func TestJobsRepository_Clusters(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Cluster Channel", "http://t.me/cluster")
+	requireNoError(t, err)
+
+	post := "Senior Go Developer\nTechCorp\nSalary 300 000 - 400 000\nRemote, full time\nGo, PostgreSQL, Kafka, Kubernetes\nContact @hr_techcorp"
+
+	first := &Job{TargetID: targetID, ExternalID: "1", RawContent: post, Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, first))
+	if first.ClusterID == nil || *first.ClusterID != first.ID {
+		t.Fatalf("first job should start its own cluster, got %v", first.ClusterID)
+	}
+
+	// same role, edited salary and an added hashtag
+	edited := &Job{TargetID: targetID, ExternalID: "2", RawContent: strings.Replace(post, "300 000 - 400 000", "350 000 - 450 000", 1) + "\n#vacancy", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, edited))
+	if edited.ClusterID == nil || *edited.ClusterID != first.ID {
+		t.Errorf("edited repost should join cluster %s, got %v", first.ID, edited.ClusterID)
+	}
+	if edited.IsDuplicate() {
+		t.Errorf("edited repost is not an exact duplicate")
+	}
+
+	other := &Job{TargetID: targetID, ExternalID: "3", RawContent: "Python Backend Developer\nDataSoft\nSalary 250 000\nOffice Moscow\nPython, Django, Redis\nContact @hr_datasoft", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, other))
+	if other.ClusterID == nil || *other.ClusterID != other.ID {
+		t.Errorf("unrelated job should start its own cluster, got %v", other.ClusterID)
+	}
+
+	cluster, err := repo.GetCluster(ctx, first.ID)
+	requireNoError(t, err)
+	if len(cluster) != 2 || cluster[0].ID != edited.ID {
+		t.Fatalf("expected cluster of 2, newest first, got %+v", cluster)
+	}
+
+	// collapsed listing shows one job per cluster, the newest
+	jobs, total, err := repo.List(ctx, JobFilter{Collapse: true})
+	requireNoError(t, err)
+	if total != 2 {
+		t.Errorf("expected 2 clusters, got %d", total)
+	}
+	for _, j := range jobs {
+		if j.ID == first.ID {
+			t.Errorf("older job of a cluster should be collapsed")
+		}
+	}
+
+	// the first job is edited into another vacancy: it leaves, the repost keeps a cluster of its own
+	changed, err := repo.UpdateContent(ctx, first.ID, "Frontend Developer\nWebShop\nReact, TypeScript\nOffice Berlin", time.Now())
+	requireNoError(t, err)
+	if !changed {
+		t.Fatal("edit should change the content")
+	}
+	got, err := repo.GetByID(ctx, first.ID)
+	requireNoError(t, err)
+	if got.ClusterID == nil || *got.ClusterID != first.ID {
+		t.Errorf("edited job should start its own cluster, got %v", got.ClusterID)
+	}
+	got, err = repo.GetByID(ctx, edited.ID)
+	requireNoError(t, err)
+	if got.ClusterID == nil || *got.ClusterID != edited.ID {
+		t.Errorf("remaining member should be relabeled to its own id, got %v", got.ClusterID)
+	}
+
+	// edited back: it joins the repost's cluster again
+	_, err = repo.UpdateContent(ctx, first.ID, post, time.Now().Add(time.Minute))
+	requireNoError(t, err)
+	got, err = repo.GetByID(ctx, first.ID)
+	requireNoError(t, err)
+	if got.ClusterID == nil || *got.ClusterID != edited.ID {
+		t.Errorf("edited job should rejoin cluster %s, got %v", edited.ID, got.ClusterID)
+	}
+
+	// clustering disabled: every job is its own cluster
+	repo.SetClustering(0, DefaultClusterMaxDistance)
+	again := &Job{TargetID: targetID, ExternalID: "4", RawContent: post + "\nUpdated", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, again))
+	if again.ClusterID == nil || *again.ClusterID != again.ID {
+		t.Errorf("with clustering disabled job should be its own cluster, got %v", again.ClusterID)
+	}
+}
+
+func TestJobsRepository_FindDuplicate(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Preview Channel", "http://t.me/preview")
+	requireNoError(t, err)
+
+	post := "Senior Go Developer\nTechCorp\nSalary 300 000 - 400 000\nRemote, full time\nGo, PostgreSQL, Kafka, Kubernetes\nContact @hr_techcorp"
+	stored := &Job{TargetID: targetID, ExternalID: "1", RawContent: post, Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, stored))
+
+	// exact repost: duplicate of the stored job, in its cluster
+	match, err := repo.FindDuplicate(ctx, &Job{TargetID: uuid.New(), ExternalID: "9", RawContent: post})
+	requireNoError(t, err)
+	if match.DuplicateOf == nil || *match.DuplicateOf != stored.ID || match.ClusterID == nil || *match.ClusterID != stored.ID {
+		t.Errorf("repost match = %+v, want duplicate of and cluster %s", match, stored.ID)
+	}
+
+	// edited repost: only similar
+	match, err = repo.FindDuplicate(ctx, &Job{TargetID: targetID, ExternalID: "2", RawContent: strings.Replace(post, "300 000 - 400 000", "350 000 - 450 000", 1) + "\n#vacancy"})
+	requireNoError(t, err)
+	if match.DuplicateOf != nil || match.ClusterID == nil || *match.ClusterID != stored.ID {
+		t.Errorf("edited repost match = %+v, want cluster %s only", match, stored.ID)
+	}
+
+	// unrelated job: no match
+	match, err = repo.FindDuplicate(ctx, &Job{TargetID: targetID, ExternalID: "3", RawContent: "Python Backend Developer\nDataSoft\nSalary 250 000\nOffice Moscow\nPython, Django, Redis\nContact @hr_datasoft"})
+	requireNoError(t, err)
+	if match.DuplicateOf != nil || match.ClusterID != nil {
+		t.Errorf("unrelated job match = %+v, want none", match)
+	}
+
+	// nothing was stored
+	var count int
+	requireNoError(t, db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM jobs WHERE target_id = $1", targetID).Scan(&count))
+	if count != 1 {
+		t.Errorf("FindDuplicate() stored jobs: %d, want 1", count)
+	}
+}
+
+func TestJobsRepository_UpdateContent(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Edit Channel", "http://t.me/edit")
+	requireNoError(t, err)
+
+	job := &Job{TargetID: targetID, ExternalID: "1", RawContent: "Go developer, 300k", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, job))
+	requireNoError(t, repo.UpdateStructuredData(ctx, job.ID, map[string]interface{}{"salary": "300k"}))
+	requireNoError(t, repo.UpdateStatus(ctx, job.ID, "INTERESTED"))
+
+	firstEdit := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// salary changed
+	changed, err := repo.UpdateContent(ctx, job.ID, "Go developer, 350k", firstEdit)
+	requireNoError(t, err)
+	if !changed {
+		t.Fatal("expected content change")
+	}
+
+	got, err := repo.GetByID(ctx, job.ID)
+	requireNoError(t, err)
+	if got.RawContent != "Go developer, 350k" || got.EditedAt == nil || !got.EditedAt.Equal(firstEdit) {
+		t.Errorf("unexpected job after edit: content %q edited_at %v", got.RawContent, got.EditedAt)
+	}
+	if *got.ContentHash == *job.ContentHash {
+		t.Error("content hash should change with the content")
+	}
+
+	// same edit again is ignored
+	changed, err = repo.UpdateContent(ctx, job.ID, "Go developer, 999k", firstEdit)
+	requireNoError(t, err)
+	if changed {
+		t.Error("edit with the same date should be ignored")
+	}
+
+	// later edit that only changes formatting/emoji keeps the content
+	changed, err = repo.UpdateContent(ctx, job.ID, "🔥 Go developer,  350k", firstEdit.Add(time.Minute))
+	requireNoError(t, err)
+	if changed {
+		t.Error("edit with the same normalized text should not count as a change")
+	}
+
+	revisions, err := repo.GetRevisions(ctx, job.ID)
+	requireNoError(t, err)
+	if len(revisions) != 1 || revisions[0].RawContent != "Go developer, 300k" || revisions[0].EditedAt != nil {
+		t.Fatalf("expected the original post as the only revision, got %+v", revisions)
+	}
+
+	// re-analysis keeps the status set by the user
+	requireNoError(t, repo.UpdateStructuredData(ctx, job.ID, map[string]interface{}{"salary": "350k"}))
+	got, err = repo.GetByID(ctx, job.ID)
+	requireNoError(t, err)
+	if got.Status != "INTERESTED" || got.StructuredData["salary"] != "350k" {
+		t.Errorf("expected refreshed data with status INTERESTED, got %s %v", got.Status, got.StructuredData)
+	}
+}
+
+func TestJobsRepository_UpdateContentPromotesDuplicates(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Promote Channel", "http://t.me/promote")
+	requireNoError(t, err)
+
+	post := "Go developer, 300k"
+	canonical := &Job{TargetID: targetID, ExternalID: "1", RawContent: post, Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, canonical))
+	first := &Job{TargetID: targetID, ExternalID: "2", RawContent: post, Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, first))
+	second := &Job{TargetID: targetID, ExternalID: "3", RawContent: post, Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, second))
+
+	// editing the canonical post leaves its reposts without a canonical
+	changed, err := repo.UpdateContent(ctx, canonical.ID, "Python developer, 250k", time.Now().Truncate(time.Second))
+	requireNoError(t, err)
+	if !changed {
+		t.Fatal("expected content change")
+	}
+
+	got, err := repo.GetByID(ctx, canonical.ID)
+	requireNoError(t, err)
+	if got.DuplicateOf != nil {
+		t.Errorf("edited job should no longer be a duplicate, got %v", got.DuplicateOf)
+	}
+
+	promoted, err := repo.GetByID(ctx, first.ID)
+	requireNoError(t, err)
+	if promoted.DuplicateOf != nil {
+		t.Errorf("oldest duplicate should become canonical, got duplicate_of %v", promoted.DuplicateOf)
+	}
+
+	rest, err := repo.GetByID(ctx, second.ID)
+	requireNoError(t, err)
+	if rest.DuplicateOf == nil || *rest.DuplicateOf != first.ID {
+		t.Errorf("expected duplicate of promoted job %s, got %v", first.ID, rest.DuplicateOf)
+	}
+}
+
+func TestJobsRepository_Attachment(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Files Channel", "http://t.me/files")
+	requireNoError(t, err)
+
+	att := &Attachment{
+		Type:          "document",
+		FileName:      "vacancy.pdf",
+		MimeType:      "application/pdf",
+		Size:          2048,
+		FileID:        100,
+		AccessHash:    200,
+		FileReference: []byte{1, 2, 3},
+		DCID:          2,
+		Path:          "storage/attachments/vacancy.pdf",
+		Text:          "Go developer, 300k",
+	}
+	withFile := &Job{TargetID: targetID, ExternalID: "1", RawContent: att.Text, Status: "RAW", Attachment: att}
+	requireNoError(t, repo.Create(ctx, withFile))
+	plain := &Job{TargetID: targetID, ExternalID: "2", RawContent: "Python developer", Status: "RAW"}
+	requireNoError(t, repo.Create(ctx, plain))
+
+	got, err := repo.GetByID(ctx, withFile.ID)
+	requireNoError(t, err)
+	if got.Attachment == nil || got.Attachment.FileName != "vacancy.pdf" || got.Attachment.FileID != 100 ||
+		string(got.Attachment.FileReference) != string(att.FileReference) || got.Attachment.Text != att.Text {
+		t.Errorf("attachment not stored: %+v", got.Attachment)
+	}
+
+	got, err = repo.GetByExternalID(ctx, targetID, "2")
+	requireNoError(t, err)
+	if got.Attachment != nil {
+		t.Errorf("job without attachment should have none, got %+v", got.Attachment)
+	}
+}
+
+func TestJobsRepository_Links(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Links Channel", "http://t.me/links")
+	requireNoError(t, err)
+
+	job := &Job{TargetID: targetID, ExternalID: "1", RawContent: "Go developer, write @hr_anna", Status: "RAW", Links: []Link{
+		{Type: LinkMention, URL: "@hr_anna"},
+		{Type: LinkTextURL, Text: "apply", URL: "https://example.com/apply"},
+	}}
+	requireNoError(t, repo.Create(ctx, job))
+
+	got, err := repo.GetByID(ctx, job.ID)
+	requireNoError(t, err)
+	if len(got.Links) != 2 || got.Links[0].URL != "@hr_anna" || got.Links[1].Text != "apply" {
+		t.Errorf("links not stored: %+v", got.Links)
+	}
+
+	requireNoError(t, repo.UpdateLinks(ctx, job.ID, []Link{{Type: LinkEmail, URL: "hr@example.com"}}))
+	got, err = repo.GetByID(ctx, job.ID)
+	requireNoError(t, err)
+	if len(got.Links) != 1 || got.Links[0].URL != "hr@example.com" {
+		t.Errorf("links not replaced: %+v", got.Links)
+	}
+
+	requireNoError(t, repo.UpdateLinks(ctx, job.ID, nil))
+	got, err = repo.GetByID(ctx, job.ID)
+	requireNoError(t, err)
+	if got.Links != nil {
+		t.Errorf("links not cleared: %+v", got.Links)
+	}
+}
+
+func TestJobsRepository_CloseByMessageIDs(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+	repo := NewJobsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Closed Channel", "http://t.me/closed")
+	requireNoError(t, err)
+
+	jobs := map[int64]*Job{}
+	for i, status := range []string{"RAW", "INTERESTED", "SENT"} {
+		msgID := int64(i + 1)
+		job := &Job{TargetID: targetID, ExternalID: fmt.Sprint(msgID), RawContent: fmt.Sprintf("vacancy %d", msgID), Status: "RAW", TgMessageID: &msgID}
+		requireNoError(t, repo.Create(ctx, job))
+		requireNoError(t, repo.UpdateStatus(ctx, job.ID, status))
+		jobs[msgID] = job
+	}
+
+	// sent jobs are not verified
+	ids, err := repo.ListOpenMessageIDs(ctx, targetID, 0, 100)
+	requireNoError(t, err)
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("expected open message ids [1 2], got %v", ids)
+	}
+	ids, err = repo.ListOpenMessageIDs(ctx, targetID, 1, 100)
+	requireNoError(t, err)
+	if len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("expected open message ids after 1 to be [2], got %v", ids)
+	}
+
+	closed, err := repo.CloseByMessageIDs(ctx, targetID, []int64{2, 3})
+	requireNoError(t, err)
+	if closed != 1 {
+		t.Errorf("expected 1 closed job, got %d", closed)
+	}
+
+	got, err := repo.GetByID(ctx, jobs[2].ID)
+	requireNoError(t, err)
+	if !got.IsClosed() || got.ClosedAt == nil {
+		t.Errorf("expected closed job with closed_at, got %s %v", got.Status, got.ClosedAt)
+	}
+	got, err = repo.GetByID(ctx, jobs[3].ID)
+	requireNoError(t, err)
+	if got.Status != "SENT" {
+		t.Errorf("sent job should keep its status, got %s", got.Status)
+	}
+
+	list, total, err := repo.List(ctx, JobFilter{HideClosed: true})
+	requireNoError(t, err)
+	if total != 2 || len(list) != 2 {
+		t.Errorf("expected 2 jobs without closed ones, got %d", total)
+	}
+
+	// reopening clears closed_at
+	requireNoError(t, repo.UpdateStatus(ctx, jobs[2].ID, "INTERESTED"))
+	got, err = repo.GetByID(ctx, jobs[2].ID)
+	requireNoError(t, err)
+	if got.ClosedAt != nil {
+		t.Errorf("expected closed_at cleared on reopen, got %v", got.ClosedAt)
+	}
+}
+
 func requireNoError(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
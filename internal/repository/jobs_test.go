@@ -1,12 +1,13 @@
 package repository
 
 import (
+	"strings"
 	"testing"
 )
 
 // test job status validation
 func TestJob_IsValidStatus(t *testing.T) {
-	validStatuses := []string{"RAW", "ANALYZED", "REJECTED", "INTERESTED", "TAILORED", "SENT", "RESPONDED"}
+	validStatuses := []string{"RAW", "ANALYZED", "REJECTED", "INTERESTED", "TAILORED", "SENT", "RESPONDED", "CLOSED"}
 
 	for _, status := range validStatuses {
 		job := Job{Status: status}
@@ -55,3 +56,99 @@ func TestJob_ComputeHash(t *testing.T) {
 		t.Error("different content should produce different hash")
 	}
 }
+
+// test content normalization used for duplicate detection
+func TestNormalizeContent(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"lowercase", "Go Developer", "go developer"},
+		{"collapse whitespace", "  go\n\n\tdeveloper  ", "go developer"},
+		{"drop emoji", "🚀 Go developer 👨‍💻", "go developer"},
+		{"drop flags and variation selectors", "Remote 🇷🇺 ❤️ only", "remote only"},
+		{"keep punctuation and cyrillic", "Вакансия: Go-разработчик!", "вакансия: go-разработчик!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeContent(tt.in); got != tt.want {
+				t.Errorf("NormalizeContent(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	a := Job{RawContent: "🔥 Senior Go Developer\n\nRemote"}
+	b := Job{RawContent: "senior go developer remote"}
+	if a.ComputeHash() != b.ComputeHash() {
+		t.Error("reposts differing in case, emoji and whitespace should have the same hash")
+	}
+}
+
+// test contacts from post links and analyzer output
+func TestJob_Contacts(t *testing.T) {
+	job := Job{
+		Links: []Link{
+			{Type: LinkURL, URL: "https://example.com/about"},
+			{Type: LinkTextURL, Text: "Откликнуться", URL: "https://forms.example.com/apply"},
+			{Type: LinkTextURL, Text: "HR", URL: "https://t.me/hr_anna"},
+			{Type: LinkURL, URL: "t.me/golang_jobs/123"},
+			{Type: LinkMention, URL: "@recruiter"},
+			{Type: LinkEmail, URL: "jobs@example.com"},
+			{Type: LinkPhone, URL: "+7 999 123-45-67"},
+		},
+		StructuredData: map[string]interface{}{
+			"contacts": []interface{}{"@Recruiter", "cto@example.com", ""},
+		},
+	}
+
+	wantLinks := []string{"https://forms.example.com/apply", "@hr_anna", "@recruiter", "jobs@example.com", "+7 999 123-45-67"}
+	got := job.LinkContacts()
+	if len(got) != len(wantLinks) {
+		t.Fatalf("LinkContacts() = %v, want %v", got, wantLinks)
+	}
+	for i := range wantLinks {
+		if got[i] != wantLinks[i] {
+			t.Errorf("LinkContacts()[%d] = %q, want %q", i, got[i], wantLinks[i])
+		}
+	}
+
+	all := job.Contacts()
+	if len(all) != len(wantLinks)+1 || all[len(all)-1] != "cto@example.com" {
+		t.Errorf("Contacts() = %v, want link contacts then cto@example.com", all)
+	}
+}
+
+// test that comments are analyzed with the post they reply to
+func TestJob_AnalysisContent(t *testing.T) {
+	post := Job{RawContent: "Go developer"}
+	if got := post.AnalysisContent(); got != "Go developer" {
+		t.Errorf("AnalysisContent() of a post = %q", got)
+	}
+
+	comment := Job{RawContent: "QA too, write @hr", Parent: &ParentPost{MessageID: 42, Text: "Acme is hiring"}}
+	got := comment.AnalysisContent()
+	if !strings.HasPrefix(got, "QA too, write @hr") || !strings.HasSuffix(got, "Acme is hiring") {
+		t.Errorf("AnalysisContent() of a comment = %q", got)
+	}
+}
+
+// test telegram username links
+func TestTelegramUsername(t *testing.T) {
+	tests := map[string]string{
+		"https://t.me/hr_anna":         "hr_anna",
+		"http://telegram.me/hr_anna/":  "hr_anna",
+		"t.me/hr_anna?start=cv":        "hr_anna",
+		"https://t.me/golang_jobs/123": "",
+		"https://t.me/+AbCdEf":         "",
+		"https://t.me/joinchat/AbCdEf": "",
+		"https://example.com/hr_anna":  "",
+		"https://t.me/":                "",
+	}
+	for link, want := range tests {
+		if got := TelegramUsername(link); got != want {
+			t.Errorf("TelegramUsername(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -12,6 +13,7 @@ import (
 type ParsedRange struct {
 	ID       uuid.UUID
 	TargetID uuid.UUID
+	TopicID  int64 // forum topic id, 0 = whole channel
 	MinMsgID int64
 	MaxMsgID int64
 }
@@ -38,17 +40,81 @@ func (r *ParsedRange) Extend(newMin, newMax int64) {
 	}
 }
 
+// RangeSet is a sorted list of disjoint parsed ranges
+type RangeSet []ParsedRange
+
+// Add returns the set with [minID, maxID] added.
+// overlapping and adjacent ranges are merged.
+func (s RangeSet) Add(minID, maxID int64) RangeSet {
+	if minID > maxID {
+		minID, maxID = maxID, minID
+	}
+
+	merged := ParsedRange{MinMsgID: minID, MaxMsgID: maxID}
+	out := make(RangeSet, 0, len(s)+1)
+	inserted := false
+	for _, r := range s {
+		switch {
+		case r.MaxMsgID+1 < merged.MinMsgID:
+			out = append(out, r)
+		case merged.MaxMsgID+1 < r.MinMsgID:
+			if !inserted {
+				out = append(out, merged)
+				inserted = true
+			}
+			out = append(out, r)
+		default:
+			merged.Extend(r.MinMsgID, r.MaxMsgID)
+		}
+	}
+	if !inserted {
+		out = append(out, merged)
+	}
+	return out
+}
+
+// Covering returns the range that contains msgID
+func (s RangeSet) Covering(msgID int64) (ParsedRange, bool) {
+	i := sort.Search(len(s), func(i int) bool { return s[i].MaxMsgID >= msgID })
+	if i < len(s) && s[i].Contains(msgID) {
+		return s[i], true
+	}
+	return ParsedRange{}, false
+}
+
+// Contains checks if a message id is within any range of the set
+func (s RangeSet) Contains(msgID int64) bool {
+	_, ok := s.Covering(msgID)
+	return ok
+}
+
+// Min returns the lowest parsed message id, 0 if the set is empty
+func (s RangeSet) Min() int64 {
+	if len(s) == 0 {
+		return 0
+	}
+	return s[0].MinMsgID
+}
+
+// Max returns the highest parsed message id, 0 if the set is empty
+func (s RangeSet) Max() int64 {
+	if len(s) == 0 {
+		return 0
+	}
+	return s[len(s)-1].MaxMsgID
+}
+
 // MessageIDFilter filters messages based on already parsed ranges
 type MessageIDFilter struct {
-	maxParsed int64
+	ranges RangeSet
 }
 
-// NewMessageIDFilter creates a filter with max parsed message id
-func NewMessageIDFilter(maxParsed int64) *MessageIDFilter {
-	return &MessageIDFilter{maxParsed: maxParsed}
+// NewMessageIDFilter creates a filter over a set of parsed ranges
+func NewMessageIDFilter(ranges RangeSet) *MessageIDFilter {
+	return &MessageIDFilter{ranges: ranges}
 }
 
-// FilterNew returns only message ids that are newer than max parsed
+// FilterNew returns only message ids that are not in any parsed range
 func (f *MessageIDFilter) FilterNew(messageIDs []int64) []int64 {
 	if len(messageIDs) == 0 {
 		return []int64{}
@@ -56,7 +122,7 @@ func (f *MessageIDFilter) FilterNew(messageIDs []int64) []int64 {
 
 	var newIDs []int64
 	for _, id := range messageIDs {
-		if id > f.maxParsed {
+		if !f.ranges.Contains(id) {
 			newIDs = append(newIDs, id)
 		}
 	}
@@ -67,6 +133,11 @@ func (f *MessageIDFilter) FilterNew(messageIDs []int64) []int64 {
 	return newIDs
 }
 
+// Ranges returns the parsed ranges the filter was built from
+func (f *MessageIDFilter) Ranges() RangeSet {
+	return f.ranges
+}
+
 // RangesRepository handles parsed_ranges table operations
 type RangesRepository struct {
 	pool *pgxpool.Pool
@@ -77,54 +148,102 @@ func NewRangesRepository(pool *pgxpool.Pool) *RangesRepository {
 	return &RangesRepository{pool: pool}
 }
 
-// GetRange returns the parsed range for a target, or nil if not exists
-func (r *RangesRepository) GetRange(ctx context.Context, targetID uuid.UUID) (*ParsedRange, error) {
-	var pr ParsedRange
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, target_id, min_msg_id, max_msg_id
-		FROM parsed_ranges
-		WHERE target_id = $1
-	`, targetID).Scan(&pr.ID, &pr.TargetID, &pr.MinMsgID, &pr.MaxMsgID)
+// GetRanges returns the parsed ranges of a target, empty if nothing was parsed yet
+func (r *RangesRepository) GetRanges(ctx context.Context, targetID uuid.UUID) (RangeSet, error) {
+	return r.GetTopicRanges(ctx, targetID, 0)
+}
 
+// GetTopicRanges returns the parsed ranges of a forum topic of a target, ordered by min id.
+// topicID 0 is the whole channel history.
+func (r *RangesRepository) GetTopicRanges(ctx context.Context, targetID uuid.UUID, topicID int64) (RangeSet, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, target_id, topic_id, min_msg_id, max_msg_id
+		FROM parsed_ranges
+		WHERE target_id = $1 AND topic_id = $2
+		ORDER BY min_msg_id
+	`, targetID, topicID)
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			return nil, nil // no range exists yet
+		return nil, fmt.Errorf("get parsed ranges: %w", err)
+	}
+	defer rows.Close()
+
+	var set RangeSet
+	for rows.Next() {
+		var pr ParsedRange
+		if err := rows.Scan(&pr.ID, &pr.TargetID, &pr.TopicID, &pr.MinMsgID, &pr.MaxMsgID); err != nil {
+			return nil, fmt.Errorf("scan parsed range: %w", err)
 		}
-		return nil, fmt.Errorf("get parsed range: %w", err)
+		set = append(set, pr)
 	}
 
-	return &pr, nil
+	return set, rows.Err()
 }
 
-// UpdateRange creates or extends the parsed range for a target
-// uses upsert - if range exists, it extends; otherwise creates new
+// UpdateRange adds a scraped range to the parsed ranges of a target
 func (r *RangesRepository) UpdateRange(ctx context.Context, targetID uuid.UUID, minID, maxID int64) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO parsed_ranges (target_id, min_msg_id, max_msg_id)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (target_id)
-		DO UPDATE SET
-			min_msg_id = LEAST(parsed_ranges.min_msg_id, $2),
-			max_msg_id = GREATEST(parsed_ranges.max_msg_id, $3),
-			updated_at = NOW()
-	`, targetID, minID, maxID)
+	return r.UpdateTopicRange(ctx, targetID, 0, minID, maxID)
+}
+
+// UpdateTopicRange adds a scraped range to the parsed ranges of a forum topic.
+// overlapping and adjacent ranges are merged into one row, other ranges are kept as gaps.
+func (r *RangesRepository) UpdateTopicRange(ctx context.Context, targetID uuid.UUID, topicID, minID, maxID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("update parsed range: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// serialize range updates of the same target
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM scraping_targets WHERE id = $1 FOR UPDATE`, targetID); err != nil {
+		return fmt.Errorf("update parsed range: lock target: %w", err)
+	}
 
+	var mergedMin, mergedMax *int64
+	err = tx.QueryRow(ctx, `
+		WITH removed AS (
+			DELETE FROM parsed_ranges
+			WHERE target_id = $1 AND topic_id = $2
+			  AND min_msg_id <= $4 + 1 AND max_msg_id >= $3 - 1
+			RETURNING min_msg_id, max_msg_id
+		)
+		SELECT MIN(min_msg_id), MAX(max_msg_id) FROM removed
+	`, targetID, topicID, minID, maxID).Scan(&mergedMin, &mergedMax)
+	if err != nil {
+		return fmt.Errorf("update parsed range: merge: %w", err)
+	}
+
+	if mergedMin != nil && *mergedMin < minID {
+		minID = *mergedMin
+	}
+	if mergedMax != nil && *mergedMax > maxID {
+		maxID = *mergedMax
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO parsed_ranges (target_id, topic_id, min_msg_id, max_msg_id)
+		VALUES ($1, $2, $3, $4)
+	`, targetID, topicID, minID, maxID)
 	if err != nil {
 		return fmt.Errorf("update parsed range: %w", err)
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
 
 // GetMaxMessageID returns the maximum parsed message id for a target
 // returns 0 if no range exists (meaning all messages are new)
 func (r *RangesRepository) GetMaxMessageID(ctx context.Context, targetID uuid.UUID) (int64, error) {
+	return r.GetTopicMaxMessageID(ctx, targetID, 0)
+}
+
+// GetTopicMaxMessageID returns the maximum parsed message id for a forum topic of a target
+func (r *RangesRepository) GetTopicMaxMessageID(ctx context.Context, targetID uuid.UUID, topicID int64) (int64, error) {
 	var maxID int64
 	err := r.pool.QueryRow(ctx, `
-		SELECT COALESCE(max_msg_id, 0)
+		SELECT COALESCE(MAX(max_msg_id), 0)
 		FROM parsed_ranges
-		WHERE target_id = $1
-	`, targetID).Scan(&maxID)
+		WHERE target_id = $1 AND topic_id = $2
+	`, targetID, topicID).Scan(&maxID)
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -136,11 +255,16 @@ func (r *RangesRepository) GetMaxMessageID(ctx context.Context, targetID uuid.UU
 	return maxID, nil
 }
 
-// NewFilter creates a message filter for a target based on its parsed range
+// NewFilter creates a message filter for a target based on its parsed ranges
 func (r *RangesRepository) NewFilter(ctx context.Context, targetID uuid.UUID) (*MessageIDFilter, error) {
-	maxID, err := r.GetMaxMessageID(ctx, targetID)
+	return r.NewTopicFilter(ctx, targetID, 0)
+}
+
+// NewTopicFilter creates a message filter for a forum topic of a target
+func (r *RangesRepository) NewTopicFilter(ctx context.Context, targetID uuid.UUID, topicID int64) (*MessageIDFilter, error) {
+	ranges, err := r.GetTopicRanges(ctx, targetID, topicID)
 	if err != nil {
 		return nil, err
 	}
-	return NewMessageIDFilter(maxID), nil
+	return NewMessageIDFilter(ranges), nil
 }
@@ -8,57 +8,63 @@ import (
 func TestMessageIDFilter_FilterNew(t *testing.T) {
 	tests := []struct {
 		name        string
-		maxParsed   int64
+		ranges      RangeSet
 		inputIDs    []int64
 		expectedIDs []int64
 	}{
 		{
 			name:        "all new when no parsed",
-			maxParsed:   0,
+			ranges:      nil,
 			inputIDs:    []int64{100, 101, 102},
 			expectedIDs: []int64{100, 101, 102},
 		},
 		{
 			name:        "filters out old messages",
-			maxParsed:   100,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 100}},
 			inputIDs:    []int64{99, 100, 101, 102},
 			expectedIDs: []int64{101, 102},
 		},
 		{
 			name:        "returns empty when all old",
-			maxParsed:   200,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 200}},
 			inputIDs:    []int64{99, 100, 101},
 			expectedIDs: []int64{},
 		},
 		{
 			name:        "handles empty input",
-			maxParsed:   100,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 100}},
 			inputIDs:    []int64{},
 			expectedIDs: []int64{},
 		},
 		{
 			name:        "handles nil input",
-			maxParsed:   100,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 100}},
 			inputIDs:    nil,
 			expectedIDs: []int64{},
 		},
 		{
 			name:        "boundary case - exactly at max",
-			maxParsed:   100,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 100}},
 			inputIDs:    []int64{100},
 			expectedIDs: []int64{},
 		},
 		{
 			name:        "boundary case - just above max",
-			maxParsed:   100,
+			ranges:      RangeSet{{MinMsgID: 1, MaxMsgID: 100}},
 			inputIDs:    []int64{101},
 			expectedIDs: []int64{101},
 		},
+		{
+			name:        "keeps ids in gap between ranges",
+			ranges:      RangeSet{{MinMsgID: 10, MaxMsgID: 50}, {MinMsgID: 80, MaxMsgID: 100}},
+			inputIDs:    []int64{101, 90, 79, 60, 51, 50, 9},
+			expectedIDs: []int64{101, 79, 60, 51, 9},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filter := NewMessageIDFilter(tt.maxParsed)
+			filter := NewMessageIDFilter(tt.ranges)
 			result := filter.FilterNew(tt.inputIDs)
 
 			// handle nil vs empty slice comparison
@@ -80,6 +86,95 @@ func TestMessageIDFilter_FilterNew(t *testing.T) {
 	}
 }
 
+// test range set merging
+func TestRangeSet_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial RangeSet
+		minID   int64
+		maxID   int64
+		want    RangeSet
+	}{
+		{
+			name:  "first range",
+			minID: 100,
+			maxID: 200,
+			want:  RangeSet{{MinMsgID: 100, MaxMsgID: 200}},
+		},
+		{
+			name:    "keeps gap below",
+			initial: RangeSet{{MinMsgID: 100, MaxMsgID: 200}},
+			minID:   10,
+			maxID:   50,
+			want:    RangeSet{{MinMsgID: 10, MaxMsgID: 50}, {MinMsgID: 100, MaxMsgID: 200}},
+		},
+		{
+			name:    "keeps gap above",
+			initial: RangeSet{{MinMsgID: 100, MaxMsgID: 200}},
+			minID:   300,
+			maxID:   400,
+			want:    RangeSet{{MinMsgID: 100, MaxMsgID: 200}, {MinMsgID: 300, MaxMsgID: 400}},
+		},
+		{
+			name:    "merges overlapping",
+			initial: RangeSet{{MinMsgID: 100, MaxMsgID: 200}},
+			minID:   150,
+			maxID:   300,
+			want:    RangeSet{{MinMsgID: 100, MaxMsgID: 300}},
+		},
+		{
+			name:    "merges adjacent",
+			initial: RangeSet{{MinMsgID: 100, MaxMsgID: 200}},
+			minID:   201,
+			maxID:   250,
+			want:    RangeSet{{MinMsgID: 100, MaxMsgID: 250}},
+		},
+		{
+			name:    "fills gap between ranges",
+			initial: RangeSet{{MinMsgID: 10, MaxMsgID: 50}, {MinMsgID: 100, MaxMsgID: 200}, {MinMsgID: 500, MaxMsgID: 600}},
+			minID:   40,
+			maxID:   120,
+			want:    RangeSet{{MinMsgID: 10, MaxMsgID: 200}, {MinMsgID: 500, MaxMsgID: 600}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.initial.Add(tt.minID, tt.maxID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Add() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].MinMsgID != tt.want[i].MinMsgID || got[i].MaxMsgID != tt.want[i].MaxMsgID {
+					t.Errorf("Add()[%d] = [%d, %d], want [%d, %d]", i,
+						got[i].MinMsgID, got[i].MaxMsgID, tt.want[i].MinMsgID, tt.want[i].MaxMsgID)
+				}
+			}
+		})
+	}
+}
+
+// test range lookup in a set
+func TestRangeSet_Covering(t *testing.T) {
+	set := RangeSet{{MinMsgID: 10, MaxMsgID: 50}, {MinMsgID: 100, MaxMsgID: 200}}
+
+	if r, ok := set.Covering(150); !ok || r.MinMsgID != 100 {
+		t.Errorf("Covering(150) = %+v, %v, want range starting at 100", r, ok)
+	}
+	if _, ok := set.Covering(75); ok {
+		t.Error("Covering(75) should report a gap")
+	}
+	if _, ok := set.Covering(201); ok {
+		t.Error("Covering(201) should be above the set")
+	}
+	if set.Min() != 10 || set.Max() != 200 {
+		t.Errorf("Min/Max = %d/%d, want 10/200", set.Min(), set.Max())
+	}
+	if RangeSet(nil).Min() != 0 {
+		t.Error("empty set Min should be 0")
+	}
+}
+
 // test range contains check
 func TestParsedRange_Contains(t *testing.T) {
 	tests := []struct {
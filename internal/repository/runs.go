@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScrapeRun represents a single scrape run with its statistics
+type ScrapeRun struct {
+	ID       uuid.UUID       `json:"id"`
+	TargetID *uuid.UUID      `json:"target_id,omitempty"`
+	Channel  string          `json:"channel"`
+	Options  json.RawMessage `json:"options"`
+
+	Status string  `json:"status"`
+	Error  *string `json:"error,omitempty"`
+
+	TotalFetched    int `json:"total_fetched"`
+	NewJobs         int `json:"new_jobs"`
+	SkippedOld      int `json:"skipped_old"`
+	SkippedEmpty    int `json:"skipped_empty"`
+	SkippedFiltered int `json:"skipped_filtered"`
+	EditedJobs      int `json:"edited_jobs"`
+	Errors          int `json:"errors"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Resumable is set for finished runs with checkpoints short of their end
+	Resumable bool `json:"resumable"`
+}
+
+// RunFilter defines filtering options for scrape runs
+type RunFilter struct {
+	TargetID *uuid.UUID
+	Status   string
+	Page     int
+	Limit    int
+}
+
+// RunsRepository handles scrape_runs table operations
+type RunsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRunsRepository creates a new runs repository
+func NewRunsRepository(pool *pgxpool.Pool) *RunsRepository {
+	return &RunsRepository{pool: pool}
+}
+
+// Create inserts a run when it starts
+func (r *RunsRepository) Create(ctx context.Context, run *ScrapeRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	if run.Status == "" {
+		run.Status = "running"
+	}
+	if len(run.Options) == 0 {
+		run.Options = json.RawMessage("{}")
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO scrape_runs (id, target_id, channel, options, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING started_at
+	`, run.ID, run.TargetID, run.Channel, run.Options, run.Status).Scan(&run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("create scrape run: %w", err)
+	}
+	return nil
+}
+
+// Finish stores the outcome and statistics of a run
+func (r *RunsRepository) Finish(ctx context.Context, run *ScrapeRun) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE scrape_runs
+		SET target_id = COALESCE($2, target_id),
+		    status = $3,
+		    error = $4,
+		    total_fetched = $5,
+		    new_jobs = $6,
+		    skipped_old = $7,
+		    skipped_empty = $8,
+		    skipped_filtered = $9,
+		    edited_jobs = $10,
+		    errors = $11,
+		    finished_at = NOW()
+		WHERE id = $1
+		RETURNING finished_at
+	`, run.ID, run.TargetID, run.Status, run.Error,
+		run.TotalFetched, run.NewJobs, run.SkippedOld, run.SkippedEmpty, run.SkippedFiltered, run.EditedJobs, run.Errors,
+	).Scan(&run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("finish scrape run: %w", err)
+	}
+	return nil
+}
+
+// MarkInterrupted sets the status of runs left running by a previous
+// process to interrupted and returns their count
+func (r *RunsRepository) MarkInterrupted(ctx context.Context) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE scrape_runs
+		SET status = 'interrupted', finished_at = NOW()
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("mark interrupted scrape runs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// runResumable selects whether a run can be resumed: it is over and one of
+// its streams did not reach its end
+const runResumable = `(status <> 'running' AND EXISTS (
+		SELECT 1 FROM scrape_checkpoints c WHERE c.run_id = scrape_runs.id AND NOT c.done
+	)) AS resumable`
+
+// GetByID returns a run by id, nil if not found
+func (r *RunsRepository) GetByID(ctx context.Context, id uuid.UUID) (*ScrapeRun, error) {
+	query := `
+		SELECT id, target_id, channel, options, status, error,
+		       total_fetched, new_jobs, skipped_old, skipped_empty, skipped_filtered, edited_jobs, errors,
+		       started_at, finished_at, ` + runResumable + `
+		FROM scrape_runs
+		WHERE id = $1
+	`
+	var run ScrapeRun
+	var channel *string
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&run.ID, &run.TargetID, &channel, &run.Options, &run.Status, &run.Error,
+		&run.TotalFetched, &run.NewJobs, &run.SkippedOld, &run.SkippedEmpty, &run.SkippedFiltered, &run.EditedJobs, &run.Errors,
+		&run.StartedAt, &run.FinishedAt, &run.Resumable,
+	)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get scrape run: %w", err)
+	}
+	if channel != nil {
+		run.Channel = *channel
+	}
+	return &run, nil
+}
+
+// List returns runs ordered by start time, newest first, and the total count
+func (r *RunsRepository) List(ctx context.Context, filter RunFilter) ([]*ScrapeRun, int, error) {
+	query := `
+		SELECT id, target_id, channel, options, status, error,
+		       total_fetched, new_jobs, skipped_old, skipped_empty, skipped_filtered, edited_jobs, errors,
+		       started_at, finished_at, ` + runResumable + `,
+		       COUNT(*) OVER() as total_count
+		FROM scrape_runs
+		WHERE 1=1
+	`
+	var args []interface{}
+	argID := 1
+
+	if filter.TargetID != nil {
+		query += fmt.Sprintf(" AND target_id = $%d", argID)
+		args = append(args, *filter.TargetID)
+		argID++
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argID)
+		args = append(args, filter.Status)
+		argID++
+	}
+
+	query += " ORDER BY started_at DESC"
+
+	limit := 50
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	query += fmt.Sprintf(" LIMIT $%d", argID)
+	args = append(args, limit)
+	argID++
+
+	offset := 0
+	if filter.Page > 1 {
+		offset = (filter.Page - 1) * limit
+	}
+	query += fmt.Sprintf(" OFFSET $%d", argID)
+	args = append(args, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*ScrapeRun{}
+	var total int
+	for rows.Next() {
+		var run ScrapeRun
+		var channel *string
+		if err := rows.Scan(
+			&run.ID, &run.TargetID, &channel, &run.Options, &run.Status, &run.Error,
+			&run.TotalFetched, &run.NewJobs, &run.SkippedOld, &run.SkippedEmpty, &run.SkippedFiltered, &run.EditedJobs, &run.Errors,
+			&run.StartedAt, &run.FinishedAt, &run.Resumable,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan scrape run: %w", err)
+		}
+		if channel != nil {
+			run.Channel = *channel
+		}
+		runs = append(runs, &run)
+	}
+
+	return runs, total, rows.Err()
+}
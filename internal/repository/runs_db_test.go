@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestRunsRepository_CreateFinishList(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+
+	repo := NewRunsRepository(db.Pool)
+
+	targetID := uuid.New()
+	_, err = db.Pool.Exec(ctx, "INSERT INTO scraping_targets (id, name, url, type, is_active, created_at, updated_at) VALUES ($1, $2, $3, 'TG_CHANNEL', true, NOW(), NOW())", targetID, "Test Channel", "@test")
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+
+	// 1. Create a run without target (resolved later)
+	run := &ScrapeRun{
+		ID:      uuid.New(),
+		Channel: "@test",
+		Options: json.RawMessage(`{"channel":"@test","limit":10}`),
+	}
+	if err := repo.Create(ctx, run); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if run.StartedAt.IsZero() {
+		t.Error("expected started_at to be set")
+	}
+
+	// 2. Finish with statistics and resolved target
+	errText := "resolve channel: not found"
+	run.TargetID = &targetID
+	run.Status = "failed"
+	run.Error = &errText
+	run.TotalFetched = 10
+	run.SkippedOld = 10
+	if err := repo.Finish(ctx, run); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	// 3. GetByID
+	fetched, err := repo.GetByID(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if fetched == nil {
+		t.Fatal("GetByID returned nil")
+	}
+	if fetched.Status != "failed" || fetched.Error == nil || *fetched.Error != errText {
+		t.Errorf("unexpected outcome: status=%s error=%v", fetched.Status, fetched.Error)
+	}
+	if fetched.TotalFetched != 10 || fetched.SkippedOld != 10 {
+		t.Errorf("unexpected counters: %+v", fetched)
+	}
+	if fetched.TargetID == nil || *fetched.TargetID != targetID {
+		t.Errorf("expected target_id %v, got %v", targetID, fetched.TargetID)
+	}
+	if fetched.FinishedAt == nil {
+		t.Error("expected finished_at to be set")
+	}
+
+	// 4. List filtered by target
+	runs, total, err := repo.List(ctx, RunFilter{TargetID: &targetID})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 1 || len(runs) != 1 {
+		t.Errorf("expected 1 run, got %d (total %d)", len(runs), total)
+	}
+
+	// 5. Unknown id
+	missing, err := repo.GetByID(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("GetByID(unknown) failed: %v", err)
+	}
+	if missing != nil {
+		t.Error("expected nil for unknown run")
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// SimHash returns a 64-bit similarity fingerprint of normalized text.
+// every word votes on each bit with its fnv hash, so posts that differ
+// by a few words (salary, hashtags, one line) end up a few bits apart.
+// returns 0 for text without words.
+func SimHash(text string) uint64 {
+	words := strings.FieldsFunc(NormalizeContent(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var votes [64]int
+	for _, w := range words {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(w))
+		sum := h.Sum64()
+		for i := range votes {
+			if sum&(1<<uint(i)) != 0 {
+				votes[i]++
+			} else {
+				votes[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i, v := range votes {
+		if v > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// HammingDistance returns the number of differing bits of two fingerprints
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+const simhashPost = `Senior Go Developer
+Компания: TechCorp
+Зарплата: 300 000 - 400 000 руб
+Удаленно, полный день
+Стек: Go, PostgreSQL, Kafka, Kubernetes
+Требования: опыт от 4 лет, знание конкурентности в Go
+Контакты: @hr_techcorp`
+
+func TestSimHash_NearDuplicates(t *testing.T) {
+	base := SimHash(simhashPost)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"same text", simhashPost},
+		{"case, emoji and whitespace", "🔥 " + strings.ToUpper(simhashPost) + "\n\n"},
+		{"changed salary", strings.Replace(simhashPost, "300 000 - 400 000", "350 000 - 450 000", 1)},
+		{"added hashtags", simhashPost + "\n#вакансия #golang"},
+		{"removed line", strings.Replace(simhashPost, "Удаленно, полный день\n", "", 1)},
+		{"changed grade", strings.Replace(simhashPost, "Senior", "Middle", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := HammingDistance(base, SimHash(tt.text)); d > DefaultClusterMaxDistance {
+				t.Errorf("distance = %d, want <= %d", d, DefaultClusterMaxDistance)
+			}
+		})
+	}
+}
+
+func TestSimHash_DifferentPosts(t *testing.T) {
+	other := `Golang разработчик (Middle+)
+Компания: FinTech Bank
+Зарплата: до 350 000 руб
+Гибрид, Санкт-Петербург
+Стек: Go, PostgreSQL, gRPC, Docker
+Требования: опыт от 3 лет коммерческой разработки на Go
+Резюме: @fintech_hr`
+
+	if d := HammingDistance(SimHash(simhashPost), SimHash(other)); d <= DefaultClusterMaxDistance {
+		t.Errorf("distance between different vacancies = %d, want > %d", d, DefaultClusterMaxDistance)
+	}
+}
+
+func TestSimHash_Empty(t *testing.T) {
+	if got := SimHash(" 🚀 \n "); got != 0 {
+		t.Errorf("SimHash of text without words = %d, want 0", got)
+	}
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -14,7 +16,7 @@ import (
 type ScrapingTarget struct {
 	ID            uuid.UUID              `json:"id"`
 	Name          string                 `json:"name"`
-	Type          string                 `json:"type"` // TG_CHANNEL, TG_GROUP, TG_FORUM, HH_SEARCH, LINKEDIN_SEARCH
+	Type          string                 `json:"type"` // TG_CHANNEL, TG_GROUP, TG_FORUM, HH_SEARCH, LINKEDIN_SEARCH, FEED
 	URL           string                 `json:"url"`
 	TgAccessHash  *int64                 `json:"tg_access_hash,omitempty"`
 	TgChannelID   *int64                 `json:"tg_channel_id,omitempty"`
@@ -22,8 +24,30 @@ type ScrapingTarget struct {
 	LastScrapedAt *time.Time             `json:"last_scraped_at,omitempty"`
 	LastMessageID *int64                 `json:"last_message_id,omitempty"`
 	IsActive      bool                   `json:"is_active"`
-	CreatedAt     time.Time              `json:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at"`
+	// validators of the last fetched feed, for conditional GET
+	HTTPETag         *string `json:"http_etag,omitempty"`
+	HTTPLastModified *string `json:"http_last_modified,omitempty"`
+	TargetHealth
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TargetHealth tracks the outcome of the scrape runs of a target
+type TargetHealth struct {
+	ConsecutiveFailures int        `json:"consecutive_failures"` // failed runs since the last successful one
+	LastError           *string    `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	SuccessfulRuns      int        `json:"successful_runs"`
+	AvgNewJobs          float64    `json:"avg_new_jobs"` // per successful run
+	// DisabledAt is set when the target was deactivated after too many
+	// consecutive failures, nil for targets turned off by hand
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// Healthy reports whether the last run of the target succeeded
+func (h TargetHealth) Healthy() bool {
+	return h.ConsecutiveFailures == 0 && h.DisabledAt == nil
 }
 
 // valid target types
@@ -33,6 +57,7 @@ var validTargetTypes = map[string]bool{
 	"TG_FORUM":        true,
 	"HH_SEARCH":       true,
 	"LINKEDIN_SEARCH": true,
+	"FEED":            true,
 }
 
 // IsValid checks if target has valid type
@@ -45,11 +70,109 @@ func (t *ScrapingTarget) IsTelegram() bool {
 	return strings.HasPrefix(t.Type, "TG_")
 }
 
+// IsHH checks if target is a hh.ru vacancy search
+func (t *ScrapingTarget) IsHH() bool {
+	return t.Type == "HH_SEARCH"
+}
+
+// IsFeed checks if target is a rss/atom or json feed
+func (t *ScrapingTarget) IsFeed() bool {
+	return t.Type == "FEED"
+}
+
 // IsForum checks if target is a telegram forum
 func (t *ScrapingTarget) IsForum() bool {
 	return t.Type == "TG_FORUM"
 }
 
+// Username returns the public username of a telegram target, from its url
+// ("@name", "name" or "https://t.me/name"). empty for invite links and
+// channel ids.
+func (t *ScrapingTarget) Username() string {
+	ref := strings.TrimSpace(t.URL)
+	if strings.Contains(ref, "/") {
+		return TelegramUsername(ref)
+	}
+	ref = strings.TrimPrefix(ref, "@")
+	// usernames start with a letter, a number is a channel id
+	if len(ref) < 4 || !unicode.IsLetter(rune(ref[0])) || strings.IndexFunc(ref, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) >= 0 {
+		return ""
+	}
+	return ref
+}
+
+// InviteHash returns the hash of the invite link of a private telegram
+// target ("https://t.me/+<hash>" or "https://t.me/joinchat/<hash>"),
+// empty for other urls
+func (t *ScrapingTarget) InviteHash() string {
+	path := telegramPath(strings.TrimSpace(t.URL))
+	hash, ok := strings.CutPrefix(path, "+")
+	if !ok {
+		hash, ok = strings.CutPrefix(path, "joinchat/")
+	}
+	if !ok || hash == "" || strings.Contains(hash, "/") {
+		return ""
+	}
+	return hash
+}
+
+// URLChannelID returns the channel id a private telegram target is defined
+// by: "https://t.me/c/<id>", "-100<id>" (bot api form) or "<id>".
+// 0 for other urls.
+func (t *ScrapingTarget) URLChannelID() int64 {
+	ref := strings.TrimSpace(t.URL)
+	if strings.Contains(ref, "/") {
+		rest, ok := strings.CutPrefix(telegramPath(ref), "c/")
+		if !ok {
+			return 0
+		}
+		// a message link (t.me/c/<id>/<msg>) names its channel too
+		ref, _, _ = strings.Cut(rest, "/")
+	} else {
+		ref = strings.TrimPrefix(ref, "-100")
+	}
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// Permalink returns the link to a message of a telegram target:
+// https://t.me/<username>/<id> for public channels and groups,
+// https://t.me/c/<channel_id>/<id> for private ones, with the topic id
+// before the message id for forum messages outside the general topic.
+// empty when the target has neither a username nor a known channel id.
+func (t *ScrapingTarget) Permalink(msgID int64, topicID *int64) string {
+	var base string
+	if username := t.Username(); username != "" {
+		base = "https://t.me/" + username
+	} else if t.TgChannelID != nil && *t.TgChannelID != 0 {
+		base = fmt.Sprintf("https://t.me/c/%d", *t.TgChannelID)
+	} else if id := t.URLChannelID(); id != 0 {
+		base = fmt.Sprintf("https://t.me/c/%d", id)
+	} else {
+		return ""
+	}
+
+	if topicID != nil && *topicID > 1 && *topicID != msgID {
+		return fmt.Sprintf("%s/%d/%d", base, *topicID, msgID)
+	}
+	return fmt.Sprintf("%s/%d", base, msgID)
+}
+
+// CommentPermalink returns the link to a comment on a channel post
+// (https://t.me/<username>/<post>?comment=<id>), empty like Permalink
+func (t *ScrapingTarget) CommentPermalink(postID, commentID int64) string {
+	post := t.Permalink(postID, nil)
+	if post == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s?comment=%d", post, commentID)
+}
+
 // TargetsRepository handles scraping_targets table operations
 type TargetsRepository struct {
 	pool *pgxpool.Pool
@@ -66,13 +189,15 @@ func (r *TargetsRepository) GetByID(ctx context.Context, id uuid.UUID) (*Scrapin
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, name, type, url, tg_access_hash, tg_channel_id, 
 		       metadata, last_scraped_at, last_message_id, is_active, 
-		       created_at, updated_at
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
 		FROM scraping_targets
 		WHERE id = $1
 	`, id).Scan(
 		&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
 		&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+		&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -92,13 +217,15 @@ func (r *TargetsRepository) GetByURL(ctx context.Context, url string) (*Scraping
 	err := r.pool.QueryRow(ctx, `
 		SELECT id, name, type, url, tg_access_hash, tg_channel_id, 
 		       metadata, last_scraped_at, last_message_id, is_active, 
-		       created_at, updated_at
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
 		FROM scraping_targets
 		WHERE url = $1 OR url = '@' || $1
 	`, url).Scan(
 		&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
 		&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+		&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -109,12 +236,40 @@ func (r *TargetsRepository) GetByURL(ctx context.Context, url string) (*Scraping
 	return &t, nil
 }
 
+// GetByChannelID returns the telegram target of a channel id, nil if none
+func (r *TargetsRepository) GetByChannelID(ctx context.Context, channelID int64) (*ScrapingTarget, error) {
+	var t ScrapingTarget
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, type, url, tg_access_hash, tg_channel_id,
+		       metadata, last_scraped_at, last_message_id, is_active,
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
+		FROM scraping_targets
+		WHERE tg_channel_id = $1 AND type LIKE 'TG\_%'
+		ORDER BY created_at
+		LIMIT 1
+	`, channelID).Scan(
+		&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
+		&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
+		&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+		&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get target by channel id: %w", err)
+	}
+	return &t, nil
+}
+
 // GetActive returns all active targets
 func (r *TargetsRepository) GetActive(ctx context.Context) ([]ScrapingTarget, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, name, type, url, tg_access_hash, tg_channel_id, 
 		       metadata, last_scraped_at, last_message_id, is_active, 
-		       created_at, updated_at
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
 		FROM scraping_targets
 		WHERE is_active = true
 		ORDER BY name
@@ -130,7 +285,8 @@ func (r *TargetsRepository) GetActive(ctx context.Context) ([]ScrapingTarget, er
 		if err := rows.Scan(
 			&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
 			&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+			&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan target: %w", err)
 		}
@@ -180,12 +336,103 @@ func (r *TargetsRepository) UpdateTelegramInfo(ctx context.Context, id uuid.UUID
 	return nil
 }
 
+// UpdateHTTPCache stores the validators of the last fetched feed (empty = none)
+func (r *TargetsRepository) UpdateHTTPCache(ctx context.Context, id uuid.UUID, etag, lastModified string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE scraping_targets
+		SET http_etag = NULLIF($2, ''), http_last_modified = NULLIF($3, ''), updated_at = NOW()
+		WHERE id = $1
+	`, id, etag, lastModified)
+	if err != nil {
+		return fmt.Errorf("update http cache: %w", err)
+	}
+	return nil
+}
+
+// RecordSuccess resets the failures of a target after a successful run and
+// adds its new jobs to the average
+func (r *TargetsRepository) RecordSuccess(ctx context.Context, id uuid.UUID, newJobs int) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE scraping_targets
+		SET consecutive_failures = 0,
+		    last_success_at = NOW(),
+		    avg_new_jobs = (avg_new_jobs * successful_runs + $2) / (successful_runs + 1),
+		    successful_runs = successful_runs + 1,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, newJobs)
+	if err != nil {
+		return fmt.Errorf("record target success: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure counts a failed run of a target and deactivates it once
+// maxFailures consecutive runs failed (0 = never).
+// returns true if the target was deactivated by this failure.
+func (r *TargetsRepository) RecordFailure(ctx context.Context, id uuid.UUID, errText string, maxFailures int) (bool, error) {
+	var disabled bool
+	err := r.pool.QueryRow(ctx, `
+		UPDATE scraping_targets
+		SET consecutive_failures = consecutive_failures + 1,
+		    last_error = $2,
+		    last_error_at = NOW(),
+		    is_active = is_active AND NOT ($3 > 0 AND consecutive_failures + 1 >= $3),
+		    disabled_at = CASE WHEN is_active AND $3 > 0 AND consecutive_failures + 1 >= $3
+		                       THEN NOW() ELSE disabled_at END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING COALESCE(disabled_at = NOW(), false)
+	`, id, errText, maxFailures).Scan(&disabled)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return false, nil
+		}
+		return false, fmt.Errorf("record target failure: %w", err)
+	}
+	return disabled, nil
+}
+
+// ListUnhealthy returns the targets whose last run failed and the ones
+// deactivated after consecutive failures, most failures first
+func (r *TargetsRepository) ListUnhealthy(ctx context.Context) ([]ScrapingTarget, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, type, url, tg_access_hash, tg_channel_id,
+		       metadata, last_scraped_at, last_message_id, is_active,
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
+		FROM scraping_targets
+		WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+		ORDER BY consecutive_failures DESC, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list unhealthy targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []ScrapingTarget
+	for rows.Next() {
+		var t ScrapingTarget
+		if err := rows.Scan(
+			&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
+			&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
+			&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+			&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
 // List returns all targets (active and inactive)
 func (r *TargetsRepository) List(ctx context.Context) ([]ScrapingTarget, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT id, name, type, url, tg_access_hash, tg_channel_id, 
 		       metadata, last_scraped_at, last_message_id, is_active, 
-		       created_at, updated_at
+		       http_etag, http_last_modified, consecutive_failures, last_error, last_error_at,
+		       last_success_at, successful_runs, avg_new_jobs, disabled_at, created_at, updated_at
 		FROM scraping_targets
 		ORDER BY name
 	`)
@@ -200,7 +447,8 @@ func (r *TargetsRepository) List(ctx context.Context) ([]ScrapingTarget, error)
 		if err := rows.Scan(
 			&t.ID, &t.Name, &t.Type, &t.URL, &t.TgAccessHash, &t.TgChannelID,
 			&t.Metadata, &t.LastScrapedAt, &t.LastMessageID, &t.IsActive,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.HTTPETag, &t.HTTPLastModified, &t.ConsecutiveFailures, &t.LastError, &t.LastErrorAt,
+			&t.LastSuccessAt, &t.SuccessfulRuns, &t.AvgNewJobs, &t.DisabledAt, &t.CreatedAt, &t.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan target: %w", err)
 		}
@@ -209,13 +457,19 @@ func (r *TargetsRepository) List(ctx context.Context) ([]ScrapingTarget, error)
 	return targets, nil
 }
 
-// Update updates a target
+// Update updates a target, with its telegram channel id and access hash.
+// turning a target back on gives it a fresh run of failures.
 func (r *TargetsRepository) Update(ctx context.Context, t *ScrapingTarget) error {
-	_, err := r.pool.Exec(ctx, `
+	err := r.pool.QueryRow(ctx, `
 		UPDATE scraping_targets
-		SET name = $2, type = $3, url = $4, metadata = $5, is_active = $6, updated_at = NOW()
+		SET name = $2, type = $3, url = $4, metadata = $5, is_active = $6,
+		    tg_access_hash = $7, tg_channel_id = $8,
+		    consecutive_failures = CASE WHEN $6 AND NOT is_active THEN 0 ELSE consecutive_failures END,
+		    disabled_at = CASE WHEN $6 THEN NULL ELSE disabled_at END,
+		    updated_at = NOW()
 		WHERE id = $1
-	`, t.ID, t.Name, t.Type, t.URL, t.Metadata, t.IsActive)
+		RETURNING consecutive_failures, disabled_at
+	`, t.ID, t.Name, t.Type, t.URL, t.Metadata, t.IsActive, t.TgAccessHash, t.TgChannelID).Scan(&t.ConsecutiveFailures, &t.DisabledAt)
 	if err != nil {
 		return fmt.Errorf("update target: %w", err)
 	}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/blockedby/positions-os/internal/database"
+)
+
+func TestTargetsRepository_Health(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+
+	repo := NewTargetsRepository(db.Pool)
+	target := &ScrapingTarget{Name: "Renamed Channel", Type: "TG_CHANNEL", URL: "@renamed", IsActive: true}
+	if err := repo.Create(ctx, target); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// 1. successful runs average their new jobs
+	if err := repo.RecordSuccess(ctx, target.ID, 4); err != nil {
+		t.Fatalf("RecordSuccess failed: %v", err)
+	}
+	if err := repo.RecordSuccess(ctx, target.ID, 2); err != nil {
+		t.Fatalf("RecordSuccess failed: %v", err)
+	}
+	got, _ := repo.GetByID(ctx, target.ID)
+	if got.SuccessfulRuns != 2 || got.AvgNewJobs != 3 || got.LastSuccessAt == nil || !got.Healthy() {
+		t.Errorf("health after successes = %+v", got.TargetHealth)
+	}
+
+	// 2. failures are counted, the third one deactivates the target
+	for i := 1; i <= 3; i++ {
+		disabled, err := repo.RecordFailure(ctx, target.ID, "resolve channel: USERNAME_INVALID", 3)
+		if err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+		if disabled != (i == 3) {
+			t.Errorf("failure %d: disabled = %v", i, disabled)
+		}
+	}
+	got, _ = repo.GetByID(ctx, target.ID)
+	if got.IsActive || got.DisabledAt == nil || got.ConsecutiveFailures != 3 || got.LastError == nil {
+		t.Errorf("target after 3 failures = active %v, %+v", got.IsActive, got.TargetHealth)
+	}
+	if disabled, _ := repo.RecordFailure(ctx, target.ID, "resolve channel: USERNAME_INVALID", 3); disabled {
+		t.Error("an inactive target can not be deactivated again")
+	}
+
+	unhealthy, err := repo.ListUnhealthy(ctx)
+	if err != nil {
+		t.Fatalf("ListUnhealthy failed: %v", err)
+	}
+	if len(unhealthy) != 1 || unhealthy[0].ID != target.ID {
+		t.Errorf("ListUnhealthy = %+v", unhealthy)
+	}
+
+	// 3. turning the target back on resets its failures
+	got.IsActive = true
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got.ConsecutiveFailures != 0 || got.DisabledAt != nil {
+		t.Errorf("health after re-activation = %+v", got.TargetHealth)
+	}
+	if unhealthy, _ := repo.ListUnhealthy(ctx); len(unhealthy) != 0 {
+		t.Errorf("re-activated target still unhealthy: %+v", unhealthy)
+	}
+}
+
+func TestTargetsRepository_UpdateChannel(t *testing.T) {
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := database.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	setupSchema(t, db)
+
+	repo := NewTargetsRepository(db.Pool)
+	channelID, hash := int64(1234567890), int64(42)
+	target := &ScrapingTarget{Name: "Go Jobs", Type: "TG_CHANNEL", URL: "@golang_jobs", TgChannelID: &channelID, TgAccessHash: &hash, IsActive: true}
+	if err := repo.Create(ctx, target); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// a new url without its channel clears the stored one
+	target.URL, target.TgChannelID, target.TgAccessHash = "@rust_jobs", nil, nil
+	if err := repo.Update(ctx, target); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	got, _ := repo.GetByID(ctx, target.ID)
+	if got.URL != "@rust_jobs" || got.TgChannelID != nil || got.TgAccessHash != nil {
+		t.Errorf("target after url change = %s, channel %v, hash %v", got.URL, got.TgChannelID, got.TgAccessHash)
+	}
+	if other, _ := repo.GetByChannelID(ctx, channelID); other != nil {
+		t.Errorf("GetByChannelID(%d) = %s, want the old channel gone", channelID, other.URL)
+	}
+}
@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 )
 
 // test target type validation
 func TestScrapingTarget_IsValid(t *testing.T) {
-	validTypes := []string{"TG_CHANNEL", "TG_GROUP", "TG_FORUM", "HH_SEARCH", "LINKEDIN_SEARCH"}
+	validTypes := []string{"TG_CHANNEL", "TG_GROUP", "TG_FORUM", "HH_SEARCH", "LINKEDIN_SEARCH", "FEED"}
 
 	for _, typ := range validTypes {
 		target := ScrapingTarget{
@@ -37,12 +40,15 @@ func TestScrapingTarget_IsTelegram(t *testing.T) {
 		typ       string
 		wantTg    bool
 		wantForum bool
+		wantHH    bool
+		wantFeed  bool
 	}{
-		{"TG_CHANNEL", true, false},
-		{"TG_GROUP", true, false},
-		{"TG_FORUM", true, true},
-		{"HH_SEARCH", false, false},
-		{"LINKEDIN_SEARCH", false, false},
+		{"TG_CHANNEL", true, false, false, false},
+		{"TG_GROUP", true, false, false, false},
+		{"TG_FORUM", true, true, false, false},
+		{"HH_SEARCH", false, false, true, false},
+		{"LINKEDIN_SEARCH", false, false, false, false},
+		{"FEED", false, false, false, true},
 	}
 
 	for _, tt := range tests {
@@ -53,5 +59,114 @@ func TestScrapingTarget_IsTelegram(t *testing.T) {
 		if target.IsForum() != tt.wantForum {
 			t.Errorf("type %s: IsForum() = %v, want %v", tt.typ, target.IsForum(), tt.wantForum)
 		}
+		if target.IsHH() != tt.wantHH {
+			t.Errorf("type %s: IsHH() = %v, want %v", tt.typ, target.IsHH(), tt.wantHH)
+		}
+		if target.IsFeed() != tt.wantFeed {
+			t.Errorf("type %s: IsFeed() = %v, want %v", tt.typ, target.IsFeed(), tt.wantFeed)
+		}
+	}
+}
+
+// test telegram message permalinks
+func TestScrapingTarget_Permalink(t *testing.T) {
+	channelID := int64(1234567890)
+	topic := int64(15)
+	general := int64(1)
+
+	tests := []struct {
+		name    string
+		target  ScrapingTarget
+		msgID   int64
+		topicID *int64
+		want    string
+	}{
+		{"public channel", ScrapingTarget{URL: "@golang_jobs"}, 42, nil, "https://t.me/golang_jobs/42"},
+		{"bare username", ScrapingTarget{URL: "golang_jobs"}, 42, nil, "https://t.me/golang_jobs/42"},
+		{"t.me url", ScrapingTarget{URL: "https://t.me/golang_jobs"}, 42, nil, "https://t.me/golang_jobs/42"},
+		{"forum topic", ScrapingTarget{URL: "@go_forum"}, 42, &topic, "https://t.me/go_forum/15/42"},
+		{"topic root", ScrapingTarget{URL: "@go_forum"}, 15, &topic, "https://t.me/go_forum/15"},
+		{"general topic", ScrapingTarget{URL: "@go_forum"}, 42, &general, "https://t.me/go_forum/42"},
+		{"private channel", ScrapingTarget{URL: "https://t.me/+AbCdEf", TgChannelID: &channelID}, 42, nil, "https://t.me/c/1234567890/42"},
+		{"private forum topic", ScrapingTarget{URL: "https://t.me/+AbCdEf", TgChannelID: &channelID}, 42, &topic, "https://t.me/c/1234567890/15/42"},
+		{"unresolved invite link", ScrapingTarget{URL: "https://t.me/+AbCdEf"}, 42, nil, ""},
+		{"channel id url", ScrapingTarget{URL: "https://t.me/c/1234567890"}, 42, nil, "https://t.me/c/1234567890/42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.Permalink(tt.msgID, tt.topicID); got != tt.want {
+				t.Errorf("Permalink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&ScrapingTarget{URL: "@golang_jobs"}).CommentPermalink(42, 1001); got != "https://t.me/golang_jobs/42?comment=1001" {
+		t.Errorf("CommentPermalink() = %q", got)
+	}
+	if got := (&ScrapingTarget{URL: "https://t.me/+AbCdEf"}).CommentPermalink(42, 1001); got != "" {
+		t.Errorf("CommentPermalink() of an unresolved invite link = %q, want empty", got)
+	}
+}
+
+// test the username, invite link and channel id forms of telegram urls
+func TestScrapingTarget_TelegramRef(t *testing.T) {
+	tests := []struct {
+		url       string
+		username  string
+		invite    string
+		channelID int64
+	}{
+		{"@golang_jobs", "golang_jobs", "", 0},
+		{"https://t.me/golang_jobs", "golang_jobs", "", 0},
+		{"https://t.me/+AbCdEf123", "", "AbCdEf123", 0},
+		{"https://t.me/joinchat/AbCdEf123", "", "AbCdEf123", 0},
+		{"t.me/+AbCdEf123?single", "", "AbCdEf123", 0},
+		{"https://t.me/c/1234567890", "", "", 1234567890},
+		{"https://t.me/c/1234567890/42", "", "", 1234567890},
+		{"-1001234567890", "", "", 1234567890},
+		{"1234567890", "", "", 1234567890},
+		{"https://t.me/golang_jobs/42", "", "", 0},
+		{"https://example.com/+AbCdEf", "", "", 0},
+	}
+
+	for _, tt := range tests {
+		target := ScrapingTarget{URL: tt.url}
+		if got := target.Username(); got != tt.username {
+			t.Errorf("%s: Username() = %q, want %q", tt.url, got, tt.username)
+		}
+		if got := target.InviteHash(); got != tt.invite {
+			t.Errorf("%s: InviteHash() = %q, want %q", tt.url, got, tt.invite)
+		}
+		if got := target.URLChannelID(); got != tt.channelID {
+			t.Errorf("%s: URLChannelID() = %d, want %d", tt.url, got, tt.channelID)
+		}
+	}
+}
+
+// test target health state and its json fields
+func TestTargetHealth(t *testing.T) {
+	now := time.Now()
+	errText := "resolve channel: USERNAME_NOT_OCCUPIED"
+
+	if !(ScrapingTarget{}).Healthy() {
+		t.Error("a new target should be healthy")
+	}
+	failing := ScrapingTarget{TargetHealth: TargetHealth{ConsecutiveFailures: 2, LastError: &errText}}
+	if failing.Healthy() {
+		t.Error("a target whose last run failed should be unhealthy")
+	}
+	if (ScrapingTarget{TargetHealth: TargetHealth{DisabledAt: &now}}).Healthy() {
+		t.Error("an auto-disabled target should be unhealthy")
+	}
+
+	data, err := json.Marshal(failing)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	for _, key := range []string{`"consecutive_failures":2`, `"last_error":"resolve channel`, `"avg_new_jobs":0`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("health should be flat in the target json, missing %s: %s", key, data)
+		}
 	}
 }
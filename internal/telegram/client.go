@@ -2,12 +2,17 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
+	"unicode/utf16"
 
 	"github.com/blockedby/positions-os/internal/logger"
 	"github.com/celestix/gotgproto"
+	"github.com/gotd/td/constant"
+	"github.com/gotd/td/telegram/downloader"
 	"github.com/gotd/td/tg"
 )
 
@@ -35,6 +40,12 @@ func (c *Client) Close() {
 	}
 }
 
+// FloodWaitUntil returns the time until which telegram requests are paused
+// after a FLOOD_WAIT. zero time if no backoff is active.
+func (c *Client) FloodWaitUntil() time.Time {
+	return c.rateLimiter.FloodWaitUntil()
+}
+
 // GetStatus returns the current status of the telegram client.
 func (c *Client) GetStatus() Status {
 	return c.manager.GetStatus()
@@ -59,7 +70,7 @@ func (c *Client) CancelQR() {
 func (c *Client) getProto() (*gotgproto.Client, error) {
 	proto := c.manager.GetClient()
 	if proto == nil {
-		return nil, fmt.Errorf("telegram client not authorized")
+		return nil, ErrNotReady
 	}
 	return proto, nil
 }
@@ -99,19 +110,173 @@ func (c *Client) ResolveChannel(ctx context.Context, username string) (*Channel,
 			c.rateLimiter.SetFloodWait(wait)
 		}
 		c.log.Error().Err(err).Str("username", username).Msg("telegram: failed to resolve username")
-		return nil, fmt.Errorf("resolve username %s: %w", username, err)
+		return nil, resolveError(username, err)
 	}
 
 	if len(resolved.Chats) == 0 {
-		return nil, fmt.Errorf("channel not found: %s", username)
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, username)
+	}
+
+	var ch *tg.Channel
+	switch chat := resolved.Chats[0].(type) {
+	case *tg.Channel:
+		ch = chat
+	case *tg.ChannelForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrChannelPrivate, username)
+	default:
+		return nil, fmt.Errorf("%w: %s is not a channel or group", ErrChannelNotFound, username)
+	}
+
+	return c.fullChannel(ctx, ch, username)
+}
+
+// ResolveChannelByID resolves a channel by its id and access hash, stored
+// when the target was resolved or joined before. without the access hash it
+// is looked up among the peers of the session (channels it joined),
+// ErrChannelPrivate if the session does not know the channel.
+func (c *Client) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*Channel, error) {
+	ref := fmt.Sprintf("channel %d", id)
+	if accessHash == 0 {
+		proto, err := c.getProto()
+		if err != nil {
+			return nil, err
+		}
+		var peerID constant.TDLibPeerID
+		peerID.Channel(id)
+		peer := proto.PeerStorage.GetPeerById(int64(peerID))
+		if peer == nil || peer.ID == 0 || peer.AccessHash == 0 {
+			return nil, fmt.Errorf("%w: channel %d is not joined, add it by invite link", ErrChannelPrivate, id)
+		}
+		accessHash = peer.AccessHash
+	}
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
 	}
 
-	ch, ok := resolved.Chats[0].(*tg.Channel)
+	c.log.Info().Int64("channel_id", id).Msg("telegram: resolving channel by id")
+	api, err := c.API()
+	if err != nil {
+		return nil, err
+	}
+	fullCh, err := api.ChannelsGetFullChannel(ctx, &tg.InputChannel{
+		ChannelID:  id,
+		AccessHash: accessHash,
+	})
+	if err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return nil, resolveError(ref, fmt.Errorf("get full channel: %w", err))
+	}
+
+	for _, chat := range fullCh.Chats {
+		if ch, ok := chat.(*tg.Channel); ok && ch.ID == id {
+			return newChannel(ch, fullCh, ch.Username)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, ref)
+}
+
+// JoinInvite resolves the channel of an invite link hash (t.me/+<hash>),
+// joining it unless the session is already a member. ErrChannelNotFound for
+// expired or invalid links, ErrChannelPrivate when joining needs the
+// approval of an admin.
+func (c *Client) JoinInvite(ctx context.Context, hash string) (*Channel, error) {
+	return c.resolveInvite(ctx, hash, true)
+}
+
+// CheckInvite resolves the channel of an invite link hash without joining
+// it: the session must be a member already, or the link must let it peek
+// into the chat. ErrChannelPrivate for a chat it would have to join.
+func (c *Client) CheckInvite(ctx context.Context, hash string) (*Channel, error) {
+	return c.resolveInvite(ctx, hash, false)
+}
+
+// resolveInvite checks an invite link and joins its chat when join is set
+// and the session is not a member yet
+func (c *Client) resolveInvite(ctx context.Context, hash string, join bool) (*Channel, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	c.log.Info().Msg("telegram: checking invite link")
+	api, err := c.API()
+	if err != nil {
+		return nil, err
+	}
+	invite, err := api.MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return nil, resolveError("invite link", err)
+	}
+
+	var chat tg.ChatClass
+	switch inv := invite.(type) {
+	case *tg.ChatInviteAlready:
+		chat = inv.Chat
+	case *tg.ChatInvite:
+		if inv.RequestNeeded {
+			return nil, fmt.Errorf("%w: joining %q needs the approval of an admin", ErrChannelPrivate, inv.Title)
+		}
+		if !join {
+			return nil, fmt.Errorf("%w: %q is not joined yet", ErrChannelPrivate, inv.Title)
+		}
+		if chat, err = c.importInvite(ctx, api, hash); err != nil {
+			return nil, err
+		}
+	case *tg.ChatInvitePeek:
+		// the peek lets the session read the chat for a while without
+		// joining it; it is joined to keep reading it once the peek expires
+		chat = inv.Chat
+		if join {
+			if chat, err = c.importInvite(ctx, api, hash); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected invite type %T", invite)
+	}
+
+	ch, ok := chat.(*tg.Channel)
 	if !ok {
-		return nil, fmt.Errorf("not a channel: %s", username)
+		return nil, fmt.Errorf("%w: invite link is not of a channel or group", ErrChannelNotFound)
+	}
+	return c.fullChannel(ctx, ch, ch.Username)
+}
+
+// importInvite joins the chat of an invite link and returns it
+func (c *Client) importInvite(ctx context.Context, api *tg.Client, hash string) (tg.ChatClass, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	updates, err := api.MessagesImportChatInvite(ctx, hash)
+	if err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return nil, resolveError("invite link", fmt.Errorf("join: %w", err))
+	}
+
+	var chats []tg.ChatClass
+	switch u := updates.(type) {
+	case *tg.Updates:
+		chats = u.Chats
+	case *tg.UpdatesCombined:
+		chats = u.Chats
+	}
+	if len(chats) == 0 {
+		return nil, fmt.Errorf("join: no chat in the updates of the invite")
 	}
+	c.log.Info().Int64("chat_id", chats[0].GetID()).Msg("telegram: joined by invite link")
+	return chats[0], nil
+}
 
-	api, err = c.API()
+// fullChannel fetches the full info of a channel (its discussion group)
+func (c *Client) fullChannel(ctx context.Context, ch *tg.Channel, ref string) (*Channel, error) {
+	api, err := c.API()
 	if err != nil {
 		return nil, fmt.Errorf("get api: %w", err)
 	}
@@ -120,32 +285,82 @@ func (c *Client) ResolveChannel(ctx context.Context, username string) (*Channel,
 		AccessHash: ch.AccessHash,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("get full channel: %w", err)
+		return nil, resolveError(ref, fmt.Errorf("get full channel: %w", err))
 	}
+	return newChannel(ch, fullCh, ref)
+}
 
+// newChannel converts a channel and its full info to Channel.
+// username is used when the channel has no registered one.
+func newChannel(ch *tg.Channel, fullCh *tg.MessagesChatFull, username string) (*Channel, error) {
 	chFull, ok := fullCh.FullChat.(*tg.ChannelFull)
 	if !ok {
 		return nil, fmt.Errorf("unexpected channel type")
 	}
 
-	// forum flag is at position 30 in ChannelFull flags
-	isForum := chFull.Flags.Has(30)
+	// the forum flag is on the channel (flags.30), not on its full info
+	isForum := ch.Forum
+
+	// the username as registered, not as typed
+	if ch.Username != "" {
+		username = ch.Username
+	}
 
 	return &Channel{
-		ID:         ch.ID,
-		AccessHash: ch.AccessHash,
-		Username:   username,
-		Title:      ch.Title,
-		IsForum:    isForum,
+		ID:          ch.ID,
+		AccessHash:  ch.AccessHash,
+		Username:    username,
+		Title:       ch.Title,
+		IsForum:     isForum,
+		IsBroadcast: ch.Broadcast,
+		IsMegagroup: ch.Megagroup,
+		LinkedChat:  linkedChat(ch, chFull, fullCh.Chats),
 	}, nil
 }
 
+// resolveError tells unknown usernames and dead invite links
+// (ErrChannelNotFound) and channels the session can not read
+// (ErrChannelPrivate) apart from other failures
+func resolveError(ref string, err error) error {
+	str := err.Error()
+	switch {
+	case strings.Contains(str, "USERNAME_NOT_OCCUPIED"), strings.Contains(str, "USERNAME_INVALID"),
+		strings.Contains(str, "INVITE_HASH_EXPIRED"), strings.Contains(str, "INVITE_HASH_INVALID"),
+		strings.Contains(str, "INVITE_HASH_EMPTY"):
+		return fmt.Errorf("%w: %s: %w", ErrChannelNotFound, ref, err)
+	case strings.Contains(str, "CHANNEL_PRIVATE"), strings.Contains(str, "CHANNEL_INVALID"),
+		strings.Contains(str, "INVITE_REQUEST_SENT"):
+		return fmt.Errorf("%w: %s: %w", ErrChannelPrivate, ref, err)
+	}
+	return fmt.Errorf("resolve %s: %w", ref, err)
+}
+
+// linkedChat returns the discussion group of a broadcast channel from its
+// full info, nil if comments are off. the group of a discussion group
+// links back to its channel, that link is not followed.
+func linkedChat(ch *tg.Channel, full *tg.ChannelFull, chats []tg.ChatClass) *Channel {
+	id, ok := full.GetLinkedChatID()
+	if !ok || !ch.Broadcast {
+		return nil
+	}
+	for _, chat := range chats {
+		if group, ok := chat.(*tg.Channel); ok && group.ID == id {
+			return &Channel{
+				ID:         group.ID,
+				AccessHash: group.AccessHash,
+				Username:   group.Username,
+				Title:      group.Title,
+			}
+		}
+	}
+	return nil
+}
+
 // ChannelExists checks if channel username exists and is accessible
 func (c *Client) ChannelExists(ctx context.Context, username string) (bool, error) {
 	_, err := c.ResolveChannel(ctx, username)
 	if err != nil {
-		// check if it's a "not found" error
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, ErrChannelNotFound) {
 			return false, nil
 		}
 		return false, err
@@ -192,6 +407,66 @@ func (c *Client) GetMessages(ctx context.Context, channel *Channel, offsetID int
 	return c.extractMessages(history, channel)
 }
 
+// GetDeletedMessages re-fetches messages of a channel by id (max 100)
+// and returns the ids of the ones that no longer exist
+func (c *Client) GetDeletedMessages(ctx context.Context, channel *Channel, ids []int) ([]int, error) {
+	if len(ids) > 100 {
+		ids = ids[:100] // telegram api limit
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	api, err := c.API()
+	if err != nil {
+		return nil, err
+	}
+	input := make([]tg.InputMessageClass, 0, len(ids))
+	for _, id := range ids {
+		input = append(input, &tg.InputMessageID{ID: id})
+	}
+	result, err := api.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+		Channel: &tg.InputChannel{
+			ChannelID:  channel.ID,
+			AccessHash: channel.AccessHash,
+		},
+		ID: input,
+	})
+	if err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return nil, fmt.Errorf("get messages: %w", err)
+	}
+
+	return deletedMessageIDs(result), nil
+}
+
+// deletedMessageIDs returns the ids of messages that came back empty (deleted)
+func deletedMessageIDs(messagesClass tg.MessagesMessagesClass) []int {
+	var messages []tg.MessageClass
+	switch h := messagesClass.(type) {
+	case *tg.MessagesChannelMessages:
+		messages = h.Messages
+	case *tg.MessagesMessages:
+		messages = h.Messages
+	case *tg.MessagesMessagesSlice:
+		messages = h.Messages
+	}
+
+	var deleted []int
+	for _, msg := range messages {
+		if empty, ok := msg.(*tg.MessageEmpty); ok {
+			deleted = append(deleted, empty.ID)
+		}
+	}
+	return deleted
+}
+
 // GetTopics returns list of forum topics for a channel
 // returns empty list if channel is not a forum
 func (c *Client) GetTopics(ctx context.Context, channel *Channel) ([]Topic, error) {
@@ -249,6 +524,12 @@ func (c *Client) GetTopicMessages(ctx context.Context, channel *Channel, topicID
 		limit = 100
 	}
 
+	c.log.Debug().Int64("channel_id", channel.ID).Int("topic_id", topicID).Int("offset_id", offsetID).Int("limit", limit).Msg("telegram: waiting for rate limiter before GetTopicMessages")
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		c.log.Error().Err(err).Msg("telegram: rate limiter wait failed")
+		return nil, err
+	}
+
 	api, err := c.API()
 	if err != nil {
 		return nil, err
@@ -272,6 +553,46 @@ func (c *Client) GetTopicMessages(ctx context.Context, channel *Channel, topicID
 	return c.extractMessages(result, channel)
 }
 
+// GetReplies fetches the comments on a channel post, newest first.
+// the comments are messages of the linked discussion group and carry its id.
+func (c *Client) GetReplies(ctx context.Context, channel *Channel, postID int, offsetID int, limit int) ([]Message, error) {
+	if limit > 100 {
+		limit = 100
+	}
+
+	c.log.Debug().Int64("channel_id", channel.ID).Int("post_id", postID).Int("offset_id", offsetID).Int("limit", limit).Msg("telegram: waiting for rate limiter before GetReplies")
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		c.log.Error().Err(err).Msg("telegram: rate limiter wait failed")
+		return nil, err
+	}
+
+	api, err := c.API()
+	if err != nil {
+		return nil, err
+	}
+	result, err := api.MessagesGetReplies(ctx, &tg.MessagesGetRepliesRequest{
+		Peer: &tg.InputPeerChannel{
+			ChannelID:  channel.ID,
+			AccessHash: channel.AccessHash,
+		},
+		MsgID:    postID,
+		OffsetID: offsetID,
+		Limit:    limit,
+	})
+	if err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return nil, fmt.Errorf("get replies: %w", err)
+	}
+
+	group := channel
+	if channel.LinkedChat != nil {
+		group = channel.LinkedChat
+	}
+	return c.extractMessages(result, group)
+}
+
 // extractMessages converts telegram message response to our Message type
 func (c *Client) extractMessages(messagesClass tg.MessagesMessagesClass, channel *Channel) ([]Message, error) {
 	var messages []Message
@@ -301,26 +622,184 @@ func (c *Client) parseMessage(msg tg.MessageClass, channel *Channel) *Message {
 		return nil
 	}
 
-	// extract topic id from reply header if it's a forum message
+	message := newMessage(m, channel.ID)
+	return &message
+}
+
+// newMessage converts a telegram message of a channel to our Message type
+func newMessage(m *tg.Message, channelID int64) Message {
+	// extract topic id from reply header if it's a forum message.
+	// for a reply inside a topic ReplyToMsgID is the replied message
+	// and the topic is in ReplyToTopID.
 	var topicID *int
 	if m.ReplyTo != nil {
 		if replyHeader, ok := m.ReplyTo.(*tg.MessageReplyHeader); ok {
 			if replyHeader.ForumTopic {
 				tid := replyHeader.ReplyToMsgID
+				if topID, ok := replyHeader.GetReplyToTopID(); ok && topID != 0 {
+					tid = topID
+				}
 				topicID = &tid
 			}
 		}
 	}
 
-	return &Message{
+	msg := Message{
 		ID:        m.ID,
-		ChannelID: channel.ID,
+		ChannelID: channelID,
 		Text:      m.Message,
 		Date:      time.Unix(int64(m.Date), 0),
 		TopicID:   topicID,
 		Views:     m.Views,
 		Forwards:  m.Forwards,
 	}
+	if m.EditDate != 0 {
+		edited := time.Unix(int64(m.EditDate), 0)
+		msg.EditDate = &edited
+	}
+	if media, ok := m.GetMedia(); ok {
+		msg.Attachment = newAttachment(media)
+	}
+	if replies, ok := m.GetReplies(); ok && replies.Comments {
+		msg.Replies = replies.Replies
+	}
+	msg.Links = entityLinks(m.Message, m.Entities)
+	return msg
+}
+
+// entityLinks returns the urls, hidden text links, mentions, emails and
+// phone numbers marked up in a message. entity offsets are in utf-16 units.
+func entityLinks(text string, entities []tg.MessageEntityClass) []Link {
+	if len(entities) == 0 {
+		return nil
+	}
+	units := utf16.Encode([]rune(text))
+	span := func(offset, length int) string {
+		if offset < 0 || length <= 0 || offset+length > len(units) {
+			return ""
+		}
+		return strings.TrimSpace(string(utf16.Decode(units[offset : offset+length])))
+	}
+
+	var links []Link
+	for _, entity := range entities {
+		var link Link
+		switch e := entity.(type) {
+		case *tg.MessageEntityURL:
+			link = Link{Type: LinkURL, URL: span(e.Offset, e.Length)}
+		case *tg.MessageEntityTextURL:
+			link = Link{Type: LinkTextURL, Text: span(e.Offset, e.Length), URL: e.URL}
+		case *tg.MessageEntityMention:
+			link = Link{Type: LinkMention, URL: span(e.Offset, e.Length)}
+		case *tg.MessageEntityEmail:
+			link = Link{Type: LinkEmail, URL: span(e.Offset, e.Length)}
+		case *tg.MessageEntityPhone:
+			link = Link{Type: LinkPhone, URL: span(e.Offset, e.Length)}
+		default:
+			continue
+		}
+		if link.URL != "" {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// newAttachment converts the media of a message to an Attachment.
+// nil for media other than documents and photos (polls, links, geo).
+func newAttachment(media tg.MessageMediaClass) *Attachment {
+	switch m := media.(type) {
+	case *tg.MessageMediaDocument:
+		doc, ok := m.Document.(*tg.Document)
+		if !ok {
+			return nil
+		}
+		att := &Attachment{
+			Type:          AttachmentDocument,
+			MimeType:      doc.MimeType,
+			Size:          doc.Size,
+			ID:            doc.ID,
+			AccessHash:    doc.AccessHash,
+			FileReference: doc.FileReference,
+			DCID:          doc.DCID,
+		}
+		for _, attr := range doc.Attributes {
+			if name, ok := attr.(*tg.DocumentAttributeFilename); ok {
+				att.FileName = name.FileName
+			}
+		}
+		return att
+	case *tg.MessageMediaPhoto:
+		photo, ok := m.Photo.(*tg.Photo)
+		if !ok {
+			return nil
+		}
+		return &Attachment{
+			Type:          AttachmentPhoto,
+			MimeType:      "image/jpeg",
+			ID:            photo.ID,
+			AccessHash:    photo.AccessHash,
+			FileReference: photo.FileReference,
+			DCID:          photo.DCID,
+		}
+	}
+	return nil
+}
+
+// DownloadDocument streams a document attachment to w
+func (c *Client) DownloadDocument(ctx context.Context, att *Attachment, w io.Writer) error {
+	if att.Type != AttachmentDocument {
+		return fmt.Errorf("download: %s attachment is not a document", att.Type)
+	}
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	api, err := c.API()
+	if err != nil {
+		return err
+	}
+	location := &tg.InputDocumentFileLocation{
+		ID:            att.ID,
+		AccessHash:    att.AccessHash,
+		FileReference: att.FileReference,
+	}
+	if _, err := downloader.NewDownloader().Download(api, location).Stream(ctx, w); err != nil {
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return fmt.Errorf("download document %d: %w", att.ID, err)
+	}
+	return nil
+}
+
+// JoinChannel subscribes the account to a channel, so its new messages arrive as updates.
+// joining a channel the account is already in is not an error.
+func (c *Client) JoinChannel(ctx context.Context, channel *Channel) error {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	api, err := c.API()
+	if err != nil {
+		return err
+	}
+	_, err = api.ChannelsJoinChannel(ctx, &tg.InputChannel{
+		ChannelID:  channel.ID,
+		AccessHash: channel.AccessHash,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "USER_ALREADY_PARTICIPANT") {
+			return nil
+		}
+		if wait := c.checkFloodWait(err); wait > 0 {
+			c.rateLimiter.SetFloodWait(wait)
+		}
+		return fmt.Errorf("join channel: %w", err)
+	}
+
+	c.log.Info().Int64("channel_id", channel.ID).Str("username", channel.Username).Msg("telegram: joined channel")
+	return nil
 }
 
 // checkFloodWait checks if error is a FLOOD_WAIT error and returns wait seconds
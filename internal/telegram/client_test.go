@@ -2,10 +2,12 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/blockedby/positions-os/internal/config"
 	"github.com/glebarez/sqlite"
+	"github.com/gotd/td/tg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -46,3 +48,128 @@ func TestClient_ResolveChannel_UnauthorizedError(t *testing.T) {
 	assert.Contains(t, err.Error(), "telegram client not authorized")
 	assert.Nil(t, channel)
 }
+
+func TestDeletedMessageIDs(t *testing.T) {
+	result := &tg.MessagesChannelMessages{Messages: []tg.MessageClass{
+		&tg.Message{ID: 1, Message: "still open"},
+		&tg.MessageEmpty{ID: 2},
+		&tg.MessageService{ID: 3},
+		&tg.MessageEmpty{ID: 4},
+	}}
+
+	assert.Equal(t, []int{2, 4}, deletedMessageIDs(result))
+	assert.Empty(t, deletedMessageIDs(&tg.MessagesMessagesNotModified{}))
+}
+
+func TestNewMessage_Attachment(t *testing.T) {
+	doc := &tg.Message{ID: 10, Message: "Vacancy in the attached file"}
+	doc.SetMedia(&tg.MessageMediaDocument{Document: &tg.Document{
+		ID:            100,
+		AccessHash:    200,
+		FileReference: []byte{1, 2},
+		MimeType:      "application/pdf",
+		Size:          2048,
+		DCID:          2,
+		Attributes:    []tg.DocumentAttributeClass{&tg.DocumentAttributeFilename{FileName: "golang.pdf"}},
+	}})
+
+	msg := newMessage(doc, 1)
+	assert.Equal(t, "Vacancy in the attached file", msg.Text)
+	assert.Equal(t, &Attachment{
+		Type:          AttachmentDocument,
+		FileName:      "golang.pdf",
+		MimeType:      "application/pdf",
+		Size:          2048,
+		ID:            100,
+		AccessHash:    200,
+		FileReference: []byte{1, 2},
+		DCID:          2,
+	}, msg.Attachment)
+
+	photo := &tg.Message{ID: 11, Message: "Go developer, details in the picture"}
+	photo.SetMedia(&tg.MessageMediaPhoto{Photo: &tg.Photo{ID: 300, AccessHash: 400, DCID: 2}})
+	msg = newMessage(photo, 1)
+	assert.Equal(t, AttachmentPhoto, msg.Attachment.Type)
+	assert.Equal(t, int64(300), msg.Attachment.ID)
+
+	poll := &tg.Message{ID: 12, Message: "Which language?"}
+	poll.SetMedia(&tg.MessageMediaPoll{})
+	assert.Nil(t, newMessage(poll, 1).Attachment)
+	assert.Nil(t, newMessage(&tg.Message{ID: 13, Message: "text only"}, 1).Attachment)
+}
+
+func TestNewMessage_Replies(t *testing.T) {
+	post := &tg.Message{ID: 1, Message: "Go developer"}
+	post.SetReplies(tg.MessageReplies{Comments: true, Replies: 7})
+	assert.Equal(t, 7, newMessage(post, 1).Replies)
+
+	// a reply thread inside a group is not a comment section
+	thread := &tg.Message{ID: 2, Message: "Question"}
+	thread.SetReplies(tg.MessageReplies{Replies: 3})
+	assert.Zero(t, newMessage(thread, 1).Replies)
+}
+
+func TestLinkedChat(t *testing.T) {
+	channel := &tg.Channel{ID: 1, Broadcast: true}
+	full := &tg.ChannelFull{}
+	full.SetLinkedChatID(2)
+	chats := []tg.ChatClass{
+		channel,
+		&tg.Channel{ID: 2, AccessHash: 20, Title: "Go Jobs Chat", Megagroup: true},
+	}
+
+	assert.Equal(t, &Channel{ID: 2, AccessHash: 20, Title: "Go Jobs Chat"}, linkedChat(channel, full, chats))
+
+	// the discussion group links back to its channel
+	group := &tg.Channel{ID: 2, Megagroup: true}
+	groupFull := &tg.ChannelFull{}
+	groupFull.SetLinkedChatID(1)
+	assert.Nil(t, linkedChat(group, groupFull, chats))
+
+	assert.Nil(t, linkedChat(channel, &tg.ChannelFull{}, chats))
+}
+
+func TestNewMessage_Links(t *testing.T) {
+	// offsets are utf-16 units: the emoji takes two
+	text := "🔥 Go dev. Откликнуться, @hr_anna, jobs@example.com, +79991234567, https://example.com"
+	m := &tg.Message{ID: 1, Message: text}
+	m.SetEntities([]tg.MessageEntityClass{
+		&tg.MessageEntityBold{Offset: 3, Length: 6},
+		&tg.MessageEntityTextURL{Offset: 11, Length: 12, URL: "https://forms.example.com/apply"},
+		&tg.MessageEntityMention{Offset: 25, Length: 8},
+		&tg.MessageEntityEmail{Offset: 35, Length: 16},
+		&tg.MessageEntityPhone{Offset: 53, Length: 12},
+		&tg.MessageEntityURL{Offset: 67, Length: 19},
+		&tg.MessageEntityURL{Offset: 80, Length: 50}, // out of range
+	})
+
+	msg := newMessage(m, 1)
+	assert.Equal(t, []Link{
+		{Type: LinkTextURL, Text: "Откликнуться", URL: "https://forms.example.com/apply"},
+		{Type: LinkMention, URL: "@hr_anna"},
+		{Type: LinkEmail, URL: "jobs@example.com"},
+		{Type: LinkPhone, URL: "+79991234567"},
+		{Type: LinkURL, URL: "https://example.com"},
+	}, msg.Links)
+
+	assert.Nil(t, newMessage(&tg.Message{ID: 2, Message: "no entities"}, 1).Links)
+}
+
+func TestResolveError(t *testing.T) {
+	notFound := resolveError("no_such", errors.New("rpc error code 400: USERNAME_NOT_OCCUPIED"))
+	assert.ErrorIs(t, notFound, ErrChannelNotFound)
+	assert.Contains(t, notFound.Error(), "no_such")
+
+	private := resolveError("closed", errors.New("get full channel: rpc error code 400: CHANNEL_PRIVATE"))
+	assert.ErrorIs(t, private, ErrChannelPrivate)
+
+	expired := resolveError("invite link", errors.New("rpc error code 400: INVITE_HASH_EXPIRED"))
+	assert.ErrorIs(t, expired, ErrChannelNotFound)
+
+	pending := resolveError("invite link", errors.New("join: rpc error code 400: INVITE_REQUEST_SENT"))
+	assert.ErrorIs(t, pending, ErrChannelPrivate)
+
+	other := resolveError("golang_jobs", errors.New("rpc error code 420: FLOOD_WAIT_30"))
+	assert.NotErrorIs(t, other, ErrChannelNotFound)
+	assert.NotErrorIs(t, other, ErrChannelPrivate)
+}
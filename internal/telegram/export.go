@@ -0,0 +1,253 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedExport is returned for exports of chats other than channels and groups
+var ErrUnsupportedExport = errors.New("unsupported export: not a channel or group")
+
+// Export is a channel or group exported by Telegram Desktop (result.json)
+type Export struct {
+	Name string `json:"name"`
+	// Type is the chat type of the export: public_channel, private_channel,
+	// public_supergroup, private_supergroup or private_group
+	Type string `json:"type"`
+	// ID is the channel id (without the -100 prefix)
+	ID int64 `json:"id"`
+	// Messages are the posts of the chat, service messages left out
+	Messages []Message `json:"messages"`
+}
+
+// TargetType returns the scraping target type of the exported chat,
+// empty for private chats, bots and saved messages
+func (e *Export) TargetType() string {
+	switch {
+	case strings.HasSuffix(e.Type, "_channel"):
+		return "TG_CHANNEL"
+	case strings.HasSuffix(e.Type, "_supergroup"), e.Type == "private_group":
+		return "TG_GROUP"
+	}
+	return ""
+}
+
+// exportChat is the json layout of result.json
+type exportChat struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	ID       int64           `json:"id"`
+	Messages []exportMessage `json:"messages"`
+}
+
+type exportMessage struct {
+	ID            int             `json:"id"`
+	Type          string          `json:"type"`
+	Date          string          `json:"date"`
+	DateUnix      string          `json:"date_unixtime"`
+	Edited        string          `json:"edited"`
+	EditedUnix    string          `json:"edited_unixtime"`
+	Text          json.RawMessage `json:"text"`
+	TextEntities  []exportEntity  `json:"text_entities"`
+	File          string          `json:"file"`
+	FileName      string          `json:"file_name"`
+	FileSize      int64           `json:"file_size"`
+	MimeType      string          `json:"mime_type"`
+	Photo         string          `json:"photo"`
+	PhotoFileSize int64           `json:"photo_file_size"`
+}
+
+// exportEntity is a piece of message text, plain or marked up
+type exportEntity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+// exportDateLayout is the local time format of date and edited
+const exportDateLayout = "2006-01-02T15:04:05"
+
+// ReadExport reads a Telegram Desktop json export: the result.json file or
+// the export directory holding it. attachment paths are resolved against
+// the export directory; files left out of the export are not attached.
+func ReadExport(path string) (*Export, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "result.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read export: %w", err)
+	}
+
+	var chat exportChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, fmt.Errorf("parse export: %w", err)
+	}
+
+	export := &Export{Name: chat.Name, Type: chat.Type, ID: chat.ID}
+	if export.TargetType() == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExport, chat.Type)
+	}
+
+	dir := filepath.Dir(path)
+	export.Messages = make([]Message, 0, len(chat.Messages))
+	for i := range chat.Messages {
+		m := &chat.Messages[i]
+		if m.Type != "message" {
+			continue
+		}
+		msg, err := m.message(dir)
+		if err != nil {
+			return nil, fmt.Errorf("parse export message %d: %w", m.ID, err)
+		}
+		msg.ChannelID = chat.ID
+		export.Messages = append(export.Messages, msg)
+	}
+	return export, nil
+}
+
+// message converts an exported message
+func (m *exportMessage) message(dir string) (Message, error) {
+	date, err := exportDate(m.DateUnix, m.Date)
+	if err != nil {
+		return Message{}, err
+	}
+
+	entities := m.TextEntities
+	if entities == nil {
+		if entities, err = exportText(m.Text); err != nil {
+			return Message{}, err
+		}
+	}
+
+	var text strings.Builder
+	var links []Link
+	for _, e := range entities {
+		text.WriteString(e.Text)
+		if link, ok := exportLink(e); ok {
+			links = append(links, link)
+		}
+	}
+
+	msg := Message{
+		ID:         m.ID,
+		Text:       text.String(),
+		Date:       date,
+		Links:      links,
+		Attachment: m.attachment(dir),
+	}
+	if m.Edited != "" || m.EditedUnix != "" {
+		edited, err := exportDate(m.EditedUnix, m.Edited)
+		if err != nil {
+			return Message{}, err
+		}
+		msg.EditDate = &edited
+	}
+	return msg, nil
+}
+
+// attachment is the document or photo of an exported message.
+// nil when the file was left out of the export.
+func (m *exportMessage) attachment(dir string) *Attachment {
+	switch {
+	case exportedFile(m.File):
+		name := m.FileName
+		if name == "" {
+			name = filepath.Base(m.File)
+		}
+		return &Attachment{
+			Type:     AttachmentDocument,
+			FileName: name,
+			MimeType: m.MimeType,
+			Size:     m.FileSize,
+			Path:     filepath.Join(dir, filepath.FromSlash(m.File)),
+		}
+	case exportedFile(m.Photo):
+		return &Attachment{
+			Type: AttachmentPhoto,
+			Size: m.PhotoFileSize,
+			Path: filepath.Join(dir, filepath.FromSlash(m.Photo)),
+		}
+	}
+	return nil
+}
+
+// exportedFile reports whether a file path points into the export,
+// files over the export size limit are replaced with a note in parentheses
+func exportedFile(path string) bool {
+	return path != "" && !strings.HasPrefix(path, "(")
+}
+
+// exportDate parses the unix time of a message, or its local time for
+// exports made before date_unixtime was added
+func exportDate(unix, local string) (time.Time, error) {
+	if unix != "" {
+		sec, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix time %q: %w", unix, err)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	t, err := time.ParseInLocation(exportDateLayout, local, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", local, err)
+	}
+	return t.UTC(), nil
+}
+
+// exportText parses the text field of exports without text_entities:
+// a plain string or a list of strings and marked up pieces
+func exportText(raw json.RawMessage) ([]exportEntity, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var plain string
+	if err := json.Unmarshal(raw, &plain); err == nil {
+		return []exportEntity{{Type: "plain", Text: plain}}, nil
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return nil, fmt.Errorf("invalid text: %w", err)
+	}
+	entities := make([]exportEntity, 0, len(parts))
+	for _, part := range parts {
+		var e exportEntity
+		if err := json.Unmarshal(part, &e.Text); err == nil {
+			e.Type = "plain"
+		} else if err := json.Unmarshal(part, &e); err != nil {
+			return nil, fmt.Errorf("invalid text: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// exportLink converts a marked up piece of text to a link, like entityLinks
+// does for api messages
+func exportLink(e exportEntity) (Link, bool) {
+	text := strings.TrimSpace(e.Text)
+	var link Link
+	switch e.Type {
+	case "link":
+		link = Link{Type: LinkURL, URL: text}
+	case "text_link":
+		link = Link{Type: LinkTextURL, Text: text, URL: e.Href}
+	case "mention":
+		link = Link{Type: LinkMention, URL: text}
+	case "email":
+		link = Link{Type: LinkEmail, URL: text}
+	case "phone":
+		link = Link{Type: LinkPhone, URL: text}
+	default:
+		return Link{}, false
+	}
+	return link, link.URL != ""
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testExport = `{
+ "name": "Go Jobs",
+ "type": "private_channel",
+ "id": 1234567890,
+ "messages": [
+  {"id": 1, "type": "service", "date": "2023-01-10T09:00:00", "date_unixtime": "1673341200", "action": "create_channel", "text": "", "text_entities": []},
+  {
+   "id": 2, "type": "message", "date": "2023-01-10T10:00:00", "date_unixtime": "1673344800",
+   "edited": "2023-01-10T11:00:00", "edited_unixtime": "1673348400",
+   "text": ["Go developer, write ", {"type": "mention", "text": "@hr_anna"}, " or ", {"type": "text_link", "text": "apply", "href": "https://example.com/apply"}],
+   "text_entities": [
+    {"type": "plain", "text": "Go developer, write "},
+    {"type": "mention", "text": "@hr_anna"},
+    {"type": "plain", "text": " or "},
+    {"type": "text_link", "text": "apply", "href": "https://example.com/apply"},
+    {"type": "hashtag", "text": " #go"}
+   ]
+  },
+  {
+   "id": 3, "type": "message", "date": "2023-01-11T10:00:00", "date_unixtime": "1673431200",
+   "file": "files/vacancy.pdf", "file_name": "vacancy.pdf", "file_size": 2048, "mime_type": "application/pdf",
+   "text": "", "text_entities": []
+  },
+  {
+   "id": 4, "type": "message", "date": "2023-01-12T10:00:00", "date_unixtime": "1673517600",
+   "file": "(File not included. Change data exporting settings to download.)", "file_size": 99999999,
+   "text": "Big file", "text_entities": [{"type": "plain", "text": "Big file"}]
+  },
+  {
+   "id": 5, "type": "message", "date": "2023-01-13T10:00:00",
+   "photo": "photos/photo_1.jpg", "photo_file_size": 512,
+   "text": ["Old export ", {"type": "email", "text": "hr@example.com"}]
+  }
+ ]
+}`
+
+// test reading a telegram desktop export
+func TestReadExport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "result.json"), []byte(testExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := ReadExport(dir)
+	if err != nil {
+		t.Fatalf("ReadExport() error: %v", err)
+	}
+	if export.Name != "Go Jobs" || export.ID != 1234567890 || export.TargetType() != "TG_CHANNEL" {
+		t.Errorf("unexpected export: %+v", export)
+	}
+	if len(export.Messages) != 4 {
+		t.Fatalf("got %d messages, want 4 (service message left out)", len(export.Messages))
+	}
+
+	post := export.Messages[0]
+	if post.ID != 2 || post.ChannelID != 1234567890 || post.Text != "Go developer, write @hr_anna or apply #go" {
+		t.Errorf("unexpected post: %+v", post)
+	}
+	if !post.Date.Equal(time.Unix(1673344800, 0)) || post.EditDate == nil || !post.EditDate.Equal(time.Unix(1673348400, 0)) {
+		t.Errorf("dates = %v, %v", post.Date, post.EditDate)
+	}
+	wantLinks := []Link{
+		{Type: LinkMention, URL: "@hr_anna"},
+		{Type: LinkTextURL, Text: "apply", URL: "https://example.com/apply"},
+	}
+	if !reflect.DeepEqual(post.Links, wantLinks) {
+		t.Errorf("links = %+v, want %+v", post.Links, wantLinks)
+	}
+
+	doc := export.Messages[1].Attachment
+	if doc == nil || doc.Type != AttachmentDocument || doc.FileName != "vacancy.pdf" || doc.Size != 2048 ||
+		doc.Path != filepath.Join(dir, "files", "vacancy.pdf") {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	if export.Messages[2].Attachment != nil {
+		t.Errorf("file left out of the export should not be attached: %+v", export.Messages[2].Attachment)
+	}
+
+	old := export.Messages[3]
+	if old.Text != "Old export hr@example.com" || len(old.Links) != 1 || old.Links[0].URL != "hr@example.com" {
+		t.Errorf("text array of an old export: %+v", old)
+	}
+	if old.Attachment == nil || old.Attachment.Type != AttachmentPhoto || old.Attachment.Path != filepath.Join(dir, "photos", "photo_1.jpg") {
+		t.Errorf("unexpected photo: %+v", old.Attachment)
+	}
+	if want := time.Date(2023, 1, 13, 10, 0, 0, 0, time.Local); !old.Date.Equal(want) {
+		t.Errorf("local date = %v, want %v", old.Date, want)
+	}
+}
+
+// test that only channels and groups can be imported
+func TestReadExport_Unsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := os.WriteFile(path, []byte(`{"name": "Anna", "type": "personal_chat", "id": 42, "messages": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadExport(path); !errors.Is(err, ErrUnsupportedExport) {
+		t.Errorf("ReadExport() error = %v, want ErrUnsupportedExport", err)
+	}
+}
@@ -26,6 +26,8 @@ func NewPersistentClient(ctx context.Context, cfg *config.Config, db *gorm.DB) (
 		Session:          sessionConstructor,
 		DisableCopyright: true,
 		InMemory:         false, // Essential: persistence enabled
+		// joined channels become resolvable by id (private targets without an access hash)
+		PeersFromDialogs: true,
 	}
 
 	// 3. Create the client
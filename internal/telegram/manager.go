@@ -11,8 +11,11 @@ import (
 	"github.com/blockedby/positions-os/internal/config"
 	"github.com/blockedby/positions-os/internal/logger"
 	"github.com/celestix/gotgproto" // Added this import
+	"github.com/celestix/gotgproto/dispatcher/handlers"
+	"github.com/celestix/gotgproto/ext"
 	"github.com/gotd/td/session"
 	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +39,17 @@ type QRClientFactory func(cfg *config.Config) (*QRClientBundle, error)
 // maxMsgID is the highest message ID that was read.
 type ReadReceiptCallback func(ctx context.Context, peerUserID int64, maxMsgID int64) error
 
+// ChannelMessageCallback is called for every new or edited message in a channel the account is subscribed to.
+// edited messages have EditDate set.
+type ChannelMessageCallback func(ctx context.Context, msg Message) error
+
+// ChannelDeleteCallback is called when messages are deleted in a channel the account is subscribed to.
+type ChannelDeleteCallback func(ctx context.Context, channelID int64, msgIDs []int) error
+
+// generalTopicID is the id of the "General" topic of a forum.
+// messages in it carry no topic in the reply header.
+const generalTopicID = 1
+
 type Manager struct {
 	client *gotgproto.Client
 	db     *gorm.DB
@@ -56,6 +70,14 @@ type Manager struct {
 	// Read receipt callback for dispatcher integration
 	readReceiptCallback      ReadReceiptCallback
 	readReceiptCallbackMu    sync.RWMutex
+
+	// New channel message callback for live ingestion
+	channelMessageCallback   ChannelMessageCallback
+	channelMessageCallbackMu sync.RWMutex
+
+	// Deleted channel messages callback for closing jobs
+	channelDeleteCallback   ChannelDeleteCallback
+	channelDeleteCallbackMu sync.RWMutex
 }
 
 func NewManager(cfg *config.Config, db *gorm.DB) *Manager {
@@ -106,6 +128,99 @@ func (m *Manager) OnReadReceipt(ctx context.Context, peerUserID int64, maxMsgID
 	return cb(ctx, peerUserID, maxMsgID)
 }
 
+// SetChannelMessageCallback sets the callback for new channel messages.
+// This is used by the collector to create jobs from live updates.
+func (m *Manager) SetChannelMessageCallback(cb ChannelMessageCallback) {
+	m.channelMessageCallbackMu.Lock()
+	defer m.channelMessageCallbackMu.Unlock()
+	m.channelMessageCallback = cb
+}
+
+// OnChannelMessage forwards a new channel message to the registered callback (if any).
+func (m *Manager) OnChannelMessage(ctx context.Context, msg Message) error {
+	m.channelMessageCallbackMu.RLock()
+	cb := m.channelMessageCallback
+	m.channelMessageCallbackMu.RUnlock()
+
+	if cb == nil {
+		return nil // No callback registered, ignore
+	}
+
+	return cb(ctx, msg)
+}
+
+// SetChannelDeleteCallback sets the callback for deleted channel messages.
+// This is used by the collector to close jobs whose post was deleted.
+func (m *Manager) SetChannelDeleteCallback(cb ChannelDeleteCallback) {
+	m.channelDeleteCallbackMu.Lock()
+	defer m.channelDeleteCallbackMu.Unlock()
+	m.channelDeleteCallback = cb
+}
+
+// OnChannelDelete forwards deleted channel messages to the registered callback (if any).
+func (m *Manager) OnChannelDelete(ctx context.Context, channelID int64, msgIDs []int) error {
+	m.channelDeleteCallbackMu.RLock()
+	cb := m.channelDeleteCallback
+	m.channelDeleteCallbackMu.RUnlock()
+
+	if cb == nil {
+		return nil // No callback registered, ignore
+	}
+
+	return cb(ctx, channelID, msgIDs)
+}
+
+// handleUpdate is registered in the client dispatcher and forwards new and deleted channel messages.
+// errors are only logged, so one bad message does not stop other handlers.
+func (m *Manager) handleUpdate(ctx *ext.Context, u *ext.Update) error {
+	if del, ok := u.UpdateClass.(*tg.UpdateDeleteChannelMessages); ok {
+		if err := m.OnChannelDelete(ctx, del.ChannelID, del.Messages); err != nil {
+			m.log.Warn().Err(err).Int64("channel_id", del.ChannelID).Ints("msg_ids", del.Messages).Msg("telegram: failed to handle deleted channel messages")
+		}
+		return nil
+	}
+
+	msg, ok := channelMessageFromUpdate(u.UpdateClass, u.Entities)
+	if !ok {
+		return nil
+	}
+	if err := m.OnChannelMessage(ctx, msg); err != nil {
+		m.log.Warn().Err(err).Int64("channel_id", msg.ChannelID).Int("msg_id", msg.ID).Msg("telegram: failed to handle channel message")
+	}
+	return nil
+}
+
+// channelMessageFromUpdate extracts a new or edited channel message from an update.
+// messages of the General forum topic are stamped with its id.
+func channelMessageFromUpdate(update tg.UpdateClass, entities *tg.Entities) (Message, bool) {
+	var message tg.MessageClass
+	switch u := update.(type) {
+	case *tg.UpdateNewChannelMessage:
+		message = u.Message
+	case *tg.UpdateEditChannelMessage:
+		message = u.Message
+	default:
+		return Message{}, false
+	}
+	raw, ok := message.(*tg.Message)
+	if !ok {
+		return Message{}, false
+	}
+	peer, ok := raw.PeerID.(*tg.PeerChannel)
+	if !ok {
+		return Message{}, false
+	}
+
+	msg := newMessage(raw, peer.ChannelID)
+	if msg.TopicID == nil && entities != nil {
+		if ch, ok := entities.Channels[peer.ChannelID]; ok && ch.Forum {
+			tid := generalTopicID
+			msg.TopicID = &tid
+		}
+	}
+	return msg, true
+}
+
 func (m *Manager) GetStatus() Status {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -151,6 +266,11 @@ func (m *Manager) Init(ctx context.Context) error {
 		return nil // Don't return error to keep the app running
 	}
 
+	// forward new channel messages to the live ingestion callback
+	if client.Dispatcher != nil {
+		client.Dispatcher.AddHandler(handlers.NewAnyUpdate(m.handleUpdate))
+	}
+
 	m.mu.Lock()
 	m.client = client
 	m.status = StatusReady
@@ -12,6 +12,7 @@ import (
 	"github.com/celestix/gotgproto"
 	"github.com/glebarez/sqlite"
 	"github.com/gotd/td/session"
+	"github.com/gotd/td/tg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -109,6 +110,98 @@ func TestManager_Stop_Graceful(t *testing.T) {
 	})
 }
 
+func TestManager_OnChannelMessage(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	m := NewManager(&config.Config{}, db)
+
+	// no callback registered
+	require.NoError(t, m.OnChannelMessage(context.Background(), Message{ID: 1}))
+
+	var got Message
+	m.SetChannelMessageCallback(func(ctx context.Context, msg Message) error {
+		got = msg
+		return nil
+	})
+	require.NoError(t, m.OnChannelMessage(context.Background(), Message{ID: 42, ChannelID: 7}))
+	assert.Equal(t, 42, got.ID)
+	assert.Equal(t, int64(7), got.ChannelID)
+}
+
+func TestManager_OnChannelDelete(t *testing.T) {
+	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	m := NewManager(&config.Config{}, db)
+
+	// no callback registered
+	require.NoError(t, m.OnChannelDelete(context.Background(), 7, []int{1}))
+
+	var gotChannel int64
+	var gotIDs []int
+	m.SetChannelDeleteCallback(func(ctx context.Context, channelID int64, msgIDs []int) error {
+		gotChannel, gotIDs = channelID, msgIDs
+		return nil
+	})
+	require.NoError(t, m.OnChannelDelete(context.Background(), 7, []int{42, 43}))
+	assert.Equal(t, int64(7), gotChannel)
+	assert.Equal(t, []int{42, 43}, gotIDs)
+}
+
+func TestChannelMessageFromUpdate(t *testing.T) {
+	newUpdate := func(msg tg.MessageClass) *tg.UpdateNewChannelMessage {
+		return &tg.UpdateNewChannelMessage{Message: msg}
+	}
+	entities := &tg.Entities{Channels: map[int64]*tg.Channel{
+		100: {ID: 100},
+		200: {ID: 200, Forum: true},
+	}}
+
+	t.Run("channel post", func(t *testing.T) {
+		msg, ok := channelMessageFromUpdate(newUpdate(&tg.Message{
+			ID:      5,
+			PeerID:  &tg.PeerChannel{ChannelID: 100},
+			Message: "Go developer wanted",
+			Date:    1700000000,
+		}), entities)
+		require.True(t, ok)
+		assert.Equal(t, 5, msg.ID)
+		assert.Equal(t, int64(100), msg.ChannelID)
+		assert.Equal(t, "Go developer wanted", msg.Text)
+		assert.Nil(t, msg.TopicID)
+	})
+
+	t.Run("forum message without topic goes to general", func(t *testing.T) {
+		msg, ok := channelMessageFromUpdate(newUpdate(&tg.Message{
+			ID:     6,
+			PeerID: &tg.PeerChannel{ChannelID: 200},
+		}), entities)
+		require.True(t, ok)
+		require.NotNil(t, msg.TopicID)
+		assert.Equal(t, 1, *msg.TopicID)
+	})
+
+	t.Run("edited channel post", func(t *testing.T) {
+		msg, ok := channelMessageFromUpdate(&tg.UpdateEditChannelMessage{Message: &tg.Message{
+			ID:       5,
+			PeerID:   &tg.PeerChannel{ChannelID: 100},
+			Message:  "Go developer wanted, position closed",
+			Date:     1700000000,
+			EditDate: 1700003600,
+		}}, entities)
+		require.True(t, ok)
+		assert.Equal(t, 5, msg.ID)
+		assert.Equal(t, "Go developer wanted, position closed", msg.Text)
+		require.NotNil(t, msg.EditDate)
+		assert.Equal(t, int64(1700003600), msg.EditDate.Unix())
+	})
+
+	t.Run("ignores other updates", func(t *testing.T) {
+		_, ok := channelMessageFromUpdate(&tg.UpdateNewMessage{Message: &tg.Message{ID: 1}}, entities)
+		assert.False(t, ok)
+
+		_, ok = channelMessageFromUpdate(newUpdate(&tg.MessageService{ID: 2}), entities)
+		assert.False(t, ok)
+	})
+}
+
 func TestConvertToGotgprotoSession_RoundTrip(t *testing.T) {
 	// Arrange
 	input := &session.Data{
@@ -58,3 +58,15 @@ func (r *RateLimiter) SetFloodWait(seconds int) {
 
 	r.floodWaitUntil = time.Now().Add(time.Duration(seconds) * time.Second)
 }
+
+// FloodWaitUntil returns the time until which requests are paused.
+// zero time if no FLOOD_WAIT is active.
+func (r *RateLimiter) FloodWaitUntil() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Now().After(r.floodWaitUntil) {
+		return time.Time{}
+	}
+	return r.floodWaitUntil
+}
@@ -68,6 +68,21 @@ func TestRateLimiter_SetFloodWait(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_FloodWaitUntil(t *testing.T) {
+	rl := NewRateLimiter(10.0, 1)
+
+	if got := rl.FloodWaitUntil(); !got.IsZero() {
+		t.Errorf("expected zero time without flood wait, got %v", got)
+	}
+
+	rl.SetFloodWait(30)
+
+	got := rl.FloodWaitUntil()
+	if got.Before(time.Now().Add(29*time.Second)) || got.After(time.Now().Add(31*time.Second)) {
+		t.Errorf("expected flood wait ~30s from now, got %v", got)
+	}
+}
+
 func TestRateLimiter_RateLimiting(t *testing.T) {
 	// Test that rate limiting actually throttles requests
 	rl := NewRateLimiter(10.0, 1) // 10 requests per second = 100ms between requests
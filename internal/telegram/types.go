@@ -1,9 +1,20 @@
 package telegram
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrNotReady is returned when there is no authorized telegram session
+	ErrNotReady = errors.New("telegram client not authorized")
+	// ErrChannelNotFound is returned for usernames that are not taken or do
+	// not belong to a channel or group
+	ErrChannelNotFound = errors.New("channel not found")
+	// ErrChannelPrivate is returned for channels the session can not read
+	ErrChannelPrivate = errors.New("channel is private or not accessible")
+)
+
 // Message represents a parsed telegram message
 type Message struct {
 	ID        int       `json:"id"`
@@ -13,6 +24,53 @@ type Message struct {
 	TopicID   *int      `json:"topic_id"`
 	Views     int       `json:"views"`
 	Forwards  int       `json:"forwards"`
+	// EditDate is set when the message was edited after posting
+	EditDate *time.Time `json:"edit_date,omitempty"`
+	// Attachment is the document or photo of the message, Text is its caption
+	Attachment *Attachment `json:"attachment,omitempty"`
+	// Links are the urls and contacts of the message entities
+	Links []Link `json:"links,omitempty"`
+	// Replies is the number of comments on a channel post
+	// (in the linked discussion group)
+	Replies int `json:"replies,omitempty"`
+}
+
+// link types of message entities
+const (
+	LinkURL     = "url"
+	LinkTextURL = "text_url"
+	LinkMention = "mention"
+	LinkEmail   = "email"
+	LinkPhone   = "phone"
+)
+
+// Link is a url or contact marked up by a message entity
+type Link struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"` // visible text of a text_url
+	URL  string `json:"url"`            // url, @username, email or phone number
+}
+
+// attachment types
+const (
+	AttachmentDocument = "document"
+	AttachmentPhoto    = "photo"
+)
+
+// Attachment is the media of a message: metadata and the file location
+// needed to download it
+type Attachment struct {
+	Type          string `json:"type"` // document, photo
+	FileName      string `json:"file_name,omitempty"`
+	MimeType      string `json:"mime_type,omitempty"`
+	Size          int64  `json:"size,omitempty"`
+	ID            int64  `json:"id"`
+	AccessHash    int64  `json:"access_hash"`
+	FileReference []byte `json:"file_reference,omitempty"`
+	DCID          int    `json:"dc_id"`
+	// Path is the local file of an exported message (see ReadExport),
+	// messages from the api are downloaded instead
+	Path string `json:"path,omitempty"`
 }
 
 // Topic represents a forum topic
@@ -31,6 +89,24 @@ type Channel struct {
 	Username   string `json:"username"`
 	Title      string `json:"title"`
 	IsForum    bool   `json:"is_forum"`
+	// IsBroadcast is set for channels, IsMegagroup for groups (supergroups)
+	IsBroadcast bool `json:"is_broadcast"`
+	IsMegagroup bool `json:"is_megagroup"`
+	// LinkedChat is the discussion group of a broadcast channel,
+	// where the comments on its posts live. nil if it has none.
+	LinkedChat *Channel `json:"linked_chat,omitempty"`
+}
+
+// TargetType returns the scraping target type of the channel:
+// TG_FORUM for forums, TG_GROUP for other groups, TG_CHANNEL for channels
+func (c *Channel) TargetType() string {
+	switch {
+	case c.IsForum:
+		return "TG_FORUM"
+	case c.IsMegagroup:
+		return "TG_GROUP"
+	}
+	return "TG_CHANNEL"
 }
 
 // ParsedRange represents a range of scraped message ids
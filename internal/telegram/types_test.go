@@ -214,6 +214,23 @@ func TestScrapeStats_Summary(t *testing.T) {
 	}
 }
 
+// test target types of channels, groups and forums
+func TestChannel_TargetType(t *testing.T) {
+	tests := []struct {
+		channel Channel
+		want    string
+	}{
+		{Channel{IsBroadcast: true}, "TG_CHANNEL"},
+		{Channel{IsMegagroup: true}, "TG_GROUP"},
+		{Channel{IsMegagroup: true, IsForum: true}, "TG_FORUM"},
+	}
+	for _, tt := range tests {
+		if got := tt.channel.TargetType(); got != tt.want {
+			t.Errorf("TargetType() of %+v = %s, want %s", tt.channel, got, tt.want)
+		}
+	}
+}
+
 // helper to create int pointer
 func intPtr(i int) *int {
 	return &i
@@ -10,8 +10,13 @@ import (
 const (
 	EventJobNew      = "job.new"
 	EventJobUpdated  = "job.updated"
-	EventScrapeStart = "scrape.start"
-	EventScrapeEnd   = "scrape.end"
+	EventScrapeStart = "scrape.started"
+	EventScrapeEnd   = "scrape.completed"
+
+	// Scrape progress and outcome besides completion
+	EventScrapeProgress  = "scrape.progress"
+	EventScrapeFailed    = "scrape.failed"
+	EventScrapeCancelled = "scrape.cancelled"
 
 	// Brain events
 	EventBrainStarted  = "brain.started"
@@ -163,7 +163,7 @@ func (h *ApplicationsHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // SendRequest is the payload for sending an application.
 type SendRequest struct {
-	Recipient string `json:"recipient"` // e.g., "@recruiter" for Telegram, email for EMAIL channel
+	Recipient string `json:"recipient"` // e.g., "@recruiter" for Telegram, email for EMAIL channel; empty: from the job contacts
 }
 
 // Send sends an application via the configured channel.
@@ -200,12 +200,6 @@ func (h *ApplicationsHandler) Send(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate recipient
-	if req.Recipient == "" {
-		http.Error(w, `{"error":"recipient is required"}`, http.StatusBadRequest)
-		return
-	}
-
 	// Determine channel
 	channel := "TG_DM"
 	if app.DeliveryChannel != nil {
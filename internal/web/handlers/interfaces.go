@@ -12,6 +12,9 @@ type JobsRepository interface {
 	List(ctx context.Context, filter repository.JobFilter) ([]*repository.Job, int, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*repository.Job, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	GetDuplicates(ctx context.Context, id uuid.UUID) ([]*repository.Job, error)
+	GetCluster(ctx context.Context, id uuid.UUID) ([]*repository.Job, error)
+	GetRevisions(ctx context.Context, jobID uuid.UUID) ([]repository.JobRevision, error)
 }
 
 // StatsRepository defines interface for stats data access
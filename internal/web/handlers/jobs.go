@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -77,13 +78,16 @@ func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
 	salaryMax, _ := strconv.Atoi(r.URL.Query().Get("salary_max"))
 
 	filter := repository.JobFilter{
-		Status:    r.URL.Query().Get("status"),
-		Tech:      r.URL.Query().Get("tech"),
-		Query:     r.URL.Query().Get("q"),
-		SalaryMin: salaryMin,
-		SalaryMax: salaryMax,
-		Page:      page,
-		Limit:     limit,
+		Status:     r.URL.Query().Get("status"),
+		Tech:       r.URL.Query().Get("tech"),
+		Query:      r.URL.Query().Get("q"),
+		SalaryMin:  salaryMin,
+		SalaryMax:  salaryMax,
+		Page:       page,
+		Limit:      limit,
+		Canonical:  r.URL.Query().Get("canonical") == "true",
+		Collapse:   r.URL.Query().Get("collapse") == "true",
+		HideClosed: r.URL.Query().Get("hide_closed") == "true",
 	}
 
 	jobs, total, err := h.repo.List(r.Context(), filter)
@@ -129,3 +133,109 @@ func (h *JobsHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(job)
 }
+
+// Duplicates returns the duplicate group of a job, canonical job first
+func (h *JobsHandler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := h.repo.GetDuplicates(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(jobs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := struct {
+		CanonicalID uuid.UUID         `json:"canonical_id"`
+		Jobs        []*repository.Job `json:"jobs"`
+	}{
+		CanonicalID: jobs[0].ID,
+		Jobs:        jobs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Cluster returns the near-duplicate cluster of a job, newest first
+func (h *JobsHandler) Cluster(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := h.repo.GetCluster(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(jobs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	clusterID := jobs[0].ID
+	if jobs[0].ClusterID != nil {
+		clusterID = *jobs[0].ClusterID
+	}
+
+	resp := struct {
+		ClusterID uuid.UUID         `json:"cluster_id"`
+		Jobs      []*repository.Job `json:"jobs"`
+	}{
+		ClusterID: clusterID,
+		Jobs:      jobs,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Revisions returns earlier versions of an edited job, newest first
+func (h *JobsHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	revisions, err := h.repo.GetRevisions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := struct {
+		JobID     uuid.UUID                `json:"job_id"`
+		EditedAt  *time.Time               `json:"edited_at,omitempty"`
+		Revisions []repository.JobRevision `json:"revisions"`
+	}{
+		JobID:     id,
+		EditedAt:  job.EditedAt,
+		Revisions: revisions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
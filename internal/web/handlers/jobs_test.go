@@ -41,6 +41,21 @@ func (m *MockJobsRepository) GetByID(ctx context.Context, id uuid.UUID) (*reposi
 	return args.Get(0).(*repository.Job), args.Error(1)
 }
 
+func (m *MockJobsRepository) GetDuplicates(ctx context.Context, id uuid.UUID) ([]*repository.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*repository.Job), args.Error(1)
+}
+
+func (m *MockJobsRepository) GetCluster(ctx context.Context, id uuid.UUID) ([]*repository.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*repository.Job), args.Error(1)
+}
+
+func (m *MockJobsRepository) GetRevisions(ctx context.Context, jobID uuid.UUID) ([]repository.JobRevision, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).([]repository.JobRevision), args.Error(1)
+}
+
 func TestJobsAPI_List(t *testing.T) {
 	mockRepo := new(MockJobsRepository)
 
@@ -110,6 +125,23 @@ func TestJobsAPI_Filters(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestJobsAPI_HideClosed(t *testing.T) {
+	mockRepo := new(MockJobsRepository)
+	handler := NewJobsHandler(mockRepo, nil)
+
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(f repository.JobFilter) bool {
+		return f.HideClosed && f.Status == ""
+	})).Return([]*repository.Job{}, 0, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/jobs?hide_closed=true", nil)
+	rec := httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestJobsAPI_GetByID(t *testing.T) {
 	mockRepo := new(MockJobsRepository)
 	handler := NewJobsHandler(mockRepo, nil)
@@ -135,6 +167,141 @@ func TestJobsAPI_GetByID(t *testing.T) {
 	assert.Equal(t, id, resp.ID)
 }
 
+func TestJobsAPI_Duplicates(t *testing.T) {
+	mockRepo := new(MockJobsRepository)
+	handler := NewJobsHandler(mockRepo, nil)
+
+	canonicalID := uuid.New()
+	dupID := uuid.New()
+	group := []*repository.Job{
+		{ID: canonicalID, ExternalID: "10"},
+		{ID: dupID, ExternalID: "20", DuplicateOf: &canonicalID},
+	}
+	missingID := uuid.New()
+
+	mockRepo.On("GetDuplicates", mock.Anything, dupID).Return(group, nil)
+	mockRepo.On("GetDuplicates", mock.Anything, missingID).Return([]*repository.Job{}, nil)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/jobs/{id}/duplicates", handler.Duplicates)
+
+	t.Run("group", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs/"+dupID.String()+"/duplicates", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			CanonicalID uuid.UUID         `json:"canonical_id"`
+			Jobs        []*repository.Job `json:"jobs"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, canonicalID, resp.CanonicalID)
+		require.Len(t, resp.Jobs, 2)
+		assert.Equal(t, &canonicalID, resp.Jobs[1].DuplicateOf)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs/"+missingID.String()+"/duplicates", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestJobsAPI_Cluster(t *testing.T) {
+	mockRepo := new(MockJobsRepository)
+	handler := NewJobsHandler(mockRepo, nil)
+
+	clusterID := uuid.New()
+	newerID := uuid.New()
+	cluster := []*repository.Job{
+		{ID: newerID, ExternalID: "42", ClusterID: &clusterID},
+		{ID: clusterID, ExternalID: "17", ClusterID: &clusterID},
+	}
+
+	mockRepo.On("GetCluster", mock.Anything, newerID).Return(cluster, nil)
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(f repository.JobFilter) bool {
+		return f.Collapse && !f.Canonical
+	})).Return([]*repository.Job{cluster[0]}, 1, nil)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/jobs", handler.List)
+	r.Get("/api/v1/jobs/{id}/cluster", handler.Cluster)
+
+	t.Run("cluster", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs/"+newerID.String()+"/cluster", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			ClusterID uuid.UUID         `json:"cluster_id"`
+			Jobs      []*repository.Job `json:"jobs"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, clusterID, resp.ClusterID)
+		assert.Len(t, resp.Jobs, 2)
+	})
+
+	t.Run("collapsed list", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs?collapse=true", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestJobsAPI_Revisions(t *testing.T) {
+	mockRepo := new(MockJobsRepository)
+	handler := NewJobsHandler(mockRepo, nil)
+
+	id := uuid.New()
+	editedAt := time.Now().Add(-time.Hour)
+	missingID := uuid.New()
+
+	mockRepo.On("GetByID", mock.Anything, id).Return(&repository.Job{ID: id, EditedAt: &editedAt}, nil)
+	mockRepo.On("GetByID", mock.Anything, missingID).Return(nil, nil)
+	mockRepo.On("GetRevisions", mock.Anything, id).Return([]repository.JobRevision{
+		{ID: uuid.New(), JobID: id, RawContent: "Go developer, 300k"},
+	}, nil)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/jobs/{id}/revisions", handler.Revisions)
+
+	t.Run("revisions", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs/"+id.String()+"/revisions", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp struct {
+			JobID     uuid.UUID                `json:"job_id"`
+			EditedAt  *time.Time               `json:"edited_at"`
+			Revisions []repository.JobRevision `json:"revisions"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.Equal(t, id, resp.JobID)
+		require.NotNil(t, resp.EditedAt)
+		require.Len(t, resp.Revisions, 1)
+		assert.Equal(t, "Go developer, 300k", resp.Revisions[0].RawContent)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/jobs/"+missingID.String()+"/revisions", nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
 func (m *MockJobsRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
 	args := m.Called(ctx, id, status)
 	return args.Error(0)
@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/blockedby/positions-os/internal/models"
 	"github.com/blockedby/positions-os/internal/repository"
-	"github.com/blockedby/positions-os/internal/web"
+	"github.com/blockedby/positions-os/internal/telegram"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -17,6 +21,9 @@ type TargetsRepository interface {
 	Update(ctx context.Context, t *repository.ScrapingTarget) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (*repository.ScrapingTarget, error)
+	GetByURL(ctx context.Context, url string) (*repository.ScrapingTarget, error)
+	GetByChannelID(ctx context.Context, channelID int64) (*repository.ScrapingTarget, error)
+	ListUnhealthy(ctx context.Context) ([]repository.ScrapingTarget, error)
 }
 
 var validTargetTypes = map[string]bool{
@@ -24,20 +31,31 @@ var validTargetTypes = map[string]bool{
 	"TG_GROUP":   true,
 	"TG_FORUM":   true,
 	"HH_SEARCH":  true,
+	"FEED":       true,
+}
+
+// TargetResolver checks a telegram target against telegram before it is
+// stored, detecting its type and normalizing its url (collector.Service)
+type TargetResolver interface {
+	ResolveTarget(ctx context.Context, t *repository.ScrapingTarget) error
 }
 
 type TargetsHandler struct {
-	repo      TargetsRepository
-	templates *web.TemplateEngine
+	repo     TargetsRepository
+	resolver TargetResolver // nil: telegram targets are stored as given
 }
 
-func NewTargetsHandler(repo TargetsRepository, templates *web.TemplateEngine) *TargetsHandler {
+func NewTargetsHandler(repo TargetsRepository) *TargetsHandler {
 	return &TargetsHandler{
-		repo:      repo,
-		templates: templates,
+		repo: repo,
 	}
 }
 
+// SetResolver enables resolving telegram targets on creation
+func (h *TargetsHandler) SetResolver(resolver TargetResolver) {
+	h.resolver = resolver
+}
+
 // List returns the list of targets.
 func (h *TargetsHandler) List(w http.ResponseWriter, r *http.Request) {
 	targets, err := h.repo.List(r.Context())
@@ -51,14 +69,22 @@ func (h *TargetsHandler) List(w http.ResponseWriter, r *http.Request) {
 		targets = []repository.ScrapingTarget{}
 	}
 
-	if r.Header.Get("HX-Request") == "true" {
-		data := map[string]interface{}{
-			"Targets": targets,
-		}
-		h.templates.RenderContent(w, "targets-list", data)
+	respondJSON(w, http.StatusOK, targets)
+}
+
+// Health returns the unhealthy targets: the ones whose last run failed and
+// the ones deactivated after consecutive failures, with their last error
+func (h *TargetsHandler) Health(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.repo.ListUnhealthy(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	if targets == nil {
+		targets = []repository.ScrapingTarget{}
+	}
+
 	respondJSON(w, http.StatusOK, targets)
 }
 
@@ -87,15 +113,17 @@ func (h *TargetsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.URL = r.FormValue("url")
 	}
 
-	if req.Name == "" {
+	// telegram targets get their type and name from the resolved channel
+	resolve := h.resolver != nil && (req.Type == "" || strings.HasPrefix(req.Type, "TG_"))
+	if req.Name == "" && !resolve {
 		respondError(w, http.StatusBadRequest, "name is required")
 		return
 	}
-	if req.Type == "" {
+	if req.Type == "" && !resolve {
 		respondError(w, http.StatusBadRequest, "type is required")
 		return
 	}
-	if !validTargetTypes[req.Type] {
+	if req.Type != "" && !validTargetTypes[req.Type] {
 		respondError(w, http.StatusBadRequest, "invalid type: "+req.Type)
 		return
 	}
@@ -108,6 +136,10 @@ func (h *TargetsHandler) Create(w http.ResponseWriter, r *http.Request) {
 	if metadata == nil {
 		metadata = make(map[string]interface{})
 	}
+	if err := validateMetadata(metadata); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid metadata: "+err.Error())
+		return
+	}
 
 	isActive := true
 	if req.IsActive != nil {
@@ -122,18 +154,21 @@ func (h *TargetsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Metadata: metadata,
 	}
 
-	if err := h.repo.Create(r.Context(), t); err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+	if resolve {
+		if err := h.resolver.ResolveTarget(r.Context(), t); err != nil {
+			respondResolveError(w, err)
+			return
+		}
+	}
+	if !h.checkUnique(r.Context(), w, t) {
 		return
 	}
 
-	// Return the new row HTML for HTMX
-	if r.Header.Get("HX-Request") == "true" {
-		h.templates.RenderPartial(w, "target-row", map[string]interface{}{"Target": t})
+	if err := h.repo.Create(r.Context(), t); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Return JSON for API requests
 	respondJSON(w, http.StatusCreated, t)
 }
 
@@ -172,7 +207,7 @@ func (h *TargetsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // UpdateTargetRequest represents the JSON body for updating a target
@@ -200,6 +235,7 @@ func (h *TargetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "target not found")
 		return
 	}
+	oldURL := t.URL
 
 	// Try JSON first (for React frontend)
 	if r.Header.Get("Content-Type") == "application/json" {
@@ -219,6 +255,10 @@ func (h *TargetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 			t.IsActive = *req.IsActive
 		}
 		if req.Metadata != nil {
+			if err := validateMetadata(req.Metadata); err != nil {
+				respondError(w, http.StatusBadRequest, "invalid metadata: "+err.Error())
+				return
+			}
 			t.Metadata = req.Metadata
 		}
 	} else {
@@ -236,17 +276,77 @@ func (h *TargetsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		t.IsActive = isActive
 	}
 
+	// the stored channel belongs to the old url: a telegram target with a
+	// new url is resolved again, without a resolver it is resolved on its
+	// next scrape
+	if t.URL != oldURL || !strings.HasPrefix(t.Type, "TG_") {
+		t.TgChannelID, t.TgAccessHash = nil, nil
+	}
+	if t.URL != oldURL && strings.HasPrefix(t.Type, "TG_") {
+		if h.resolver != nil {
+			if err := h.resolver.ResolveTarget(r.Context(), t); err != nil {
+				respondResolveError(w, err)
+				return
+			}
+		}
+		if !h.checkUnique(r.Context(), w, t) {
+			return
+		}
+	}
+
 	if err := h.repo.Update(r.Context(), t); err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if r.Header.Get("HX-Request") == "true" {
-		h.templates.RenderPartial(w, "target-row", map[string]interface{}{"Target": t})
-		return
+	respondJSON(w, http.StatusOK, t)
+}
+
+// checkUnique answers 409 when the channel of a telegram target is already
+// stored as another target, found by its resolved channel id or its url
+func (h *TargetsHandler) checkUnique(ctx context.Context, w http.ResponseWriter, t *repository.ScrapingTarget) bool {
+	if !strings.HasPrefix(t.Type, "TG_") {
+		return true
+	}
+	var existing *repository.ScrapingTarget
+	var err error
+	if t.TgChannelID != nil && *t.TgChannelID != 0 {
+		existing, err = h.repo.GetByChannelID(ctx, *t.TgChannelID)
+	}
+	if err == nil && existing == nil {
+		existing, err = h.repo.GetByURL(ctx, t.URL)
 	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if existing != nil && existing.ID != t.ID {
+		respondError(w, http.StatusConflict, fmt.Sprintf("channel is already a target: %s (%s)", existing.Name, existing.ID))
+		return false
+	}
+	return true
+}
 
-	respondJSON(w, http.StatusOK, t)
+// respondResolveError answers a failed telegram resolve: unknown and private
+// channels are the request's fault, a disconnected telegram is temporary
+func respondResolveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, telegram.ErrChannelNotFound), errors.Is(err, telegram.ErrChannelPrivate):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, telegram.ErrNotReady):
+		respondError(w, http.StatusServiceUnavailable, "telegram is not connected, the channel can not be checked")
+	default:
+		respondError(w, http.StatusBadGateway, "resolve channel: "+err.Error())
+	}
+}
+
+// validateMetadata checks the schedule and filter settings of target metadata
+func validateMetadata(raw map[string]interface{}) error {
+	meta, err := models.ParseTargetMetadata(raw)
+	if err != nil {
+		return err
+	}
+	return meta.Validate()
 }
 
 // respondJSON is a helper function to respond with JSON
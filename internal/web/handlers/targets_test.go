@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/blockedby/positions-os/internal/repository"
+	"github.com/blockedby/positions-os/internal/telegram"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -48,6 +50,27 @@ func (m *MockTargetsRepository) GetByID(ctx context.Context, id uuid.UUID) (*rep
 	return args.Get(0).(*repository.ScrapingTarget), args.Error(1)
 }
 
+func (m *MockTargetsRepository) GetByURL(ctx context.Context, url string) (*repository.ScrapingTarget, error) {
+	args := m.Called(ctx, url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ScrapingTarget), args.Error(1)
+}
+
+func (m *MockTargetsRepository) GetByChannelID(ctx context.Context, channelID int64) (*repository.ScrapingTarget, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ScrapingTarget), args.Error(1)
+}
+
+func (m *MockTargetsRepository) ListUnhealthy(ctx context.Context) ([]repository.ScrapingTarget, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.ScrapingTarget), args.Error(1)
+}
+
 func setupTargetsHandler(t *testing.T, repo TargetsRepository) *TargetsHandler {
 	return NewTargetsHandler(repo)
 }
@@ -77,10 +100,40 @@ func TestTargetsHandler_List(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestTargetsHandler_Health(t *testing.T) {
+	mockRepo := new(MockTargetsRepository)
+	handler := setupTargetsHandler(t, mockRepo)
+
+	errText := "resolve channel: USERNAME_NOT_OCCUPIED"
+	targets := []repository.ScrapingTarget{{
+		ID:           uuid.New(),
+		Name:         "Renamed Channel",
+		Type:         "TG_CHANNEL",
+		TargetHealth: repository.TargetHealth{ConsecutiveFailures: 5, LastError: &errText},
+	}}
+	mockRepo.On("ListUnhealthy", mock.Anything).Return(targets, nil)
+
+	req := httptest.NewRequest("GET", "/targets/health", nil)
+	rec := httptest.NewRecorder()
+
+	handler.Health(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var result []map[string]interface{}
+	err := json.NewDecoder(rec.Body).Decode(&result)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 5.0, result[0]["consecutive_failures"])
+	assert.Equal(t, errText, result[0]["last_error"])
+	mockRepo.AssertExpectations(t)
+}
+
 func TestTargetsHandler_Create(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
 	handler := setupTargetsHandler(t, mockRepo)
 
+	mockRepo.On("GetByURL", mock.Anything, "@golang_jobs").Return(nil, nil)
 	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
 		return t.Name == "Go Jobs" && t.Type == "TG_CHANNEL"
 	})).Return(nil)
@@ -136,6 +189,109 @@ func TestTargetsHandler_Create_Validation(t *testing.T) {
 	}
 }
 
+// mockResolver resolves telegram targets like collector.Service, or fails with err
+type mockResolver struct {
+	err error
+}
+
+func (m *mockResolver) ResolveTarget(ctx context.Context, t *repository.ScrapingTarget) error {
+	if m.err != nil {
+		return m.err
+	}
+	id, hash := int64(1234567890), int64(42)
+	t.Type, t.URL = "TG_FORUM", "@it_jobs_forum"
+	t.TgChannelID, t.TgAccessHash = &id, &hash
+	if t.Name == "" {
+		t.Name = "IT Jobs"
+	}
+	return nil
+}
+
+func TestTargetsHandler_Create_Resolve(t *testing.T) {
+	post := func(handler *TargetsHandler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/targets", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.Create(rec, req)
+		return rec
+	}
+
+	t.Run("detects type and name of a telegram target", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{})
+		mockRepo.On("GetByChannelID", mock.Anything, int64(1234567890)).Return(nil, nil)
+		mockRepo.On("GetByURL", mock.Anything, "@it_jobs_forum").Return(nil, nil)
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
+			return t.Name == "IT Jobs" && t.Type == "TG_FORUM" && t.URL == "@it_jobs_forum" && *t.TgChannelID == 1234567890
+		})).Return(nil)
+
+		rec := post(handler, `{"url": "https://t.me/IT_Jobs_Forum"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown channels", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{err: fmt.Errorf("%w: no_such_channel", telegram.ErrChannelNotFound)})
+
+		rec := post(handler, `{"name": "Test", "type": "TG_CHANNEL", "url": "@no_such_channel"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "channel not found: no_such_channel")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects private channels", func(t *testing.T) {
+		handler := setupTargetsHandler(t, new(MockTargetsRepository))
+		handler.SetResolver(&mockResolver{err: fmt.Errorf("%w: closed_club", telegram.ErrChannelPrivate)})
+
+		rec := post(handler, `{"url": "@closed_club"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "private")
+	})
+
+	t.Run("needs telegram to check the channel", func(t *testing.T) {
+		handler := setupTargetsHandler(t, new(MockTargetsRepository))
+		handler.SetResolver(&mockResolver{err: telegram.ErrNotReady})
+
+		rec := post(handler, `{"url": "@golang_jobs"}`)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("rejects channels that are already a target", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{})
+		existing := &repository.ScrapingTarget{ID: uuid.New(), Name: "IT Jobs", Type: "TG_FORUM", URL: "https://t.me/c/1234567890"}
+		mockRepo.On("GetByChannelID", mock.Anything, int64(1234567890)).Return(existing, nil)
+
+		rec := post(handler, `{"url": "https://t.me/IT_Jobs_Forum"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), existing.ID.String())
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("other target types are not resolved", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{err: telegram.ErrNotReady})
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
+			return t.Type == "FEED" && t.URL == "https://example.com/jobs.rss"
+		})).Return(nil)
+
+		rec := post(handler, `{"name": "Jobs feed", "type": "FEED", "url": "https://example.com/jobs.rss"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestTargetsHandler_Delete(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
 	handler := setupTargetsHandler(t, mockRepo)
@@ -167,6 +323,7 @@ func TestTargetsHandler_Update(t *testing.T) {
 	}
 
 	mockRepo.On("GetByID", mock.Anything, id).Return(target, nil)
+	mockRepo.On("GetByURL", mock.Anything, "@new").Return(nil, nil)
 	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
 		return t.ID == id && t.Name == "New Name" && !t.IsActive
 	})).Return(nil)
@@ -197,8 +354,9 @@ func TestTargetsHandler_Update(t *testing.T) {
 
 func TestTargetsHandler_Create_JSON(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
-	handler, _ := setupTargetsHandler(t, mockRepo)
+	handler := setupTargetsHandler(t, mockRepo)
 
+	mockRepo.On("GetByURL", mock.Anything, "@golang_jobs").Return(nil, nil)
 	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
 		return t.Name == "Go Jobs" && t.Type == "TG_CHANNEL" && t.URL == "@golang_jobs" && t.IsActive == true
 	})).Return(nil)
@@ -219,7 +377,7 @@ func TestTargetsHandler_Create_JSON(t *testing.T) {
 
 func TestTargetsHandler_Create_JSON_Validation(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
-	handler, _ := setupTargetsHandler(t, mockRepo)
+	handler := setupTargetsHandler(t, mockRepo)
 
 	tests := []struct {
 		name    string
@@ -231,6 +389,8 @@ func TestTargetsHandler_Create_JSON_Validation(t *testing.T) {
 		{"invalid type", `{"name":"Test","type":"INVALID","url":"@test"}`, "invalid type"},
 		{"missing url", `{"name":"Test","type":"TG_CHANNEL"}`, "url is required"},
 		{"invalid json", `{invalid}`, "invalid json"},
+		{"invalid interval", `{"name":"Test","type":"TG_CHANNEL","url":"@test","metadata":{"scrape_interval":"soon"}}`, "invalid metadata"},
+		{"invalid regex", `{"name":"Test","type":"TG_CHANNEL","url":"@test","metadata":{"include_regex":["golang("]}}`, "invalid metadata"},
 	}
 
 	for _, tt := range tests {
@@ -249,7 +409,7 @@ func TestTargetsHandler_Create_JSON_Validation(t *testing.T) {
 
 func TestTargetsHandler_Update_JSON(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
-	handler, _ := setupTargetsHandler(t, mockRepo)
+	handler := setupTargetsHandler(t, mockRepo)
 
 	id := uuid.New()
 	target := &repository.ScrapingTarget{
@@ -280,9 +440,106 @@ func TestTargetsHandler_Update_JSON(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestTargetsHandler_Update_URL(t *testing.T) {
+	put := func(handler *TargetsHandler, id uuid.UUID, body string) *httptest.ResponseRecorder {
+		r := chi.NewRouter()
+		r.Put("/targets/{id}", handler.Update)
+		req := httptest.NewRequest("PUT", "/targets/"+id.String(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	stored := func() *repository.ScrapingTarget {
+		channelID, hash := int64(111), int64(7)
+		return &repository.ScrapingTarget{
+			ID: uuid.New(), Name: "Go Jobs", Type: "TG_CHANNEL", URL: "@golang_jobs",
+			TgChannelID: &channelID, TgAccessHash: &hash, IsActive: true,
+		}
+	}
+
+	t.Run("resolves the new channel", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{})
+		target := stored()
+		mockRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		mockRepo.On("GetByChannelID", mock.Anything, int64(1234567890)).Return(nil, nil)
+		mockRepo.On("GetByURL", mock.Anything, "@it_jobs_forum").Return(nil, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
+			return t.Type == "TG_FORUM" && t.URL == "@it_jobs_forum" && *t.TgChannelID == 1234567890 && *t.TgAccessHash == 42
+		})).Return(nil)
+
+		rec := put(handler, target.ID, `{"url": "https://t.me/IT_Jobs_Forum"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("clears the stored channel without a resolver", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		target := stored()
+		mockRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		mockRepo.On("GetByURL", mock.Anything, "@rust_jobs").Return(nil, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
+			return t.URL == "@rust_jobs" && t.TgChannelID == nil && t.TgAccessHash == nil
+		})).Return(nil)
+
+		rec := put(handler, target.ID, `{"url": "@rust_jobs"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown channels", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{err: fmt.Errorf("%w: no_such_channel", telegram.ErrChannelNotFound)})
+		target := stored()
+		mockRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+
+		rec := put(handler, target.ID, `{"url": "@no_such_channel"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects channels that are already another target", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{})
+		target := stored()
+		existing := &repository.ScrapingTarget{ID: uuid.New(), Name: "IT Jobs", Type: "TG_FORUM", URL: "@it_jobs_forum"}
+		mockRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		mockRepo.On("GetByChannelID", mock.Anything, int64(1234567890)).Return(existing, nil)
+
+		rec := put(handler, target.ID, `{"url": "https://t.me/IT_Jobs_Forum"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("keeps the channel when the url is unchanged", func(t *testing.T) {
+		mockRepo := new(MockTargetsRepository)
+		handler := setupTargetsHandler(t, mockRepo)
+		handler.SetResolver(&mockResolver{err: telegram.ErrNotReady})
+		target := stored()
+		mockRepo.On("GetByID", mock.Anything, target.ID).Return(target, nil)
+		mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *repository.ScrapingTarget) bool {
+			return t.Name == "Golang Jobs" && *t.TgChannelID == 111
+		})).Return(nil)
+
+		rec := put(handler, target.ID, `{"name": "Golang Jobs", "url": "@golang_jobs"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestTargetsHandler_GetByID(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
-	handler, _ := setupTargetsHandler(t, mockRepo)
+	handler := setupTargetsHandler(t, mockRepo)
 
 	id := uuid.New()
 	target := &repository.ScrapingTarget{
@@ -311,7 +568,7 @@ func TestTargetsHandler_GetByID(t *testing.T) {
 
 func TestTargetsHandler_GetByID_NotFound(t *testing.T) {
 	mockRepo := new(MockTargetsRepository)
-	handler, _ := setupTargetsHandler(t, mockRepo)
+	handler := setupTargetsHandler(t, mockRepo)
 
 	id := uuid.New()
 	mockRepo.On("GetByID", mock.Anything, id).Return(nil, nil)
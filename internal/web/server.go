@@ -116,11 +116,21 @@ func (s *Server) RegisterJobsHandler(handler interface{}) {
 		GetByID(w http.ResponseWriter, r *http.Request)
 		UpdateStatus(w http.ResponseWriter, r *http.Request)
 	}
+	type jobGroupsHandler interface {
+		Duplicates(w http.ResponseWriter, r *http.Request)
+		Cluster(w http.ResponseWriter, r *http.Request)
+		Revisions(w http.ResponseWriter, r *http.Request)
+	}
 
 	if h, ok := handler.(jobsHandler); ok {
 		s.router.Route("/api/v1/jobs", func(r chi.Router) {
 			r.Get("/", h.List)
 			r.Get("/{id}", h.GetByID)
+			if d, ok := handler.(jobGroupsHandler); ok {
+				r.Get("/{id}/duplicates", d.Duplicates)
+				r.Get("/{id}/cluster", d.Cluster)
+				r.Get("/{id}/revisions", d.Revisions)
+			}
 			r.Patch("/{id}/status", h.UpdateStatus)
 		})
 	}
@@ -134,10 +144,16 @@ func (s *Server) RegisterTargetsHandler(handler interface{}) {
 		Delete(w http.ResponseWriter, r *http.Request)
 		Update(w http.ResponseWriter, r *http.Request)
 	}
+	type targetHealthHandler interface {
+		Health(w http.ResponseWriter, r *http.Request)
+	}
 
 	if h, ok := handler.(targetsHandler); ok {
 		s.router.Route("/api/v1/targets", func(r chi.Router) {
 			r.Get("/", h.List)
+			if hh, ok := handler.(targetHealthHandler); ok {
+				r.Get("/health", hh.Health)
+			}
 			r.Post("/", h.Create)
 			r.Delete("/{id}", h.Delete)
 			r.Put("/{id}", h.Update)
@@ -162,6 +178,14 @@ func (s *Server) RegisterCollectorHandler(handler interface{}) {
 		StartScrape(w http.ResponseWriter, r *http.Request)
 		StopScrape(w http.ResponseWriter, r *http.Request)
 		Status(w http.ResponseWriter, r *http.Request)
+		Schedule(w http.ResponseWriter, r *http.Request)
+		ListJobs(w http.ResponseWriter, r *http.Request)
+		GetJob(w http.ResponseWriter, r *http.Request)
+		CancelJob(w http.ResponseWriter, r *http.Request)
+		MoveJob(w http.ResponseWriter, r *http.Request)
+		ListRuns(w http.ResponseWriter, r *http.Request)
+		GetRun(w http.ResponseWriter, r *http.Request)
+		ResumeRun(w http.ResponseWriter, r *http.Request)
 	}
 
 	if h, ok := handler.(collectorHandler); ok {
@@ -169,6 +193,14 @@ func (s *Server) RegisterCollectorHandler(handler interface{}) {
 			r.Post("/telegram", h.StartScrape)
 			r.Delete("/current", h.StopScrape)
 			r.Get("/status", h.Status)
+			r.Get("/schedule", h.Schedule)
+			r.Get("/jobs", h.ListJobs)
+			r.Get("/jobs/{id}", h.GetJob)
+			r.Delete("/jobs/{id}", h.CancelJob)
+			r.Post("/jobs/{id}/move", h.MoveJob)
+			r.Get("/runs", h.ListRuns)
+			r.Get("/runs/{id}", h.GetRun)
+			r.Post("/runs/{id}/resume", h.ResumeRun)
 		})
 	}
 }
@@ -0,0 +1,6 @@
+-- rollback: per-topic parsed ranges
+DELETE FROM parsed_ranges WHERE topic_id <> 0;
+
+ALTER TABLE parsed_ranges DROP CONSTRAINT uq_parsed_ranges_target_topic;
+ALTER TABLE parsed_ranges ADD CONSTRAINT uq_parsed_ranges_target UNIQUE (target_id);
+ALTER TABLE parsed_ranges DROP COLUMN topic_id;
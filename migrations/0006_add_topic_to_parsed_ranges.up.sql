@@ -0,0 +1,8 @@
+-- track parsed ranges per forum topic
+-- topic_id = 0 is the whole channel history (non-forum targets)
+ALTER TABLE parsed_ranges ADD COLUMN topic_id BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE parsed_ranges DROP CONSTRAINT uq_parsed_ranges_target;
+ALTER TABLE parsed_ranges ADD CONSTRAINT uq_parsed_ranges_target_topic UNIQUE (target_id, topic_id);
+
+COMMENT ON COLUMN parsed_ranges.topic_id IS 'forum topic id (0 = whole channel)';
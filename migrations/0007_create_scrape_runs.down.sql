@@ -0,0 +1,2 @@
+-- drop scrape_runs table
+DROP TABLE IF EXISTS scrape_runs;
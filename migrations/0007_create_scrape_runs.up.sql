@@ -0,0 +1,27 @@
+-- create scrape_runs table to keep history of scrape runs and their statistics
+CREATE TABLE scrape_runs (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),  -- same as the scrape job id
+    target_id       UUID REFERENCES scraping_targets(id) ON DELETE SET NULL,
+    channel         VARCHAR(255),
+    options         JSONB NOT NULL DEFAULT '{}',
+
+    -- outcome
+    status          VARCHAR(20) NOT NULL DEFAULT 'running',       -- running, completed, failed, cancelled
+    error           TEXT,
+
+    -- statistics (ScrapeResult)
+    total_fetched   INT NOT NULL DEFAULT 0,
+    new_jobs        INT NOT NULL DEFAULT 0,
+    skipped_old     INT NOT NULL DEFAULT 0,
+    skipped_empty   INT NOT NULL DEFAULT 0,
+    errors          INT NOT NULL DEFAULT 0,
+
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at     TIMESTAMPTZ
+);
+
+CREATE INDEX idx_scrape_runs_started ON scrape_runs (started_at DESC);
+CREATE INDEX idx_scrape_runs_target ON scrape_runs (target_id, started_at DESC);
+
+COMMENT ON TABLE scrape_runs IS 'history of scrape runs with their statistics';
+COMMENT ON COLUMN scrape_runs.options IS 'scrape options the run was started with';
@@ -0,0 +1,19 @@
+-- collapse range sets back into a single range per target and topic.
+-- gaps between ranges are lost (treated as parsed).
+CREATE TEMP TABLE parsed_ranges_merged AS
+SELECT target_id, topic_id, MIN(min_msg_id) AS min_msg_id, MAX(max_msg_id) AS max_msg_id
+FROM parsed_ranges
+GROUP BY target_id, topic_id;
+
+DELETE FROM parsed_ranges;
+
+INSERT INTO parsed_ranges (target_id, topic_id, min_msg_id, max_msg_id)
+SELECT target_id, topic_id, min_msg_id, max_msg_id FROM parsed_ranges_merged;
+
+DROP TABLE parsed_ranges_merged;
+
+DROP INDEX IF EXISTS idx_parsed_ranges_target_topic;
+
+ALTER TABLE parsed_ranges ADD CONSTRAINT uq_parsed_ranges_target_topic UNIQUE (target_id, topic_id);
+
+COMMENT ON TABLE parsed_ranges IS 'tracks ranges of scraped telegram message ids for incremental parsing';
@@ -0,0 +1,7 @@
+-- allow several disjoint parsed ranges per target and topic.
+-- gaps left by limited or cancelled scrapes stay visible and can be backfilled.
+ALTER TABLE parsed_ranges DROP CONSTRAINT uq_parsed_ranges_target_topic;
+
+CREATE INDEX idx_parsed_ranges_target_topic ON parsed_ranges (target_id, topic_id, min_msg_id);
+
+COMMENT ON TABLE parsed_ranges IS 'disjoint ranges of scraped telegram message ids per target and topic';
@@ -0,0 +1,4 @@
+-- messages dropped by the per-target prefilter (keywords, regexes, hashtags)
+ALTER TABLE scrape_runs ADD COLUMN skipped_filtered INT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN scrape_runs.skipped_filtered IS 'messages skipped by the target prefilter';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_duplicate_of;
+DROP INDEX IF EXISTS idx_jobs_content_hash;
+ALTER TABLE jobs DROP COLUMN duplicate_of;
+
+COMMENT ON COLUMN jobs.content_hash IS 'sha256 of raw_content for duplicate detection';
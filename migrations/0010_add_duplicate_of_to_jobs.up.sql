@@ -0,0 +1,12 @@
+-- cross-channel duplicates: a job with the same normalized content_hash
+-- as an earlier job points to it (the canonical job of the group)
+ALTER TABLE jobs ADD COLUMN duplicate_of UUID REFERENCES jobs(id) ON DELETE SET NULL;
+
+-- lookup of the canonical job on insert
+CREATE INDEX idx_jobs_content_hash ON jobs (content_hash) WHERE duplicate_of IS NULL;
+
+-- listing the duplicates of a canonical job
+CREATE INDEX idx_jobs_duplicate_of ON jobs (duplicate_of) WHERE duplicate_of IS NOT NULL;
+
+COMMENT ON COLUMN jobs.duplicate_of IS 'canonical job with the same normalized content, NULL for canonical jobs';
+COMMENT ON COLUMN jobs.content_hash IS 'sha256 of normalized raw_content (lowercase, no emoji, collapsed whitespace)';
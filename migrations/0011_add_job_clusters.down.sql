@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_simhash_recent;
+DROP INDEX IF EXISTS idx_jobs_cluster;
+ALTER TABLE jobs DROP COLUMN cluster_id;
+ALTER TABLE jobs DROP COLUMN simhash;
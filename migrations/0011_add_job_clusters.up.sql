@@ -0,0 +1,15 @@
+-- near-duplicate clustering: 64-bit simhash of normalized raw_content,
+-- jobs within a few bits of each other share a cluster
+ALTER TABLE jobs ADD COLUMN simhash BIGINT;
+ALTER TABLE jobs ADD COLUMN cluster_id UUID;
+
+-- existing jobs start as clusters of one
+UPDATE jobs SET cluster_id = id;
+
+CREATE INDEX idx_jobs_cluster ON jobs (cluster_id);
+
+-- candidates for clustering are recent jobs with a fingerprint
+CREATE INDEX idx_jobs_simhash_recent ON jobs (created_at) WHERE simhash IS NOT NULL;
+
+COMMENT ON COLUMN jobs.simhash IS 'simhash of normalized raw_content for near-duplicate detection';
+COMMENT ON COLUMN jobs.cluster_id IS 'id of the first job of the near-duplicate cluster';
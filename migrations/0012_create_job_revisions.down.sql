@@ -0,0 +1,3 @@
+ALTER TABLE scrape_runs DROP COLUMN edited_jobs;
+DROP TABLE IF EXISTS job_revisions;
+ALTER TABLE jobs DROP COLUMN edited_at;
@@ -0,0 +1,24 @@
+-- edits of source messages: the job keeps the latest text,
+-- earlier versions are kept in job_revisions
+ALTER TABLE jobs ADD COLUMN edited_at TIMESTAMPTZ;
+
+CREATE TABLE job_revisions (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_id          UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+
+    -- content before the edit
+    raw_content     TEXT NOT NULL,
+    content_hash    VARCHAR(64),
+    edited_at       TIMESTAMPTZ,                          -- edit date of this version, NULL for the original post
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()    -- when it was replaced
+);
+
+CREATE INDEX idx_job_revisions_job ON job_revisions (job_id, created_at DESC);
+
+-- edited jobs found by a scrape run
+ALTER TABLE scrape_runs ADD COLUMN edited_jobs INT NOT NULL DEFAULT 0;
+
+COMMENT ON TABLE job_revisions IS 'earlier versions of edited job posts';
+COMMENT ON COLUMN jobs.edited_at IS 'edit date of the source message the current raw_content comes from';
+COMMENT ON COLUMN scrape_runs.edited_jobs IS 'jobs whose source message was edited';
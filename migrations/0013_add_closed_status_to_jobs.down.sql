@@ -0,0 +1,26 @@
+-- enum values can not be dropped: closed jobs are reopened and the type is recreated
+UPDATE jobs SET status = CASE WHEN analyzed_at IS NULL THEN 'RAW' ELSE 'ANALYZED' END::job_status
+WHERE status = 'CLOSED';
+
+ALTER TABLE jobs DROP COLUMN closed_at;
+
+DROP INDEX IF EXISTS idx_jobs_raw;
+DROP INDEX IF EXISTS idx_jobs_status;
+ALTER TABLE jobs ALTER COLUMN status DROP DEFAULT;
+
+ALTER TYPE job_status RENAME TO job_status_old;
+CREATE TYPE job_status AS ENUM (
+    'RAW',
+    'ANALYZED',
+    'REJECTED',
+    'INTERESTED',
+    'TAILORED',
+    'SENT',
+    'RESPONDED'
+);
+ALTER TABLE jobs ALTER COLUMN status TYPE job_status USING status::text::job_status;
+ALTER TABLE jobs ALTER COLUMN status SET DEFAULT 'RAW';
+DROP TYPE job_status_old;
+
+CREATE INDEX idx_jobs_status ON jobs (status);
+CREATE INDEX idx_jobs_raw ON jobs (created_at) WHERE status = 'RAW';
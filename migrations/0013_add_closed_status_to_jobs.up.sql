@@ -0,0 +1,7 @@
+-- jobs whose source post was deleted (the vacancy is filled) are closed.
+-- the new value can not be used in the same transaction, so no partial index on it here.
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'CLOSED';
+
+ALTER TABLE jobs ADD COLUMN closed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN jobs.closed_at IS 'when the job was closed (source message deleted), NULL for open jobs';
@@ -0,0 +1,20 @@
+-- enum values can not be dropped: feed targets (and their jobs) are deleted and the type is recreated
+DELETE FROM scraping_targets WHERE type = 'FEED';
+
+ALTER TABLE scraping_targets DROP COLUMN http_last_modified;
+ALTER TABLE scraping_targets DROP COLUMN http_etag;
+
+DROP INDEX IF EXISTS idx_scraping_targets_type;
+
+ALTER TYPE scraping_target_type RENAME TO scraping_target_type_old;
+CREATE TYPE scraping_target_type AS ENUM (
+    'TG_CHANNEL',
+    'TG_GROUP',
+    'TG_FORUM',
+    'HH_SEARCH',
+    'LINKEDIN_SEARCH'
+);
+ALTER TABLE scraping_targets ALTER COLUMN type TYPE scraping_target_type USING type::text::scraping_target_type;
+DROP TYPE scraping_target_type_old;
+
+CREATE INDEX idx_scraping_targets_type ON scraping_targets (type);
@@ -0,0 +1,9 @@
+-- rss/atom and json feed targets.
+-- validators of the last fetch make the next fetch a conditional GET.
+ALTER TYPE scraping_target_type ADD VALUE IF NOT EXISTS 'FEED';
+
+ALTER TABLE scraping_targets ADD COLUMN http_etag TEXT;
+ALTER TABLE scraping_targets ADD COLUMN http_last_modified TEXT;
+
+COMMENT ON COLUMN scraping_targets.http_etag IS 'ETag of the last fetched feed, sent as If-None-Match';
+COMMENT ON COLUMN scraping_targets.http_last_modified IS 'Last-Modified of the last fetched feed, sent as If-Modified-Since';
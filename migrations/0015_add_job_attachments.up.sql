@@ -0,0 +1,5 @@
+-- attachment of the source post: document or photo metadata, telegram file
+-- reference, storage path of a downloaded text document and its extracted text
+ALTER TABLE jobs ADD COLUMN attachment JSONB;
+
+COMMENT ON COLUMN jobs.attachment IS 'Attachment of the source post (file name, mime, size, file reference, storage path, extracted text)';
@@ -0,0 +1,5 @@
+-- links and contacts of the source post taken from telegram message entities:
+-- urls, hidden text links, @mentions, emails and phone numbers
+ALTER TABLE jobs ADD COLUMN links JSONB;
+
+COMMENT ON COLUMN jobs.links IS 'Links and contacts from message entities: [{type, text, url}]';
@@ -0,0 +1,5 @@
+-- comments collected from the discussion group linked to a channel keep the
+-- channel post they reply to as context
+ALTER TABLE jobs ADD COLUMN parent JSONB;
+
+COMMENT ON COLUMN jobs.parent IS 'Channel post a comment replies to: {message_id, text, url}';
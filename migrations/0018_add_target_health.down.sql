@@ -0,0 +1,7 @@
+ALTER TABLE scraping_targets DROP COLUMN disabled_at;
+ALTER TABLE scraping_targets DROP COLUMN avg_new_jobs;
+ALTER TABLE scraping_targets DROP COLUMN successful_runs;
+ALTER TABLE scraping_targets DROP COLUMN last_success_at;
+ALTER TABLE scraping_targets DROP COLUMN last_error_at;
+ALTER TABLE scraping_targets DROP COLUMN last_error;
+ALTER TABLE scraping_targets DROP COLUMN consecutive_failures;
@@ -0,0 +1,16 @@
+-- health of a target over its scrape runs.
+-- targets failing on every run (channel renamed, made private or banned)
+-- are deactivated after a number of consecutive failures, disabled_at tells
+-- them apart from targets turned off by hand.
+ALTER TABLE scraping_targets ADD COLUMN consecutive_failures INT NOT NULL DEFAULT 0;
+ALTER TABLE scraping_targets ADD COLUMN last_error TEXT;
+ALTER TABLE scraping_targets ADD COLUMN last_error_at TIMESTAMPTZ;
+ALTER TABLE scraping_targets ADD COLUMN last_success_at TIMESTAMPTZ;
+ALTER TABLE scraping_targets ADD COLUMN successful_runs INT NOT NULL DEFAULT 0;
+ALTER TABLE scraping_targets ADD COLUMN avg_new_jobs DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE scraping_targets ADD COLUMN disabled_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN scraping_targets.consecutive_failures IS 'failed scrape runs since the last successful one';
+COMMENT ON COLUMN scraping_targets.last_error IS 'error of the last failed scrape run';
+COMMENT ON COLUMN scraping_targets.avg_new_jobs IS 'average new jobs per successful scrape run';
+COMMENT ON COLUMN scraping_targets.disabled_at IS 'when the target was deactivated after consecutive failures, NULL otherwise';
@@ -0,0 +1,20 @@
+-- progress of a scrape run per stream, saved after every batch so an
+-- interrupted run can be resumed
+CREATE TABLE scrape_checkpoints (
+    run_id          UUID NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+    target_id       UUID NOT NULL REFERENCES scraping_targets(id) ON DELETE CASCADE,
+    topic_id        BIGINT NOT NULL DEFAULT 0,             -- stream: 0 = whole channel, forum topic, -post id for comments
+
+    next_cursor     BIGINT NOT NULL DEFAULT 0,             -- offset of the next batch (0 = newest)
+    min_msg_id      BIGINT NOT NULL DEFAULT 0,             -- walked span so far
+    max_msg_id      BIGINT NOT NULL DEFAULT 0,
+    floor_msg_id    BIGINT NOT NULL DEFAULT 0,             -- oldest parsed message when the walk started, 0 = backfill
+    batches         INT NOT NULL DEFAULT 0,
+    done            BOOLEAN NOT NULL DEFAULT false,        -- the walk reached its end
+
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (run_id, topic_id)
+);
+
+COMMENT ON TABLE scrape_checkpoints IS 'per-stream progress of scrape runs, resumed by later runs';
@@ -3,17 +3,33 @@ package integration
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/blockedby/positions-os/internal/collector"
 	"github.com/blockedby/positions-os/internal/database"
+	"github.com/blockedby/positions-os/internal/feed"
+	"github.com/blockedby/positions-os/internal/feed/feedtest"
+	"github.com/blockedby/positions-os/internal/hh"
+	"github.com/blockedby/positions-os/internal/hh/hhtest"
 	"github.com/blockedby/positions-os/internal/logger"
 	"github.com/blockedby/positions-os/internal/repository"
 	"github.com/blockedby/positions-os/internal/telegram"
 )
 
+// MockDownloader serves a fixed document
+type MockDownloader struct {
+	Data string
+}
+
+func (m *MockDownloader) DownloadDocument(ctx context.Context, att *telegram.Attachment, w io.Writer) error {
+	_, err := io.WriteString(w, m.Data)
+	return err
+}
+
 // MockTGClient mocks telegram client
 type MockTGClient struct {
 	Channel  *telegram.Channel
@@ -27,6 +43,18 @@ func (m *MockTGClient) ResolveChannel(ctx context.Context, username string) (*te
 	return m.Channel, nil
 }
 
+func (m *MockTGClient) ResolveChannelByID(ctx context.Context, id, accessHash int64) (*telegram.Channel, error) {
+	return m.ResolveChannel(ctx, fmt.Sprint(id))
+}
+
+func (m *MockTGClient) JoinInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return m.ResolveChannel(ctx, hash)
+}
+
+func (m *MockTGClient) CheckInvite(ctx context.Context, hash string) (*telegram.Channel, error) {
+	return m.ResolveChannel(ctx, hash)
+}
+
 func (m *MockTGClient) GetMessages(ctx context.Context, channel *telegram.Channel, offsetID int, limit int) ([]telegram.Message, error) {
 	if offsetID > 0 {
 		// simulate end of history for test simplicity
@@ -39,13 +67,22 @@ func (m *MockTGClient) GetTopics(ctx context.Context, channel *telegram.Channel)
 	return []telegram.Topic{}, nil
 }
 
+func (m *MockTGClient) GetTopicMessages(ctx context.Context, channel *telegram.Channel, topicID int, offsetID int, limit int) ([]telegram.Message, error) {
+	return []telegram.Message{}, nil
+}
+
+func (m *MockTGClient) GetReplies(ctx context.Context, channel *telegram.Channel, postID int, offsetID int, limit int) ([]telegram.Message, error) {
+	return []telegram.Message{}, nil
+}
+
 func (m *MockTGClient) GetStatus() telegram.Status {
 	return telegram.StatusReady
 }
 
 // MockPublisher mocks event publisher
 type MockPublisher struct {
-	Events []collector.JobNewEvent
+	Events  []collector.JobNewEvent
+	Updates []collector.JobUpdatedEvent
 }
 
 func (m *MockPublisher) PublishJobNew(ctx context.Context, event collector.JobNewEvent) error {
@@ -53,6 +90,11 @@ func (m *MockPublisher) PublishJobNew(ctx context.Context, event collector.JobNe
 	return nil
 }
 
+func (m *MockPublisher) PublishJobUpdated(ctx context.Context, event collector.JobUpdatedEvent) error {
+	m.Updates = append(m.Updates, event)
+	return nil
+}
+
 func TestEndToEnd_Scraping(t *testing.T) {
 	// this test requires database
 	if os.Getenv("INTEGRATION_TEST") == "" {
@@ -90,7 +132,7 @@ func TestEndToEnd_Scraping(t *testing.T) {
 	channel := &telegram.Channel{
 		ID:         channelID,
 		AccessHash: accessHash,
-		Username:   "test_channel",
+		Username:   "easy_python_job",
 		Title:      "Test Channel",
 	}
 
@@ -209,12 +251,422 @@ func TestEndToEnd_Scraping(t *testing.T) {
 	}
 }
 
+func TestEndToEnd_HHSearch(t *testing.T) {
+	// this test requires database
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run (WARNING: wipes database)")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set, skipping integration test")
+	}
+
+	logger.Init("debug", "")
+	log := logger.Get()
+
+	db, err := database.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	dropTables(t, db)
+	runMigrations(t, db)
+
+	targetsRepo := repository.NewTargetsRepository(db.Pool)
+	jobsRepo := repository.NewJobsRepository(db.Pool)
+	rangesRepo := repository.NewRangesRepository(db.Pool)
+
+	// hh.ru api stub serving recorded responses
+	srv := hhtest.NewServer("../../internal/hh/testdata")
+	defer srv.Close()
+	hhClient := hh.NewClient(srv.URL, "positions-os-test")
+	hhClient.SetRequestInterval(0)
+
+	publisher := &MockPublisher{}
+	svc := collector.NewService(&MockTGClient{}, targetsRepo, jobsRepo, rangesRepo, publisher, log)
+	svc.SetHHClient(hhClient)
+
+	ctx := context.Background()
+	target := &repository.ScrapingTarget{
+		Name:     "Go HH",
+		Type:     "HH_SEARCH",
+		URL:      "https://hh.ru/search/vacancy?text=golang&area=1",
+		IsActive: true,
+	}
+	if err := targetsRepo.Create(ctx, target); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result, err := svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID})
+	if err != nil {
+		t.Fatalf("Scrape() error: %v", err)
+	}
+	if result.NewJobs != 3 {
+		t.Errorf("NewJobs = %d, want 3", result.NewJobs)
+	}
+
+	job, err := jobsRepo.GetByExternalID(ctx, target.ID, "93353083")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error: %v", err)
+	}
+	if job == nil {
+		t.Fatal("job of vacancy 93353083 should exist")
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://hh.ru/vacancy/93353083" {
+		t.Errorf("SourceURL = %v", job.SourceURL)
+	}
+	if job.StructuredData["company"] != "Яндекс" || job.StructuredData["salary_min"] != float64(250000) {
+		t.Errorf("StructuredData = %v, want api fields", job.StructuredData)
+	}
+
+	// second run finds the stored vacancies on the first page and stops
+	result2, err := svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID})
+	if err != nil {
+		t.Fatalf("Scrape() 2nd run error: %v", err)
+	}
+	if result2.NewJobs != 0 || result2.SkippedOld != 2 {
+		t.Errorf("2nd run NewJobs = %d, SkippedOld = %d, want 0 and 2", result2.NewJobs, result2.SkippedOld)
+	}
+	if len(publisher.Events) != 3 {
+		t.Errorf("Publisher events = %d, want 3", len(publisher.Events))
+	}
+}
+
+func TestEndToEnd_Feed(t *testing.T) {
+	// this test requires database
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run (WARNING: wipes database)")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set, skipping integration test")
+	}
+
+	logger.Init("debug", "")
+	log := logger.Get()
+
+	db, err := database.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	dropTables(t, db)
+	runMigrations(t, db)
+
+	targetsRepo := repository.NewTargetsRepository(db.Pool)
+	jobsRepo := repository.NewJobsRepository(db.Pool)
+	rangesRepo := repository.NewRangesRepository(db.Pool)
+
+	// feed host serving the sample feeds with cache validators
+	srv := feedtest.NewServer("../../internal/feed/testdata")
+	defer srv.Close()
+
+	publisher := &MockPublisher{}
+	svc := collector.NewService(&MockTGClient{}, targetsRepo, jobsRepo, rangesRepo, publisher, log)
+	svc.SetFeedClient(feed.NewClient("positions-os-test"))
+
+	ctx := context.Background()
+	target := &repository.ScrapingTarget{
+		Name:     "Acme Careers",
+		Type:     "FEED",
+		URL:      srv.URL + "/rss.xml",
+		IsActive: true,
+	}
+	if err := targetsRepo.Create(ctx, target); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result, err := svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID})
+	if err != nil {
+		t.Fatalf("Scrape() error: %v", err)
+	}
+	if result.NewJobs != 3 {
+		t.Errorf("NewJobs = %d, want 3", result.NewJobs)
+	}
+
+	job, err := jobsRepo.GetByExternalID(ctx, target.ID, "acme-1042")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error: %v", err)
+	}
+	if job == nil {
+		t.Fatal("job of item acme-1042 should exist")
+	}
+	if job.SourceURL == nil || *job.SourceURL != "https://acme.example/careers/senior-go" {
+		t.Errorf("SourceURL = %v", job.SourceURL)
+	}
+
+	stored, err := targetsRepo.GetByID(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if stored.HTTPETag == nil || stored.HTTPLastModified == nil {
+		t.Errorf("validators = %v, %v, want both saved", stored.HTTPETag, stored.HTTPLastModified)
+	}
+
+	// second run is a conditional GET of an unchanged feed
+	result2, err := svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID})
+	if err != nil {
+		t.Fatalf("Scrape() 2nd run error: %v", err)
+	}
+	if result2.TotalFetched != 0 || result2.NewJobs != 0 {
+		t.Errorf("2nd run TotalFetched = %d, NewJobs = %d, want 0 and 0", result2.TotalFetched, result2.NewJobs)
+	}
+	if requests := srv.Requests(); len(requests) != 2 || requests[1].Status != http.StatusNotModified {
+		t.Errorf("requests = %+v, want the 2nd one not modified", requests)
+	}
+
+	// http errors fail the scrape
+	target.URL = srv.URL + "/status/503"
+	if err := targetsRepo.Update(ctx, target); err != nil {
+		t.Fatalf("update target: %v", err)
+	}
+	if _, err := svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID}); err == nil {
+		t.Error("Scrape() of a failing feed should return an error")
+	}
+}
+
+func TestEndToEnd_Import(t *testing.T) {
+	// this test requires database
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run (WARNING: wipes database)")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set, skipping integration test")
+	}
+
+	logger.Init("debug", "")
+	log := logger.Get()
+
+	db, err := database.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	dropTables(t, db)
+	runMigrations(t, db)
+
+	targetsRepo := repository.NewTargetsRepository(db.Pool)
+	jobsRepo := repository.NewJobsRepository(db.Pool)
+	rangesRepo := repository.NewRangesRepository(db.Pool)
+
+	// no telegram client: an import needs no session
+	publisher := &MockPublisher{}
+	svc := collector.NewService(nil, targetsRepo, jobsRepo, rangesRepo, publisher, log)
+
+	ctx := context.Background()
+	channelID := int64(1234567890)
+	target := &repository.ScrapingTarget{
+		Name:        "Go Jobs Archive",
+		Type:        "TG_CHANNEL",
+		URL:         "https://t.me/c/1234567890",
+		TgChannelID: &channelID,
+		IsActive:    true,
+	}
+	if err := targetsRepo.Create(ctx, target); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	date := time.Date(2023, 1, 10, 10, 0, 0, 0, time.UTC)
+	messages := []telegram.Message{
+		{ID: 2, Text: "Go developer, 300k", Date: date},
+		{ID: 3, Text: "", Date: date.Add(time.Hour)},
+		{ID: 5, Text: "Python developer, 250k", Date: date.Add(2 * time.Hour)},
+	}
+
+	result, err := svc.Import(ctx, target, messages)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result.NewJobs != 2 || result.SkippedEmpty != 1 {
+		t.Errorf("NewJobs = %d, SkippedEmpty = %d, want 2 and 1", result.NewJobs, result.SkippedEmpty)
+	}
+
+	job, err := jobsRepo.GetByExternalID(ctx, target.ID, "5")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error: %v", err)
+	}
+	if job == nil || job.SourceURL == nil || *job.SourceURL != "https://t.me/c/1234567890/5" {
+		t.Errorf("imported job = %+v, want permalink of the private channel", job)
+	}
+
+	ranges, err := rangesRepo.GetRanges(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetRanges() error: %v", err)
+	}
+	if len(ranges) != 1 || ranges[0].MinMsgID != 2 || ranges[0].MaxMsgID != 5 {
+		t.Errorf("parsed ranges = %v, want [2, 5]", ranges)
+	}
+
+	// importing the export again only applies edits
+	edited := date.Add(24 * time.Hour)
+	messages[2].Text, messages[2].EditDate = "Python developer, 280k", &edited
+	result2, err := svc.Import(ctx, target, messages)
+	if err != nil {
+		t.Fatalf("Import() 2nd run error: %v", err)
+	}
+	if result2.NewJobs != 0 || result2.EditedJobs != 1 {
+		t.Errorf("2nd run NewJobs = %d, EditedJobs = %d, want 0 and 1", result2.NewJobs, result2.EditedJobs)
+	}
+	if len(publisher.Events) != 2 || len(publisher.Updates) != 1 {
+		t.Errorf("Publisher events = %d, updates = %d, want 2 and 1", len(publisher.Events), len(publisher.Updates))
+	}
+}
+
+func TestEndToEnd_DryRun(t *testing.T) {
+	// this test requires database
+	if os.Getenv("INTEGRATION_TEST") == "" {
+		t.Skip("Skipping integration test; set INTEGRATION_TEST=1 to run (WARNING: wipes database)")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL not set, skipping integration test")
+	}
+
+	logger.Init("debug", "")
+	log := logger.Get()
+
+	db, err := database.New(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("failed to connect to db: %v", err)
+	}
+	defer db.Close()
+
+	dropTables(t, db)
+	runMigrations(t, db)
+
+	targetsRepo := repository.NewTargetsRepository(db.Pool)
+	jobsRepo := repository.NewJobsRepository(db.Pool)
+	rangesRepo := repository.NewRangesRepository(db.Pool)
+
+	channel := &telegram.Channel{ID: 123456, AccessHash: 789012, Username: "go_jobs"}
+	now := time.Now()
+	tgClient := &MockTGClient{
+		Channel: channel,
+		Messages: []telegram.Message{
+			{ID: 104, Date: now, Attachment: &telegram.Attachment{
+				Type: telegram.AttachmentDocument, FileName: "vacancy.txt", MimeType: "text/plain", Size: 6, ID: 1,
+			}},
+			{ID: 103, Text: "Go developer, remote", Date: now},
+			{ID: 102, Text: "Go intern, unpaid", Date: now.Add(-time.Minute)},
+			{ID: 101, Text: "", Date: now.Add(-2 * time.Minute)},
+			{ID: 100, Text: "Go lead", Date: now.Add(-3 * time.Minute)},
+		},
+	}
+	publisher := &MockPublisher{}
+	svc := collector.NewService(tgClient, targetsRepo, jobsRepo, rangesRepo, publisher, log)
+	attachmentsDir := t.TempDir()
+	svc.SetAttachmentStore(collector.NewAttachmentStore(attachmentsDir, 1<<20, &MockDownloader{Data: "Go SRE"}))
+	ctx := context.Background()
+
+	// an unknown channel is previewed without creating its target
+	result, err := svc.Scrape(ctx, collector.ScrapeOptions{Channel: "go_jobs", Limit: 10, DryRun: true})
+	if err != nil {
+		t.Fatalf("Scrape() dry run error: %v", err)
+	}
+	if result.NewJobs != 4 || len(result.Preview) != 5 {
+		t.Errorf("NewJobs = %d, preview = %d, want 4 and 5", result.NewJobs, len(result.Preview))
+	}
+	if result.Preview[0].Content != "Go SRE" {
+		t.Errorf("preview content = %q, want the document text", result.Preview[0].Content)
+	}
+	if target, _ := targetsRepo.GetByURL(ctx, "go_jobs"); target != nil {
+		t.Errorf("dry run created target %+v", target)
+	}
+
+	// a known target: already collected and filtered messages are listed with their reason
+	target := &repository.ScrapingTarget{
+		Name:     "Go Jobs",
+		Type:     "TG_CHANNEL",
+		URL:      "go_jobs",
+		IsActive: true,
+		Metadata: map[string]interface{}{"exclude_keywords": []string{"unpaid"}},
+	}
+	if err := targetsRepo.Create(ctx, target); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+	if err := rangesRepo.UpdateTopicRange(ctx, target.ID, 0, 100, 100); err != nil {
+		t.Fatalf("UpdateTopicRange() error: %v", err)
+	}
+
+	// the same vacancy collected from another channel
+	other := &repository.ScrapingTarget{Name: "Other Jobs", Type: "TG_CHANNEL", URL: "other_jobs", IsActive: true}
+	if err := targetsRepo.Create(ctx, other); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+	repost := &repository.Job{TargetID: other.ID, ExternalID: "7", RawContent: "Go developer, remote", Status: "RAW"}
+	if err := jobsRepo.Create(ctx, repost); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	result, err = svc.Scrape(ctx, collector.ScrapeOptions{TargetID: target.ID, Limit: 10, DryRun: true})
+	if err != nil {
+		t.Fatalf("Scrape() dry run error: %v", err)
+	}
+	skips := map[string]string{}
+	for _, p := range result.Preview {
+		skips[p.ExternalID] = p.Skip
+	}
+	want := map[string]string{
+		"104": "",
+		"103": collector.SkipDuplicate,
+		"102": "exclude_keyword",
+		"101": collector.SkipEmpty,
+		"100": collector.SkipAlreadyCollected,
+	}
+	for id, skip := range want {
+		if got, ok := skips[id]; !ok || got != skip {
+			t.Errorf("preview of %s: skip = %q (listed %v), want %q", id, got, ok, skip)
+		}
+	}
+	if result.Preview[1].URL != "https://t.me/go_jobs/103" {
+		t.Errorf("preview url = %q, want the permalink", result.Preview[1].URL)
+	}
+	if dup := result.Preview[1].DuplicateOf; dup == nil || *dup != repost.ID {
+		t.Errorf("preview duplicate_of = %v, want %s", dup, repost.ID)
+	}
+
+	// nothing was stored
+	if exists, _ := jobsRepo.Exists(ctx, target.ID, "104"); exists {
+		t.Error("dry run created a job")
+	}
+	ranges, err := rangesRepo.GetRanges(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetRanges() error: %v", err)
+	}
+	if len(ranges) != 1 || ranges[0].MaxMsgID != 100 {
+		t.Errorf("parsed ranges = %v, want only [100, 100]", ranges)
+	}
+	stored, err := targetsRepo.GetByID(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if stored.LastMessageID != nil || stored.LastScrapedAt != nil || stored.TgChannelID != nil {
+		t.Errorf("dry run updated the target: %+v", stored)
+	}
+	if len(publisher.Events) != 0 {
+		t.Errorf("Publisher events = %d, want 0", len(publisher.Events))
+	}
+	if entries, _ := os.ReadDir(attachmentsDir); len(entries) != 0 {
+		t.Errorf("dry run saved attachments: %v", entries)
+	}
+}
+
 func dropTables(t *testing.T, db *database.DB) {
 	ctx := context.Background()
 	// drops tables related to this test
 	_, err := db.Pool.Exec(ctx, `
+		DROP TABLE IF EXISTS scrape_runs CASCADE;
 		DROP TABLE IF EXISTS job_applications CASCADE;
 		DROP TABLE IF EXISTS job_listings CASCADE;
+		DROP TABLE IF EXISTS job_revisions CASCADE;
 		DROP TABLE IF EXISTS parsed_ranges CASCADE;
 		DROP TABLE IF EXISTS jobs CASCADE;
 		DROP TABLE IF EXISTS scraping_targets CASCADE;
@@ -235,6 +687,17 @@ func runMigrations(t *testing.T, db *database.DB) {
 		"../../migrations/0001_create_scraping_targets.up.sql",
 		"../../migrations/0002_create_jobs.up.sql",
 		"../../migrations/0005_create_parsed_ranges.up.sql",
+		"../../migrations/0006_add_topic_to_parsed_ranges.up.sql",
+		"../../migrations/0007_create_scrape_runs.up.sql",
+		"../../migrations/0008_parsed_range_sets.up.sql",
+		"../../migrations/0009_add_skipped_filtered_to_scrape_runs.up.sql",
+		"../../migrations/0010_add_duplicate_of_to_jobs.up.sql",
+		"../../migrations/0011_add_job_clusters.up.sql",
+		"../../migrations/0012_create_job_revisions.up.sql",
+		"../../migrations/0013_add_closed_status_to_jobs.up.sql",
+		"../../migrations/0014_add_feed_targets.up.sql",
+		"../../migrations/0015_add_job_attachments.up.sql",
+		"../../migrations/0016_add_job_links.up.sql",
 	}
 
 	ctx := context.Background()